@@ -0,0 +1,103 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains helpers for matching abbreviated long flags
+*/
+package configurature
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// expandAbbrevFlags replaces long flags in args that are unambiguous
+// prefixes of the names of flags in fs with the full flag names. E.g.
+// --sub_def for --sub_default_lock_timeout. It returns an error if a prefix
+// is ambiguous.
+func expandAbbrevFlags(fs *pflag.FlagSet, args []string) ([]string, error) {
+	out := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		out = append(out, arg)
+
+		// Everything after "--" is a positional argument
+		if arg == "--" {
+			return append(out, args[i+1:]...), nil
+		}
+		if !strings.HasPrefix(arg, "--") {
+			// The value of a shorthand flag is not expanded either
+			if len(arg) == 2 && arg[0] == '-' {
+				if fl := fs.ShorthandLookup(arg[1:]); fl != nil && fl.NoOptDefVal == "" && i+1 < len(args) {
+					i++
+					out = append(out, args[i])
+				}
+			}
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(arg[2:], "=")
+		fl := fs.Lookup(name)
+		if fl == nil {
+			var err error
+			if fl, err = abbrevFlag(fs, name); err != nil {
+				return nil, err
+			} else if fl == nil {
+				continue
+			}
+			out[len(out)-1] = "--" + fl.Name
+			if hasValue {
+				out[len(out)-1] += "=" + value
+			}
+		}
+
+		// The next arg is the flag's value and is not expanded
+		if !hasValue && fl.NoOptDefVal == "" && i+1 < len(args) {
+			i++
+			out = append(out, args[i])
+		}
+	}
+	return out, nil
+}
+
+// abbrevFlag returns the flag whose name starts with prefix or nil if there
+// is none. Hidden and internal flags must be given in full. It returns an
+// error if more than one flag name starts with prefix.
+func abbrevFlag(fs *pflag.FlagSet, prefix string) (*pflag.Flag, error) {
+	if prefix == "" {
+		return nil, nil
+	}
+	matches := []*pflag.Flag{}
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || internalFlags[f.Name] {
+			return
+		}
+		if strings.HasPrefix(f.Name, prefix) {
+			matches = append(matches, f)
+		}
+	})
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = "--" + m.Name
+	}
+	return nil, fmt.Errorf("ambiguous flag --%s could be %s", prefix, strings.Join(names, ", "))
+}
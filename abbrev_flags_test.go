@@ -0,0 +1,94 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type AbbrevConf struct {
+	Name string `short:"n"`
+	Sub  struct {
+		DefaultLockTimeout time.Duration
+		DefaultName        string
+	}
+	Verbose bool
+}
+
+func TestAbbrevFlags(t *testing.T) {
+	assert := assert.New(t)
+
+	c := co.Configure[AbbrevConf](&co.Options{
+		NoRecover:   true,
+		AbbrevFlags: true,
+		Args: []string{"--sub_default_l=3s", "--sub_default_n", "--na", "--verb",
+			"--name", "--na", "--", "--sub"},
+	})
+	assert.Equal(3*time.Second, c.Sub.DefaultLockTimeout)
+	assert.Equal("--na", c.Sub.DefaultName)
+	assert.Equal("--na", c.Name)
+	assert.True(c.Verbose)
+
+	c = co.Configure[AbbrevConf](&co.Options{
+		NoRecover:   true,
+		AbbrevFlags: true,
+		Args:        []string{"-n", "--verb"},
+	})
+	assert.Equal("--verb", c.Name)
+	assert.False(c.Verbose)
+}
+
+func TestAbbrevFlags_Ambiguous(t *testing.T) {
+	code, _, errOut := configureExit(t, func(opts *co.Options) {
+		co.Configure[AbbrevConf](opts)
+	}, co.Options{
+		AbbrevFlags: true,
+		Args:        []string{"--sub_default", "1s"},
+	})
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errOut, "ambiguous flag --sub_default could be --sub_default_lock_timeout, --sub_default_name\n")
+	assert.Contains(t, errOut, "--sub_default_name string")
+}
+
+func TestAbbrevFlags_HiddenAndInternal(t *testing.T) {
+	type Conf struct {
+		Token   string `hidden:""`
+		Timeout time.Duration
+		Printer string
+	}
+
+	// --print_changed and the other internal flags aren't matched
+	c := co.Configure[Conf](&co.Options{
+		NoRecover:   true,
+		AbbrevFlags: true,
+		Args:        []string{"--t=1s", "--print=lp"},
+	})
+	assert.Equal(t, time.Second, c.Timeout)
+	assert.Equal(t, "lp", c.Printer)
+
+	code, _, errOut := configureExit(t, func(opts *co.Options) {
+		co.Configure[Conf](opts)
+	}, co.Options{
+		AbbrevFlags: true,
+		Args:        []string{"--tok=secret"},
+	})
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errOut, "unknown flag: --tok")
+}
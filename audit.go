@@ -0,0 +1,80 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the audit log of the configuration written to
+Options.AuditWriter
+*/
+package configurature
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// AuditFormat is the format of the records written to Options.AuditWriter
+type AuditFormat string
+
+// Audit record formats
+const (
+	AuditJSON   AuditFormat = "json"   // One JSON object per line
+	AuditLogfmt AuditFormat = "logfmt" // One line of key=value pairs per record
+)
+
+// auditRecord is the audit record of an option
+type auditRecord struct {
+	Option string `json:"option"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// audit writes a record of the value and source of every option to
+// Options.AuditWriter. Secret values are redacted.
+func (c *configurer) audit(fs *pflag.FlagSet) {
+	format := c.opts.AuditFormat
+	if format != "" && format != AuditJSON && format != AuditLogfmt {
+		panic(fmt.Sprintf("unsupported audit format: %s", format))
+	}
+	w := c.opts.AuditWriter
+	fs.VisitAll(func(fl *pflag.Flag) {
+		if _, ok := internalFlags[fl.Name]; ok {
+			return
+		}
+		source, ok := c.sources[fl.Name]
+		if !ok {
+			source = "default"
+		}
+		r := auditRecord{Option: fl.Name, Value: redactedFlagValue(fl), Source: source}
+
+		if format == AuditLogfmt {
+			fmt.Fprintf(w, "option=%s value=%s source=%s\n",
+				logfmtValue(r.Option), logfmtValue(r.Value), logfmtValue(r.Source))
+			return
+		}
+		b, _ := json.Marshal(r)
+		fmt.Fprintf(w, "%s\n", b)
+	})
+}
+
+// logfmtValue quotes v if it is empty or contains spaces, quotes or "="
+func logfmtValue(v string) string {
+	if v == "" || strings.ContainsAny(v, " \t\n\"=\\") {
+		return strconv.Quote(v)
+	}
+	return v
+}
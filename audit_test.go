@@ -0,0 +1,77 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type AuditConf struct {
+	Host     string `default:"localhost"`
+	Password string `secret:""`
+	Database co.DSN `default:"postgres://app:s3cret@db:5432/app"`
+	Greeting string `default:"hello world"`
+}
+
+func TestAudit(t *testing.T) {
+	assert := assert.New(t)
+	out := &strings.Builder{}
+
+	co.Configure[AuditConf](&co.Options{
+		NoRecover:   true,
+		EnvPrefix:   "AUDIT_TEST_",
+		Environ:     map[string]string{"AUDIT_TEST_PASSWORD": "hunter2"},
+		Args:        []string{"--host", "example.com"},
+		AuditWriter: out,
+	})
+
+	assert.Equal(`{"option":"database","value":"postgres://app:xxxxx@db:5432/app","source":"default"}
+{"option":"greeting","value":"hello world","source":"default"}
+{"option":"host","value":"example.com","source":"flag"}
+{"option":"password","value":"xxxxx","source":"env"}
+`, out.String())
+}
+
+func TestAudit_Logfmt(t *testing.T) {
+	assert := assert.New(t)
+	out := &strings.Builder{}
+
+	co.Configure[AuditConf](&co.Options{
+		NoRecover:   true,
+		Args:        []string{},
+		AuditWriter: out,
+		AuditFormat: co.AuditLogfmt,
+	})
+
+	assert.Equal(`option=database value=postgres://app:xxxxx@db:5432/app source=default
+option=greeting value="hello world" source=default
+option=host value=localhost source=default
+option=password value="" source=default
+`, out.String())
+
+	assert.PanicsWithValue("unsupported audit format: xml", func() {
+		co.Configure[AuditConf](&co.Options{
+			NoRecover:   true,
+			Args:        []string{},
+			AuditWriter: out,
+			AuditFormat: "xml",
+		})
+	})
+}
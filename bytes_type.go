@@ -0,0 +1,124 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Value interface implementation for the Bytes type which
+is used to specify binary values such as keys, salts and tokens on a
+configurature struct
+*/
+package configurature
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+
+	"github.com/spf13/pflag"
+)
+
+// Type representing binary data specified as base64, or as hex when the field
+// is tagged with encoding:"hex". Standard and URL-safe base64 are accepted,
+// with or without padding. Values are redacted in templates.
+type Bytes []byte
+
+func (b *Bytes) String() string {
+	return base64.StdEncoding.EncodeToString(*b)
+}
+
+func (b *Bytes) Set(v string) error {
+	for _, enc := range []*base64.Encoding{
+		base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding,
+	} {
+		if d, err := enc.DecodeString(v); err == nil {
+			*b = d
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid base64 value")
+}
+
+func (b *Bytes) Type() string {
+	return "base64"
+}
+
+// Redacted returns "xxxxx" if b is not empty. It is used when printing
+// templates.
+func (b Bytes) Redacted() string {
+	if len(b) == 0 {
+		return ""
+	}
+	return "xxxxx"
+}
+
+// hexBytesValue wraps the Value of a Bytes flag so that it is specified as hex
+type hexBytesValue struct {
+	*Bytes
+}
+
+func (h *hexBytesValue) String() string {
+	return hex.EncodeToString(*h.Bytes)
+}
+
+func (h *hexBytesValue) Set(v string) error {
+	d, err := hex.DecodeString(v)
+	if err != nil {
+		return fmt.Errorf("invalid hex value")
+	}
+	*h.Bytes = d
+	return nil
+}
+
+func (h *hexBytesValue) Type() string {
+	return "hex"
+}
+
+func (h *hexBytesValue) unwrap() pflag.Value {
+	return h.Bytes
+}
+
+// bytesEncoding returns the encoding of field of type t specified by its
+// encoding tag
+func bytesEncoding(name string, t reflect.Type, tags *reflect.StructTag) string {
+	enc, ok := tags.Lookup("encoding")
+	if !ok {
+		return "base64"
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != reflect.TypeFor[Bytes]() {
+		panic(fmt.Sprintf("encoding tag on field %s: only Bytes fields support encoding", name))
+	}
+	if enc != "base64" && enc != "hex" {
+		panic(fmt.Sprintf("encoding tag on field %s: must be \"base64\" or \"hex\"", name))
+	}
+	return enc
+}
+
+// hexToBase64 converts the hex default value of a field to base64
+func hexToBase64(name string, def string) string {
+	d, err := hex.DecodeString(def)
+	if err != nil {
+		panic(fmt.Sprintf("Error setting default value for field %s: invalid hex value", name))
+	}
+	return base64.StdEncoding.EncodeToString(d)
+}
+
+// setFlagHex wraps the Value of the named Bytes flag in a hexBytesValue
+func setFlagHex(fs *pflag.FlagSet, name string) {
+	fl := fs.Lookup(name)
+	fl.Value = &hexBytesValue{fl.Value.(*Bytes)}
+	fl.DefValue = fl.Value.String()
+}
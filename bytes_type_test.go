@@ -0,0 +1,96 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type BytesConf struct {
+	Key  co.Bytes  `help:"signing key" default:"c2VjcmV0"`
+	Salt co.Bytes  `help:"salt" encoding:"hex" default:"0a0b"`
+	Opt  *co.Bytes `help:"optional"`
+}
+
+func TestBytes(t *testing.T) {
+	assert := assert.New(t)
+
+	c := co.Configure[BytesConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+	})
+	assert.Equal(co.Bytes("secret"), c.Key)
+	assert.Equal(co.Bytes{0x0a, 0x0b}, c.Salt)
+	assert.Empty(*c.Opt)
+
+	t.Setenv("BYTES_SALT", "ff00")
+	c = co.Configure[BytesConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--key", "-_8", "--opt", "aGk="},
+		EnvPrefix: "BYTES_",
+	})
+	assert.Equal(co.Bytes{0xfb, 0xff}, c.Key)
+	assert.Equal(co.Bytes{0xff, 0x00}, c.Salt)
+	assert.Equal(co.Bytes("hi"), *c.Opt)
+}
+
+func TestBytes_Invalid(t *testing.T) {
+	assert := assert.New(t)
+	b := new(co.Bytes)
+	assert.EqualError(b.Set("not base64!"), "invalid base64 value")
+
+	type Conf struct {
+		Name string `encoding:"hex"`
+	}
+	assert.PanicsWithValue("encoding tag on field Name: only Bytes fields support encoding", func() {
+		co.Configure[Conf](&co.Options{NoRecover: true, Args: []string{}})
+	})
+}
+
+func TestBytes_EnvTemplateRedacted(t *testing.T) {
+	if os.Getenv("TEST_PASSTHROUGH") == "1" {
+		co.Configure[BytesConf](&co.Options{
+			Args:      []string{"--print_env_template"},
+			EnvPrefix: "APP_",
+		})
+		panic("Should have exited")
+	}
+
+	stdout, stderr := runExternal(t)
+	assert.Equal(t, "", stderr)
+	assert.Contains(t, stdout, `APP_KEY="xxxxx"`)
+	assert.Contains(t, stdout, `APP_SALT="xxxxx"`)
+	assert.Contains(t, stdout, `APP_OPT=""`)
+	assert.NotContains(t, stdout, "c2VjcmV0")
+}
+
+func TestBytes_Usage(t *testing.T) {
+	if os.Getenv("TEST_PASSTHROUGH") == "1" {
+		co.Configure[BytesConf](&co.Options{
+			Args: []string{"-h"},
+		})
+		panic("Should have exited")
+	}
+
+	stdout, _ := runExternal(t)
+	assert.Contains(t, stdout, "--key base64")
+	assert.Contains(t, stdout, "--salt hex")
+	assert.Contains(t, stdout, `(default 0a0b)`)
+}
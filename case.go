@@ -0,0 +1,102 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the case conversion functions used when strcase is not
+included in the build. See case_strcase.go and case_core.go.
+*/
+package configurature
+
+import "strings"
+
+// isUpper, isLower and isDigit report the class of an ASCII byte
+func isUpper(b byte) bool { return b >= 'A' && b <= 'Z' }
+func isLower(b byte) bool { return b >= 'a' && b <= 'z' }
+func isDigit(b byte) bool { return b >= '0' && b <= '9' }
+
+// delimitedCase converts s to lower (or upper if screaming) case words
+// separated by delim. Words are split where the case of letters changes and
+// between letters and digits, and acronyms are kept together. E.g.
+// "JSONData2" -> "json_data_2". It matches strcase.ToScreamingDelimited.
+func delimitedCase(s string, delim byte, screaming bool) string {
+	s = strings.TrimSpace(s)
+	out := make([]byte, 0, len(s)+2)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		upper, lower, digit := isUpper(c), isLower(c), isDigit(c)
+		if lower && screaming {
+			c -= 'a' - 'A'
+		} else if upper && !screaming {
+			c += 'a' - 'A'
+		}
+
+		if i+1 < len(s) {
+			next := s[i+1]
+			nextUpper, nextLower, nextDigit := isUpper(next), isLower(next), isDigit(next)
+			if (upper && (nextLower || nextDigit)) || (lower && (nextUpper || nextDigit)) || (digit && (nextUpper || nextLower)) {
+				// The last capital of an acronym starts a new word. E.g.
+				// the "D" in "JSONData"
+				if upper && nextLower && i > 0 && isUpper(s[i-1]) {
+					out = append(out, delim)
+				}
+				out = append(out, c)
+				if lower || digit || nextDigit {
+					out = append(out, delim)
+				}
+				continue
+			}
+		}
+
+		switch c {
+		case ' ', '_', '-', '.':
+			out = append(out, delim)
+		default:
+			out = append(out, c)
+		}
+	}
+	return string(out)
+}
+
+// lowerCamelCase converts s to lowerCamelCase. E.g. "listen_port" ->
+// "listenPort". It matches strcase.ToLowerCamel.
+func lowerCamelCase(s string) string {
+	s = strings.TrimSpace(s)
+	out := make([]byte, 0, len(s))
+	capNext := false
+	prevUpper := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		upper, lower := isUpper(c), isLower(c)
+		if capNext {
+			if lower {
+				c -= 'a' - 'A'
+			}
+		} else if (i == 0 || prevUpper) && upper {
+			c += 'a' - 'A'
+		}
+		prevUpper = upper
+
+		switch {
+		case upper || lower:
+			out = append(out, c)
+			capNext = false
+		case isDigit(c):
+			out = append(out, c)
+			capNext = true
+		default:
+			capNext = c == '_' || c == ' ' || c == '-' || c == '.'
+		}
+	}
+	return string(out)
+}
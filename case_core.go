@@ -0,0 +1,36 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build configurature_core
+
+/*
+This file contains the case conversion functions used in the core build,
+which does not depend on strcase
+*/
+package configurature
+
+// toSnake converts s to snake_case
+func toSnake(s string) string {
+	return delimitedCase(s, '_', false)
+}
+
+// toScreamingSnake converts s to SCREAMING_SNAKE_CASE
+func toScreamingSnake(s string) string {
+	return delimitedCase(s, '_', true)
+}
+
+// toLowerCamel converts s to lowerCamelCase
+func toLowerCamel(s string) string {
+	return lowerCamelCase(s)
+}
@@ -0,0 +1,43 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature
+
+import (
+	"testing"
+
+	"github.com/iancoleman/strcase"
+	"github.com/stretchr/testify/assert"
+)
+
+var caseTestInputs = []string{
+	"", "a", "A", "Port", "ListenPort", "listen_port", "listen-port",
+	"listen.port", " Listen Port ", "DNs", "URLs", "JSONData", "HTTPServer",
+	"version2", "Version2Name", "v2", "IPv6Addr", "TLSConfig", "foo_bar",
+	"Foo_Bar", "fooBar", "FOO_BAR", "ID", "UserID", "a1b2c3", "ABC123Def",
+	"snake__case", "trailing_", "_leading", "mixed-Case_string.Name",
+}
+
+func TestDelimitedCase(t *testing.T) {
+	for _, s := range caseTestInputs {
+		assert.Equal(t, strcase.ToSnake(s), delimitedCase(s, '_', false), s)
+		assert.Equal(t, strcase.ToScreamingSnake(s), delimitedCase(s, '_', true), s)
+	}
+}
+
+func TestLowerCamelCase(t *testing.T) {
+	for _, s := range caseTestInputs {
+		assert.Equal(t, strcase.ToLowerCamel(s), lowerCamelCase(s), s)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the consolidated cfg tag, which holds the other tags in a
+single namespace
+*/
+package configurature
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// cfgTag is the name of the consolidated tag
+const cfgTag = "cfg"
+
+// ExpandCfgTag returns tag with the entries of its cfg tag appended as
+// discrete tags, so that discrete tags take precedence. Entries are separated
+// by commas and are either key=value or a key alone. desc is an alias of help,
+// enum values are separated by "|", and values containing commas can be single
+// quoted. E.g.
+//
+//	cfg:"name=listen,short=l,default=:8080,desc=Listen address,required"
+func ExpandCfgTag(tag reflect.StructTag) (reflect.StructTag, error) {
+	cfg, ok := tag.Lookup(cfgTag)
+	if !ok {
+		return tag, nil
+	}
+	items, err := parseTagItems(cfg)
+	if err != nil {
+		return tag, err
+	}
+	tags := []string{string(tag)}
+	for _, item := range items {
+		key, value := item[0], item[1]
+		switch key {
+		case "desc":
+			key = "help"
+		case "enum":
+			value = strings.ReplaceAll(value, "|", ",")
+		}
+		tags = append(tags, key+":"+strconv.Quote(value))
+	}
+	return reflect.StructTag(strings.Join(tags, " ")), nil
+}
+
+// parseTagItems returns the key and value pairs of a comma separated list of
+// key=value items, such as a cfg tag. Values containing commas can be single
+// quoted.
+func parseTagItems(s string) ([][2]string, error) {
+	items := [][2]string{}
+	for s != "" {
+		item, rest, _ := strings.Cut(s, ",")
+		key, value, _ := strings.Cut(item, "=")
+		if v, ok := strings.CutPrefix(s[len(key):], "='"); ok {
+			// A quoted value may contain commas
+			end := strings.Index(v, "'")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quote in %s", key)
+			}
+			value, rest = v[:end], v[end+1:]
+			if rest != "" && rest[0] != ',' {
+				return nil, fmt.Errorf("unexpected %q after quoted value of %s", rest, key)
+			}
+			rest = strings.TrimPrefix(rest, ",")
+		}
+		s = rest
+
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, errors.New("empty entry")
+		}
+		items = append(items, [2]string{key, value})
+	}
+	return items, nil
+}
+
+// withCfgTag returns field with its cfg tag expanded
+func withCfgTag(field reflect.StructField) reflect.StructField {
+	tag, err := ExpandCfgTag(field.Tag)
+	if err != nil {
+		panic(fmt.Sprintf("invalid cfg tag on field %s: %v", field.Name, err))
+	}
+	field.Tag = tag
+	return field
+}
@@ -0,0 +1,74 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestCfgTag(t *testing.T) {
+	type Conf struct {
+		Listen string   `cfg:"name=addr,short=l,default=:8080,desc=Listen address,required"`
+		Level  string   `cfg:"enum=debug|info,default=info" default:"debug"`
+		Hosts  []string `cfg:"default='a,b',desc=The server's hosts"`
+		Other  string   `cfg:"ignore"`
+	}
+	assert := assert.New(t)
+
+	c := co.Configure[Conf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"-l", ":9090"},
+	})
+	assert.Equal(":9090", c.Listen)
+	assert.Equal("debug", c.Level, "discrete tags take precedence")
+	assert.Equal([]string{"a", "b"}, c.Hosts)
+
+	fields := co.Fields[Conf](nil)
+	assert.Len(fields, 3)
+	assert.Equal("addr", fields[0].Name)
+	assert.Equal("Listen address", fields[0].Description)
+	assert.True(fields[0].Required)
+	assert.Equal([]string{"debug", "info"}, fields[1].Enum)
+	assert.Equal("The server's hosts", fields[2].Description)
+
+	assert.PanicsWithValue("addr is required", func() {
+		co.Configure[Conf](&co.Options{
+			NoRecover: true,
+			Args:      []string{},
+		})
+	})
+}
+
+func TestExpandCfgTag(t *testing.T) {
+	assert := assert.New(t)
+
+	tag, err := co.ExpandCfgTag(`json:"x" cfg:"desc='a, b',hidden"`)
+	assert.NoError(err)
+	assert.Equal(reflect.StructTag(`json:"x" cfg:"desc='a, b',hidden" help:"a, b" hidden:""`), tag)
+
+	_, err = co.ExpandCfgTag(`cfg:"default='a"`)
+	assert.EqualError(err, "unterminated quote in default")
+
+	_, err = co.ExpandCfgTag(`cfg:"default='a'b"`)
+	assert.EqualError(err, `unexpected "b" after quoted value of default`)
+
+	_, err = co.ExpandCfgTag(`cfg:"required,,hidden"`)
+	assert.EqualError(err, "empty entry")
+}
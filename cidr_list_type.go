@@ -0,0 +1,74 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Value interface implementation for the CIDRList type
+which is used to specify allowlists and denylists of network blocks on a
+configurature struct
+*/
+package configurature
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Type representing a list of comma separated CIDR blocks. E.g.
+// "10.0.0.0/8,192.168.0.0/16,fd00::/8". Blocks may not overlap.
+type CIDRList []net.IPNet
+
+func (c *CIDRList) String() string {
+	blocks := make([]string, len(*c))
+	for i, n := range *c {
+		blocks[i] = n.String()
+	}
+	return strings.Join(blocks, ",")
+}
+
+func (c *CIDRList) Set(v string) error {
+	list := CIDRList{}
+	for _, b := range strings.Split(v, ",") {
+		b = strings.TrimSpace(b)
+		if b == "" {
+			continue
+		}
+		_, n, err := net.ParseCIDR(b)
+		if err != nil {
+			return fmt.Errorf("invalid CIDR block \"%s\"", b)
+		}
+		for _, o := range list {
+			if o.Contains(n.IP) || n.Contains(o.IP) {
+				return fmt.Errorf("CIDR block %s overlaps %s", n, &o)
+			}
+		}
+		list = append(list, *n)
+	}
+	*c = list
+	return nil
+}
+
+func (c *CIDRList) Type() string {
+	return "cidrs"
+}
+
+// Contains returns true if ip is in any of the blocks in the list
+func (c CIDRList) Contains(ip net.IP) bool {
+	for _, n := range c {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestCIDRList(t *testing.T) {
+	type CIDRConf struct {
+		Allow co.CIDRList `default:"10.0.0.0/8, 192.168.1.0/24"`
+		Deny  co.CIDRList
+	}
+	assert := assert.New(t)
+
+	c := co.Configure[CIDRConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--deny", "10.1.2.3/16,fd00::/8"},
+	})
+
+	assert.Equal("10.0.0.0/8,192.168.1.0/24", c.Allow.String())
+	assert.Equal("10.1.0.0/16,fd00::/8", c.Deny.String())
+	assert.True(c.Allow.Contains(net.ParseIP("192.168.1.9")))
+	assert.False(c.Allow.Contains(net.ParseIP("192.168.2.9")))
+	assert.True(c.Deny.Contains(net.ParseIP("fd00::1")))
+}
+
+func TestCIDRList_Invalid(t *testing.T) {
+	assert := assert.New(t)
+	c := new(co.CIDRList)
+
+	assert.EqualError(c.Set("10.0.0.0/8,nope"), `invalid CIDR block "nope"`)
+	assert.EqualError(c.Set("10.0.0.0/8,10.2.0.0/16"), "CIDR block 10.2.0.0/16 overlaps 10.0.0.0/8")
+	assert.EqualError(c.Set("10.2.0.0/16,10.0.0.0/8"), "CIDR block 10.0.0.0/8 overlaps 10.2.0.0/16")
+}
@@ -0,0 +1,168 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Clone function and the deep copy and checksum helpers
+used to hand out snapshots of loaded configurations
+*/
+package configurature
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// deepCopy returns a copy of v whose pointers, slices, maps and interfaces
+// refer to copies of what v refers to. Unexported struct fields are copied
+// shallowly.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(deepCopy(iter.Key()), deepCopy(iter.Value()))
+		}
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return c
+	}
+	return v
+}
+
+// Clone returns a deep copy of config, including what its pointer, slice, map
+// and interface fields refer to, which can be modified without affecting
+// config. Unexported struct fields, such as those of time.Time, are copied
+// shallowly. Returns nil if config is nil.
+func Clone[T any](config *T) *T {
+	if config == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(config)).Interface().(*T)
+}
+
+// checksum returns the SHA-256 checksum of the values config refers to
+func checksum(config any) [sha256.Size]byte {
+	h := sha256.New()
+	writeValue(h, reflect.ValueOf(config), map[uintptr]bool{})
+	return [sha256.Size]byte(h.Sum(nil))
+}
+
+// writeValue writes v, and the values it refers to, to w. Values of types
+// implementing Value, such as GoTemplate, are written as their String(), and
+// pointers already in visited are not followed again, so that values that
+// refer back to themselves can be written.
+func writeValue(w io.Writer, v reflect.Value, visited map[uintptr]bool) {
+	if s, ok := valueString(v); ok {
+		fmt.Fprintf(w, "%q;", s)
+		return
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			fmt.Fprint(w, "nil;")
+			return
+		}
+		if visited[v.Pointer()] {
+			fmt.Fprintf(w, "&%x;", v.Pointer())
+			return
+		}
+		visited[v.Pointer()] = true
+		writeValue(w, v.Elem(), visited)
+	case reflect.Interface:
+		if v.IsNil() {
+			fmt.Fprint(w, "nil;")
+			return
+		}
+		writeValue(w, v.Elem(), visited)
+	case reflect.Slice, reflect.Array:
+		fmt.Fprintf(w, "[%d:", v.Len())
+		for i := 0; i < v.Len(); i++ {
+			writeValue(w, v.Index(i), visited)
+		}
+		fmt.Fprint(w, "]")
+	case reflect.Map:
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+		})
+		fmt.Fprintf(w, "{%d:", v.Len())
+		for _, k := range keys {
+			writeValue(w, k, visited)
+			writeValue(w, v.MapIndex(k), visited)
+		}
+		fmt.Fprint(w, "}")
+	case reflect.Struct:
+		fmt.Fprint(w, "{")
+		for i := 0; i < v.NumField(); i++ {
+			writeValue(w, v.Field(i), visited)
+		}
+		fmt.Fprint(w, "}")
+	default:
+		fmt.Fprintf(w, "%v;", v)
+	}
+}
+
+// valueString returns the String() of v if its type implements Value
+func valueString(v reflect.Value) (string, bool) {
+	if v.Kind() == reflect.Ptr || !v.CanInterface() || !reflect.PointerTo(v.Type()).Implements(reflect.TypeFor[Value]()) {
+		return "", false
+	}
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	return p.Interface().(Value).String(), true
+}
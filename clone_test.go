@@ -0,0 +1,64 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestClone(t *testing.T) {
+	type CloneSub struct {
+		Hosts []string         `default:"a,b"`
+		Ports map[string]int   `default:"http=80"`
+		Level *int             `default:"3"`
+		Start time.Time        `default:"2024-01-02T03:04:05Z"`
+		Extra map[string][]int `ignore:""`
+	}
+	type CloneConf struct {
+		Name string `default:"app"`
+		Sub  *CloneSub
+	}
+	assert := assert.New(t)
+
+	c := co.Configure[CloneConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+	})
+	c.Sub.Extra = map[string][]int{"x": {1}}
+
+	cl := co.Clone(c)
+	assert.Equal(c, cl)
+
+	cl.Name = "other"
+	cl.Sub.Hosts[0] = "z"
+	cl.Sub.Ports["http"] = 8080
+	*cl.Sub.Level = 9
+	cl.Sub.Start = cl.Sub.Start.Add(time.Hour)
+	cl.Sub.Extra["x"][0] = 2
+
+	assert.Equal("app", c.Name)
+	assert.Equal([]string{"a", "b"}, c.Sub.Hosts)
+	assert.Equal(map[string]int{"http": 80}, c.Sub.Ports)
+	assert.Equal(3, *c.Sub.Level)
+	assert.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), c.Sub.Start)
+	assert.Equal(map[string][]int{"x": {1}}, c.Sub.Extra)
+
+	assert.Nil(co.Clone[CloneConf](nil))
+}
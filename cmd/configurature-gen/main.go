@@ -0,0 +1,476 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+configurature-gen generates a RegisterFlags method for configurature config
+structs so that they can be loaded with configurature.ConfigureGenerated
+without reflection. Use it with go:generate:
+
+	//go:generate go run github.com/imoore76/configurature/cmd/configurature-gen -type Config
+
+Fields must be of a type supported by pflag, of a type whose pointer
+implements configurature.Value, or of a type registered with configurature
+such as time.Time. Nested config structs must be defined in the
+same package. A field's doc comment is used as its description when it has no
+help tag.
+
+With -descriptions, only the doc comments are extracted. The generated code
+registers them with configurature.AddDescriptions so that they are used by
+configurature.Configure:
+
+	//go:generate go run github.com/imoore76/configurature/cmd/configurature-gen -type Config -descriptions
+*/
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"maps"
+	"os"
+	fp "path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+
+	co "github.com/imoore76/configurature"
+)
+
+// pflagTypes maps field type expressions to the pflag.FlagSet method name
+// (without "VarP") used to add them
+var pflagTypes = map[string]string{
+	"string":            "String",
+	"bool":              "Bool",
+	"int":               "Int",
+	"int8":              "Int8",
+	"int16":             "Int16",
+	"int32":             "Int32",
+	"int64":             "Int64",
+	"uint":              "Uint",
+	"uint8":             "Uint8",
+	"uint16":            "Uint16",
+	"uint32":            "Uint32",
+	"uint64":            "Uint64",
+	"float32":           "Float32",
+	"float64":           "Float64",
+	"time.Duration":     "Duration",
+	"net.IP":            "IP",
+	"net.IPMask":        "IPMask",
+	"net.IPNet":         "IPNet",
+	"[]byte":            "BytesHex", // Like Configure. Use configurature.Bytes for base64
+	"[]string":          "StringSlice",
+	"[]bool":            "BoolSlice",
+	"[]int":             "IntSlice",
+	"[]int32":           "Int32Slice",
+	"[]int64":           "Int64Slice",
+	"[]uint":            "UintSlice",
+	"[]float32":         "Float32Slice",
+	"[]float64":         "Float64Slice",
+	"[]time.Duration":   "DurationSlice",
+	"[]net.IP":          "IPSlice",
+	"[]net.IPNet":       "IPNetSlice",
+	"map[string]string": "StringToString",
+	"map[string]int":    "StringToInt",
+	"map[string]int64":  "StringToInt64",
+}
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of config struct type names; must be set")
+	output := flag.String("output", "", "output file name; default <type>_configurature.go")
+	dir := flag.String("dir", ".", "directory of the package containing the types")
+	descriptions := flag.Bool("descriptions", false, "only generate code registering doc comments as descriptions")
+	flag.Parse()
+
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	types := strings.Split(*typeNames, ",")
+
+	if *output == "" {
+		*output = strings.ToLower(types[0]) + "_configurature.go"
+	}
+	outPath := fp.Join(*dir, *output)
+
+	src, err := generate(*dir, types, fp.Base(outPath), *descriptions)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "configurature-gen: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(outPath, src, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "configurature-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// generator holds the state of code generation for a package
+type generator struct {
+	pkgName    string
+	structs    map[string]*ast.StructType
+	buf        bytes.Buffer
+	flagPaths  map[string]string // Path of the field of each flag, used to report duplicates
+	shortPaths map[string]string // Path of the field of each short flag, used to report duplicates
+	aliases    map[string]string // Config file keys from yaml and json tags; see addFileKeyAliases()
+}
+
+// generate parses the package in dir, skipping the file named skip, and
+// returns formatted source code registering flags, or only descriptions, for
+// the given types
+func generate(dir string, types []string, skip string, descriptions bool) ([]byte, error) {
+	g, err := newGenerator(dir, types[0], skip)
+	if err != nil {
+		return nil, err
+	}
+
+	if descriptions {
+		return g.generateDescriptions(types)
+	}
+
+	fmt.Fprintf(&g.buf, "// Code generated by configurature-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&g.buf, "package %s\n\n", g.pkgName)
+	fmt.Fprintf(&g.buf, "import (\n\t\"github.com/spf13/pflag\"\n\n")
+	fmt.Fprintf(&g.buf, "\tco \"github.com/imoore76/configurature\"\n)\n")
+
+	for _, t := range types {
+		st, ok := g.structs[t]
+		if !ok {
+			return nil, fmt.Errorf("struct type %s not found in package %s", t, g.pkgName)
+		}
+		fmt.Fprintf(&g.buf, "\n// RegisterFlags adds flags bound to the fields of %s to fs\n", t)
+		fmt.Fprintf(&g.buf, "func (c *%s) RegisterFlags(fs *pflag.FlagSet) {\n", t)
+		g.flagPaths, g.shortPaths, g.aliases = map[string]string{}, map[string]string{}, map[string]string{}
+		if err := g.genStruct(st, "c", []string{}); err != nil {
+			return nil, fmt.Errorf("%s: %w", t, err)
+		}
+		fmt.Fprintf(&g.buf, "}\n")
+		g.genFileKeyAliases(t)
+	}
+
+	return format.Source(g.buf.Bytes())
+}
+
+// newGenerator parses the Go files in dir belonging to the package that
+// declares typeName and collects the struct types declared in it
+func newGenerator(dir string, typeName string, skip string) (*generator, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	files := []*ast.File{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") || e.Name() == skip {
+			continue
+		}
+		f, err := parser.ParseFile(fset, fp.Join(dir, e.Name()), nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	// Find the package that declares typeName. A directory may contain both
+	// a package and its _test package.
+	g := &generator{}
+	pkgStructs := map[string]map[string]*ast.StructType{}
+	for _, f := range files {
+		pkg := f.Name.Name
+		if pkgStructs[pkg] == nil {
+			pkgStructs[pkg] = map[string]*ast.StructType{}
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			if ts, ok := n.(*ast.TypeSpec); ok {
+				if st, ok := ts.Type.(*ast.StructType); ok {
+					pkgStructs[pkg][ts.Name.Name] = st
+					if ts.Name.Name == typeName {
+						g.pkgName = pkg
+					}
+				}
+			}
+			return true
+		})
+	}
+	if g.pkgName == "" {
+		return nil, fmt.Errorf("struct type %s not found in %s", typeName, dir)
+	}
+	g.structs = pkgStructs[g.pkgName]
+	return g, nil
+}
+
+// genStruct writes flag registration code for the fields of a struct. path
+// is the Go expression used to access the struct.
+func (g *generator) genStruct(st *ast.StructType, path string, ancestors []string) error {
+	for _, field := range st.Fields.List {
+		tags := reflect.StructTag("")
+		if field.Tag != nil {
+			tag, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return err
+			}
+			if tags, err = co.ExpandCfgTag(reflect.StructTag(tag)); err != nil {
+				return fmt.Errorf("invalid cfg tag %s: %v", field.Tag.Value, err)
+			}
+		}
+
+		// Skip any fields tagged with ignore:""
+		if _, ok := tags.Lookup("ignore"); ok {
+			continue
+		}
+
+		// Handle anonymous struct fields, which are sub-configs. Pointers to
+		// them are allocated.
+		if len(field.Names) == 0 {
+			typ := field.Type
+			if star, ok := typ.(*ast.StarExpr); ok {
+				typ = star.X
+			}
+			name := typeString(typ)
+			st, ok := g.structs[name]
+			if !ok {
+				return fmt.Errorf("embedded type %s is not a struct in this package", name)
+			}
+			if typ != field.Type {
+				fmt.Fprintf(&g.buf, "\tco.GeneratedPtr(&%s.%s)\n", path, name)
+			}
+			if err := g.genStruct(st, path+"."+name, ancestors); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			fieldPath := path + "." + ident.Name
+
+			// Handle nested config structs and pointers to them, which are
+			// allocated
+			typ := field.Type
+			if star, ok := typ.(*ast.StarExpr); ok {
+				typ = star.X
+			}
+			if id, ok := typ.(*ast.Ident); ok {
+				if st, ok := g.structs[id.Name]; ok {
+					if typ != field.Type {
+						fmt.Fprintf(&g.buf, "\tco.GeneratedPtr(&%s)\n", fieldPath)
+					}
+					fName := ident.Name
+					if name, ok := tags.Lookup("name"); ok {
+						fName = name
+					}
+					g.addFileKeyAliases(tags, strcase.ToSnake(fName), ancestors)
+					newAncestors := ancestors
+					if fName != "" {
+						newAncestors = append(append([]string{}, ancestors...), strcase.ToSnake(fName))
+					}
+					if err := g.genStruct(st, fieldPath, newAncestors); err != nil {
+						return err
+					}
+					continue
+				}
+			}
+
+			if err := g.genField(ident.Name, field.Type, fieldPath, tags, fieldDoc(field), ancestors); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// genField writes flag registration code for a single field. It returns an
+// error if another field resolves to the same flag name or short flag.
+func (g *generator) genField(name string, expr ast.Expr, fieldPath string, tags reflect.StructTag, doc string, ancestors []string) error {
+	if nm, ok := tags.Lookup("name"); ok && nm != "" {
+		name = nm
+	}
+	fName := strings.Join(append(append([]string{}, ancestors...), strcase.ToSnake(name)), "_")
+	if other, ok := g.flagPaths[fName]; ok {
+		return fmt.Errorf("fields %s and %s both resolve to flag --%s",
+			strings.TrimPrefix(other, "c."), strings.TrimPrefix(fieldPath, "c."), fName)
+	}
+	g.flagPaths[fName] = fieldPath
+	g.addFileKeyAliases(tags, strcase.ToSnake(name), ancestors)
+
+	help, ok := tags.Lookup("help")
+	if !ok {
+		help = doc
+	}
+	if !ok && help == "" {
+		help = strings.ReplaceAll(fName, "_", " ")
+	}
+	if enums := tags.Get("enum"); enums != "" {
+		help += fmt.Sprintf(" (%s)", strings.ReplaceAll(enums, ",", "|"))
+	}
+	short := tags.Get("short")
+	if other, ok := g.shortPaths[short]; ok && short != "" {
+		return fmt.Errorf("fields %s and %s both use short flag -%s",
+			strings.TrimPrefix(other, "c."), strings.TrimPrefix(fieldPath, "c."), short)
+	}
+	g.shortPaths[short] = fieldPath
+	def := tags.Get("default")
+
+	// Pointer fields are allocated and bound to
+	ptr := "&" + fieldPath
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+		ptr = fmt.Sprintf("co.GeneratedPtr(&%s)", fieldPath)
+	}
+
+	q := strconv.Quote
+	if method, ok := pflagTypes[typeString(expr)]; ok {
+		fmt.Fprintf(&g.buf, "\tfs.%sVarP(%s, %s, %s, co.GeneratedDefault((*pflag.FlagSet).%sVar, %s, %s), %s)\n",
+			method, ptr, q(fName), q(short), method, q(fName), q(def), q(help))
+	} else {
+		// Anything else must implement configurature.Value or be registered
+		// with configurature, e.g. time.Time
+		fmt.Fprintf(&g.buf, "\tfs.VarP(co.GeneratedValue(%s, %s, %s), %s, %s, %s)\n",
+			ptr, q(fName), q(def), q(fName), q(short), q(help))
+	}
+	fmt.Fprintf(&g.buf, "\tco.AnnotateGeneratedFlag(fs, %s, %s", q(fName), q(string(tags)))
+	for _, a := range ancestors {
+		fmt.Fprintf(&g.buf, ", %s", q(a))
+	}
+	g.buf.WriteString(")\n")
+	return nil
+}
+
+// addFileKeyAliases records the config file keys given by the yaml and json
+// tags of a field with the config name name. This mirrors the fileKeyAliases()
+// function of configurature.
+func (g *generator) addFileKeyAliases(tags reflect.StructTag, name string, ancestors []string) {
+	for _, tagName := range []string{"yaml", "json"} {
+		key, _, _ := strings.Cut(tags.Get(tagName), ",")
+		if key != "" && key != "-" && key != name {
+			g.aliases[strings.Join(append(append([]string{}, ancestors...), key), "_")] = name
+		}
+	}
+}
+
+// genFileKeyAliases writes a FileKeyAliases method returning the config
+// file keys recorded for type t, if there are any
+func (g *generator) genFileKeyAliases(t string) {
+	if len(g.aliases) == 0 {
+		return
+	}
+	fmt.Fprintf(&g.buf, "\n// FileKeyAliases returns the config file keys given by the yaml and json\n")
+	fmt.Fprintf(&g.buf, "// tags of the fields of %s\n", t)
+	fmt.Fprintf(&g.buf, "func (c *%s) FileKeyAliases() map[string]string {\n", t)
+	fmt.Fprintf(&g.buf, "\treturn map[string]string{\n")
+	for _, k := range slices.Sorted(maps.Keys(g.aliases)) {
+		fmt.Fprintf(&g.buf, "\t\t%s: %s,\n", strconv.Quote(k), strconv.Quote(g.aliases[k]))
+	}
+	fmt.Fprintf(&g.buf, "\t}\n}\n")
+}
+
+// generateDescriptions returns formatted source code registering the doc
+// comments of the fields of the given types, and the config structs nested
+// in them, as descriptions
+func (g *generator) generateDescriptions(types []string) ([]byte, error) {
+	fmt.Fprintf(&g.buf, "// Code generated by configurature-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&g.buf, "package %s\n\n", g.pkgName)
+	fmt.Fprintf(&g.buf, "import co \"github.com/imoore76/configurature\"\n\n")
+	fmt.Fprintf(&g.buf, "func init() {\n")
+
+	seen := map[string]bool{}
+	var visit func(string) error
+	visit = func(t string) error {
+		if seen[t] {
+			return nil
+		}
+		seen[t] = true
+		st, ok := g.structs[t]
+		if !ok {
+			return fmt.Errorf("struct type %s not found in package %s", t, g.pkgName)
+		}
+
+		descs := []string{}
+		nested := []string{}
+		for _, field := range st.Fields.List {
+			if _, ok := g.structs[typeString(field.Type)]; ok {
+				nested = append(nested, typeString(field.Type))
+				continue
+			}
+			if field.Tag != nil {
+				tag, err := strconv.Unquote(field.Tag.Value)
+				if err != nil {
+					return err
+				}
+				tags, err := co.ExpandCfgTag(reflect.StructTag(tag))
+				if err != nil {
+					return fmt.Errorf("invalid cfg tag %s: %v", field.Tag.Value, err)
+				}
+				// Fields with a help tag or ignore tag don't need descriptions
+				if _, ok := tags.Lookup("help"); ok {
+					continue
+				}
+				if _, ok := tags.Lookup("ignore"); ok {
+					continue
+				}
+			}
+			doc := fieldDoc(field)
+			if doc == "" {
+				continue
+			}
+			for _, ident := range field.Names {
+				descs = append(descs, fmt.Sprintf("\t\t%s: %s,\n", strconv.Quote(ident.Name), strconv.Quote(doc)))
+			}
+		}
+
+		if len(descs) > 0 {
+			fmt.Fprintf(&g.buf, "\tco.AddDescriptions[%s](map[string]string{\n%s\t})\n", t, strings.Join(descs, ""))
+		}
+		for _, n := range nested {
+			if err := visit(n); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, t := range types {
+		if err := visit(t); err != nil {
+			return nil, err
+		}
+	}
+	fmt.Fprintf(&g.buf, "}\n")
+
+	return format.Source(g.buf.Bytes())
+}
+
+// fieldDoc returns a field's doc comment, or its line comment if it has no
+// doc comment, as a single line
+func fieldDoc(field *ast.Field) string {
+	doc := field.Doc.Text()
+	if doc == "" {
+		doc = field.Comment.Text()
+	}
+	return strings.Join(strings.Fields(doc), " ")
+}
+
+// typeString returns the source representation of a type expression
+func typeString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	format.Node(&buf, token.NewFileSet(), expr)
+	return buf.String()
+}
@@ -0,0 +1,131 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate_UpToDate(t *testing.T) {
+	const outFile = "genconfig_configurature_test.go"
+	src, err := generate("../..", []string{"GenConfig"}, outFile, false)
+	assert.Nil(t, err)
+
+	existing, err := os.ReadFile("../../" + outFile)
+	assert.Nil(t, err)
+	assert.Equal(t, string(existing), string(src), "run go generate to update "+outFile)
+}
+
+func TestGenerate_DescriptionsUpToDate(t *testing.T) {
+	const outFile = "descconfig_configurature_test.go"
+	src, err := generate("../..", []string{"DescConfig"}, outFile, true)
+	assert.Nil(t, err)
+
+	existing, err := os.ReadFile("../../" + outFile)
+	assert.Nil(t, err)
+	assert.Equal(t, string(existing), string(src), "run go generate to update "+outFile)
+}
+
+func TestGenerate_TypeNotFound(t *testing.T) {
+	_, err := generate("../..", []string{"NoSuchConfig"}, "", false)
+	assert.EqualError(t, err, "struct type NoSuchConfig not found in ../..")
+}
+
+func TestGenerate_DuplicateFlag(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(dir+"/conf.go", []byte(`package conf
+
+type Server struct {
+	Port int
+}
+
+type Conf struct {
+	Server
+	ListenPort int `+"`name:\"port\"`"+`
+}
+`), 0644)
+	_, err := generate(dir, []string{"Conf"}, "", false)
+	assert.EqualError(t, err, "Conf: fields Server.Port and ListenPort both resolve to flag --port")
+}
+
+func TestGenerate_DuplicateShort(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(dir+"/conf.go", []byte(`package conf
+
+type Conf struct {
+	Port int `+"`short:\"p\"`"+`
+	Path int `+"`short:\"p\"`"+`
+}
+`), 0644)
+	_, err := generate(dir, []string{"Conf"}, "", false)
+	assert.EqualError(t, err, "Conf: fields Port and Path both use short flag -p")
+}
+
+func TestGenerate_SubConfigPtr(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(dir+"/conf.go", []byte(`package conf
+
+type Metrics struct {
+	Port int
+}
+
+type Conf struct {
+	Metrics *Metrics
+}
+`), 0644)
+	src, err := generate(dir, []string{"Conf"}, "", false)
+	assert.NoError(t, err)
+	assert.Contains(t, string(src), "\tco.GeneratedPtr(&c.Metrics)\n\tfs.IntVarP(&c.Metrics.Port, \"metrics_port\"")
+}
+
+func TestGenerate_CfgTag(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(dir+"/conf.go", []byte(`package conf
+
+type Conf struct {
+	Listen string `+"`cfg:\"name=addr,short=l,default=:8080,desc=Listen address\"`"+`
+}
+`), 0644)
+	src, err := generate(dir, []string{"Conf"}, "", false)
+	assert.NoError(t, err)
+	assert.Contains(t, string(src), `fs.StringVarP(&c.Listen, "addr", "l", co.GeneratedDefault((*pflag.FlagSet).StringVar, "addr", ":8080"), "Listen address")`)
+}
+
+func TestGenerate_EmbeddedPtrAndRegisteredType(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(dir+"/conf.go", []byte(`package conf
+
+import "time"
+
+type Server struct {
+	Port int `+"`yaml:\"listen_port\"`"+`
+}
+
+type Conf struct {
+	*Server
+	Started time.Time
+	Key     []byte
+}
+`), 0644)
+	src, err := generate(dir, []string{"Conf"}, "", false)
+	assert.NoError(t, err)
+	assert.Contains(t, string(src), "\tco.GeneratedPtr(&c.Server)\n\tfs.IntVarP(&c.Server.Port, \"port\"")
+	assert.Contains(t, string(src), `fs.VarP(co.GeneratedValue(&c.Started, "started", ""), "started", "", "started")`)
+	assert.Contains(t, string(src), `fs.BytesHexVarP(&c.Key, "key"`)
+	assert.Contains(t, string(src), "func (c *Conf) FileKeyAliases() map[string]string {\n\treturn map[string]string{\n\t\t\"listen_port\": \"port\",\n\t}\n}\n")
+}
@@ -0,0 +1,165 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the --completion flag, which prints a shell completion
+script that completes flag names, enum values, the keys of map value types and
+file paths
+*/
+package configurature
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Name of the flag that prints a completion script
+const completionFlag = "completion"
+
+// completer is implemented by Values with a fixed set of valid values
+type completer interface {
+	completions() []string
+}
+
+// flagCompletion is how the value of a flag is completed
+type flagCompletion struct {
+	names []string // --name and -short
+	desc  string   // Usage of the flag
+	value bool     // Whether the flag takes a value
+	words []string // Values to complete, if any
+	file  bool     // Complete file paths
+	dir   bool     // Complete directory paths
+}
+
+// flagCompletions returns how the visible flags of fs are completed
+func flagCompletions(fs *pflag.FlagSet) []flagCompletion {
+	comps := []flagCompletion{}
+	fs.VisitAll(func(fl *pflag.Flag) {
+		if fl.Hidden {
+			return
+		}
+		fc := flagCompletion{
+			names: []string{"--" + fl.Name},
+			desc:  fl.Usage,
+			value: fl.NoOptDefVal == "",
+		}
+		if fl.Shorthand != "" {
+			fc.names = append(fc.names, "-"+fl.Shorthand)
+		}
+		if enums, ok := fl.Annotations[annotationEnum]; ok {
+			fc.words = enums
+		} else if c, ok := unwrapValue(fl.Value).(completer); ok {
+			fc.words = c.completions()
+		}
+		switch strings.TrimPrefix(fl.Value.Type(), "[]") {
+		case "configFile", "existingFile":
+			fc.file = true
+		case "existingDir", "writableDir":
+			fc.dir = true
+		}
+		if _, ok := fl.Annotations[annotationRelPath]; ok {
+			fc.file = true
+		}
+		comps = append(comps, fc)
+	})
+	return comps
+}
+
+// printCompletion prints the completion script of fs for shell, which is
+// bash, zsh or fish
+func (c *configurer) printCompletion(shell string, fs *pflag.FlagSet) {
+	prog := filepath.Base(os.Args[0])
+	comps := flagCompletions(fs)
+	switch shell {
+	case "bash":
+		writeBashCompletion(c.opts.output(), prog, comps)
+	case "zsh":
+		fmt.Fprintf(c.opts.output(), "#compdef %s\n\nautoload -U +X bashcompinit && bashcompinit\n\n", prog)
+		writeBashCompletion(c.opts.output(), prog, comps)
+	case "fish":
+		writeFishCompletion(c.opts.output(), prog, comps)
+	default:
+		panic(fmt.Sprintf("unsupported completion shell: %s", shell))
+	}
+}
+
+// writeBashCompletion writes a bash completion script for prog to w
+func writeBashCompletion(w io.Writer, prog string, comps []flagCompletion) {
+	fn := "_" + regexp.MustCompile(`[^A-Za-z0-9_]`).ReplaceAllString(prog, "_") + "_completions"
+	names := []string{}
+
+	fmt.Fprintf(w, "# bash completion for %s\n", prog)
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintf(w, "\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	fmt.Fprintf(w, "\tcase \"$prev\" in\n")
+	for _, fc := range comps {
+		names = append(names, fc.names...)
+		if !fc.value {
+			continue
+		}
+		fmt.Fprintf(w, "\t%s)\n", strings.Join(fc.names, "|"))
+		switch {
+		case len(fc.words) > 0:
+			fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shellQuote(strings.Join(fc.words, " ")))
+		case fc.dir:
+			fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -d -- \"$cur\"))\n")
+		case fc.file:
+			fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))\n")
+		}
+		fmt.Fprintf(w, "\t\treturn\n\t\t;;\n")
+	}
+	fmt.Fprintf(w, "\tesac\n")
+	slices.Sort(names)
+	fmt.Fprintf(w, "\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shellQuote(strings.Join(names, " ")))
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "complete -o filenames -F %s %s\n", fn, prog)
+}
+
+// writeFishCompletion writes a fish completion script for prog to w
+func writeFishCompletion(w io.Writer, prog string, comps []flagCompletion) {
+	fmt.Fprintf(w, "# fish completion for %s\n", prog)
+	for _, fc := range comps {
+		line := "complete -c " + prog + " -l " + strings.TrimPrefix(fc.names[0], "--")
+		if len(fc.names) > 1 {
+			line += " -s " + strings.TrimPrefix(fc.names[1], "-")
+		}
+		switch {
+		case !fc.value:
+		case len(fc.words) > 0:
+			line += " -x -a " + shellQuote(strings.Join(fc.words, " "))
+		case fc.dir:
+			line += " -x -a '(__fish_complete_directories)'"
+		case fc.file:
+			line += " -r -F"
+		default:
+			line += " -x"
+		}
+		if fc.desc != "" {
+			line += " -d " + shellQuote(fc.desc)
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+// shellQuote single quotes s for bash, zsh and fish
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,74 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type CompletionConf struct {
+	Mode    string         `enum:"fast,safe" short:"m" help:"Run mode"`
+	Level   slog.Level     `default:"info" help:"Log level"`
+	Conf    co.ConfigFile  `help:"Config file"`
+	Data    co.ExistingDir `help:"Data directory"`
+	Host    string         `help:"Server's host"`
+	Verbose bool           `help:"Verbose output"`
+	Inputs  []co.ExistingFile
+}
+
+func TestCompletion_Bash(t *testing.T) {
+	assert := assert.New(t)
+	configure := func(opts *co.Options) { co.Configure[CompletionConf](opts) }
+
+	code, out, _ := configureExit(t, configure, co.Options{Args: []string{"--completion", "bash"}})
+
+	assert.Equal(0, code)
+	assert.Contains(out, "\t--mode|-m)\n\t\tCOMPREPLY=($(compgen -W 'fast safe' -- \"$cur\"))\n")
+	assert.Contains(out, "\t--level)\n\t\tCOMPREPLY=($(compgen -W 'debug error info warn' -- \"$cur\"))\n")
+	assert.Contains(out, "\t--conf)\n\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))\n")
+	assert.Contains(out, "\t--inputs)\n\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))\n")
+	assert.Contains(out, "\t--data)\n\t\tCOMPREPLY=($(compgen -d -- \"$cur\"))\n")
+	assert.Contains(out, "\t--host)\n\t\treturn\n")
+	assert.NotContains(out, "--verbose)")
+	assert.NotContains(out, "--print_changed")
+	assert.Contains(out, "--verbose")
+	assert.True(strings.HasPrefix(out, "# bash completion for "))
+}
+
+func TestCompletion_ZshAndFish(t *testing.T) {
+	assert := assert.New(t)
+	configure := func(opts *co.Options) { co.Configure[CompletionConf](opts) }
+
+	_, out, _ := configureExit(t, configure, co.Options{Args: []string{"--completion", "zsh"}})
+	assert.Contains(out, "autoload -U +X bashcompinit && bashcompinit\n")
+	assert.Contains(out, "--mode|-m)")
+
+	_, out, _ = configureExit(t, configure, co.Options{Args: []string{"--completion", "fish"}})
+	assert.Contains(out, " -l mode -s m -x -a 'fast safe' -d 'Run mode (fast|safe)'\n")
+	assert.Contains(out, " -l conf -r -F -d 'Config file'\n")
+	assert.Contains(out, " -l data -x -a '(__fish_complete_directories)' -d 'Data directory'\n")
+	assert.Contains(out, " -l host -x -d 'Server'\\''s host'\n")
+	assert.Contains(out, " -l verbose -d 'Verbose output'\n")
+
+	code, _, errOut := configureExit(t, configure, co.Options{Args: []string{"--completion", "tcsh"}})
+	assert.Equal(1, code)
+	assert.Contains(errOut, "unsupported completion shell: tcsh")
+}
@@ -0,0 +1,74 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains loading a whole config document from an environment
+variable, for platforms that can set environment variables but can't mount
+files
+*/
+package configurature
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Names of the environment variables holding a config document, without
+// Options.EnvPrefix
+const (
+	configEnvYAML = "CONFIG_YAML"
+	configEnvJSON = "CONFIG_JSON"
+)
+
+// loadConfigEnv adds setters for values found in the config document in the
+// <EnvPrefix>CONFIG_YAML or <EnvPrefix>CONFIG_JSON environment variable to
+// values. The document is handled like a config file whose relative paths
+// are relative to the working directory. It may be base64 encoded with a
+// "base64:" prefix.
+func (c *configurer) loadConfigEnv(fs *pflag.FlagSet, values sourceSetters) {
+	yamlName := c.opts.EnvPrefix + configEnvYAML
+	jsonName := c.opts.EnvPrefix + configEnvJSON
+	yamlDoc, jsonDoc := c.opts.getenv(yamlName), c.opts.getenv(jsonName)
+
+	envName, doc, ext := yamlName, yamlDoc, ".yaml"
+	switch {
+	case yamlDoc != "" && jsonDoc != "":
+		panic(fmt.Sprintf("only one of %s and %s may be set", yamlName, jsonName))
+	case jsonDoc != "":
+		envName, doc, ext = jsonName, jsonDoc, ".json"
+	case yamlDoc == "":
+		return
+	}
+
+	data := []byte(doc)
+	if encoded, ok := strings.CutPrefix(doc, base64Prefix); ok {
+		data = decodeBase64Document(envName, encoded)
+	}
+	gMap, err := parseConfigDocument(data, ext)
+	if err != nil {
+		panic(fmt.Sprintf("error parsing %s: %v", envName, err))
+	}
+
+	// Merge the selected profile over the rest of the document
+	gMap = c.applyProfile(envName, fs, gMap)
+
+	// Upgrade old config file layouts
+	if c.opts.ConfigVersion != "" {
+		gMap = c.migrateConfigFile(envName, gMap)
+	}
+
+	c.setFlagsFromGenericMap(&gMap, []string{}, fs, ".", sourceEnv, values)
+}
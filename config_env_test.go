@@ -0,0 +1,44 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type ConfigEnvConf struct {
+	Conf co.ConfigFile
+	Host string `default:"localhost"`
+	Port int    `default:"80"`
+	DB   struct {
+		User string `default:"postgres"`
+	}
+}
+
+func TestConfigEnv_JSON(t *testing.T) {
+	c := co.Configure[ConfigEnvConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+		EnvPrefix: "BLOB_",
+		ConfigEnv: true,
+		Environ:   map[string]string{"BLOB_CONFIG_JSON": `{"port": 8080, "db": {"user": "admin"}}`},
+	})
+	assert.Equal(t, 8080, c.Port)
+	assert.Equal(t, "admin", c.DB.User)
+}
@@ -0,0 +1,124 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !configurature_core
+
+package configurature_test
+
+import (
+	"encoding/base64"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestConfigEnv(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Setenv("BLOB_CONFIG_YAML", "host: example.com\ndb:\n  user: admin\n")
+	c := co.Configure[ConfigEnvConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+		EnvPrefix: "BLOB_",
+		ConfigEnv: true,
+	})
+	assert.Equal("example.com", c.Host)
+	assert.Equal(80, c.Port)
+	assert.Equal("admin", c.DB.User)
+
+	// Not loaded unless enabled
+	c = co.Configure[ConfigEnvConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+		EnvPrefix: "BLOB_",
+	})
+	assert.Equal("localhost", c.Host)
+}
+
+func TestConfigEnv_Precedence(t *testing.T) {
+	assert := assert.New(t)
+
+	confFile := t.TempDir() + "/conf.yaml"
+	os.WriteFile(confFile, []byte("host: file.example.com\nport: 1\n"), 0644)
+
+	// The document is applied over the config file and individual
+	// environment variables over the document
+	out := &strings.Builder{}
+	assert.Panics(func() {
+		co.Configure[ConfigEnvConf](&co.Options{
+			Args:      []string{"--conf", confFile, "--print_changed"},
+			EnvPrefix: "BLOB_",
+			ConfigEnv: true,
+			Environ: map[string]string{
+				"BLOB_CONFIG_YAML": "host: env.example.com\nport: 2\n",
+				"BLOB_PORT":        "3",
+			},
+			Output: out,
+			Exit:   func(int) {},
+		})
+	})
+	assert.Contains(out.String(), "host=env.example.com (env)\n")
+	assert.Contains(out.String(), "port=3 (env)\n")
+}
+
+func TestConfigEnv_Errors(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.PanicsWithValue("only one of BLOB_CONFIG_YAML and BLOB_CONFIG_JSON may be set", func() {
+		co.Configure[ConfigEnvConf](&co.Options{
+			NoRecover: true,
+			Args:      []string{},
+			EnvPrefix: "BLOB_",
+			ConfigEnv: true,
+			Environ:   map[string]string{"BLOB_CONFIG_YAML": "port: 1", "BLOB_CONFIG_JSON": `{"port": 1}`},
+		})
+	})
+
+	assert.PanicsWithValue("error parsing BLOB_CONFIG_JSON: unexpected end of JSON input", func() {
+		co.Configure[ConfigEnvConf](&co.Options{
+			NoRecover: true,
+			Args:      []string{},
+			EnvPrefix: "BLOB_",
+			ConfigEnv: true,
+			Environ:   map[string]string{"BLOB_CONFIG_JSON": `{"port": 1`},
+		})
+	})
+
+	assert.PanicsWithValue("unknown configuration file field: nope", func() {
+		co.Configure[ConfigEnvConf](&co.Options{
+			NoRecover: true,
+			Args:      []string{},
+			EnvPrefix: "BLOB_",
+			ConfigEnv: true,
+			Environ:   map[string]string{"BLOB_CONFIG_YAML": "nope: 1"},
+		})
+	})
+}
+
+func TestConfigEnv_Base64(t *testing.T) {
+	doc := base64.StdEncoding.EncodeToString([]byte("host: example.com\nport: 8080\n"))
+	c := co.Configure[ConfigEnvConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+		EnvPrefix: "BLOB_",
+		ConfigEnv: true,
+		Environ:   map[string]string{"BLOB_CONFIG_YAML": "base64:" + doc},
+	})
+	assert.Equal(t, "example.com", c.Host)
+	assert.Equal(t, 8080, c.Port)
+}
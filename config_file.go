@@ -19,96 +19,328 @@ package configurature
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	fp "path/filepath"
 	"reflect"
+	"slices"
 	"strings"
+	"time"
 
-	"github.com/iancoleman/strcase"
 	"github.com/spf13/pflag"
-	"gopkg.in/yaml.v3"
 )
 
-// setConfigFile checks for a field of type File in the config struct and sets
-// the configFile.Value pointer to its address
-func (c *configurer) setConfigFile() {
-	c.visitFields(c.config, func(f reflect.StructField, _ *reflect.StructTag, v reflect.Value, _ []string) (stop bool) {
-		if v.Elem().Type() == configFileType {
-			if c.configFile.Value != nil {
-				panic("ConfigFile already set to " + *c.configFile.Value)
+// loadConfigFiles adds setters for values found in the config files given by
+// the ConfigFile fields, in the order they are declared, to values. Values in
+// later files take precedence. Options.ConfigFile is loaded if none of the
+// fields give a file.
+func (c *configurer) loadConfigFiles(fs *pflag.FlagSet, values sourceSetters) {
+	loaded := false
+	for _, flagName := range c.configFileFlags {
+		if fileName := c.configFileName(fs, flagName); fileName != "" {
+			format := c.opts.ConfigFileFormat
+			if f, ok := fs.Lookup(flagName).Annotations[annotationFormat]; ok {
+				format = f[0]
 			}
-			c.configFile.Value = (*string)(v.Interface().(*ConfigFile))
-			stop = true
+			c.loadConfigFile(fileName, flagName, format, fs, values)
+			loaded = true
 		}
-		return stop
-	}, []string{})
-}
+	}
+	if loaded || c.opts.ConfigFile == "" {
+		return
+	}
 
-// Load configuration from config file
-func (c *configurer) loadConfigFile(fs *pflag.FlagSet) {
-	// Set from env since setFromEnv() has not been called yet
-	// (chicken and egg)
-	if envVal := os.Getenv(
-		fmt.Sprintf("%s%s", c.opts.EnvPrefix, strcase.ToScreamingSnake(c.configFile.Flag)),
-	); envVal != "" {
-		*c.configFile.Value = envVal
-	}
-
-	// Set up a flagset that only contains the flags we are looking for to
-	// get the config file. Parse args to get the value.
-	f := pflag.NewFlagSet("cf", pflag.ContinueOnError)
-	f.Usage = func() {}
-	fileName := new(string)
-	f.StringVarP(fileName, c.configFile.Flag, c.configFile.Short, *c.configFile.Value, "")
-	f.Parse(c.opts.Args)
-
-	// No config file specified, nothing to do
-	if *fileName == "" {
+	// Options.ConfigFile is a path or "$" and the name of an environment
+	// variable holding a path
+	if envVar, ok := strings.CutPrefix(c.opts.ConfigFile, "$"); ok {
+		if fileName := c.opts.getenv(envVar); fileName != "" {
+			c.loadConfigFile(fileName, envVar, c.opts.ConfigFileFormat, fs, values)
+		}
 		return
 	}
+	c.loadConfigFile(c.opts.ConfigFile, "ConfigFile", c.opts.ConfigFileFormat, fs, values)
+}
+
+// configFileName returns the config file given by the ConfigFile field with
+// the flag flagName
+func (c *configurer) configFileName(fs *pflag.FlagSet, flagName string) string {
+	// The config file specified on the command line takes precedence over
+	// the environment
+	fl := fs.Lookup(flagName)
+	if !fl.Changed {
+		if envVal := c.opts.getenv(
+			fmt.Sprintf("%s%s", c.opts.EnvPrefix, toScreamingSnake(flagName)),
+		); envVal != "" {
+			return envVal
+		}
+	}
+	return fl.Value.String()
+}
 
-	confFile, err := os.ReadFile(*fileName)
+// loadConfigFile adds setters for values found in the config file to values.
+// name is the flag or environment variable that gave the file. format, if not
+// empty, is used instead of the file's extension. If fileName is a glob
+// pattern rather than an existing file, each matching file is loaded in sorted
+// order.
+func (c *configurer) loadConfigFile(fileName string, name string, format string, fs *pflag.FlagSet, values sourceSetters) {
+	if strings.HasPrefix(fileName, base64Prefix) || !strings.ContainsAny(fileName, "*?[") {
+		c.loadConfigPath(fileName, name, format, fs, values)
+		return
+	}
+	if _, err := os.Stat(fileName); err == nil {
+		// The file's name contains pattern characters
+		c.loadConfigPath(fileName, name, format, fs, values)
+		return
+	}
+	matches, err := fp.Glob(fileName)
 	if err != nil {
-		panic(fmt.Sprintf("error reading config file %s: %v ", *fileName, err))
+		panic(fmt.Sprintf("error matching config files %s: %v", fileName, err))
 	}
+	if len(matches) == 0 {
+		panic(fmt.Sprintf("no config files match %s", fileName))
+	}
+	slices.Sort(matches)
+	for _, m := range matches {
+		c.loadConfigPath(m, name, format, fs, values)
+	}
+}
 
-	// Parse config file based on extension
-	gMap := make(map[string]any)
-	switch fp.Ext(strings.ToLower(*fileName)) {
-	case ".json":
-		err = json.Unmarshal(confFile, &gMap)
-		if err != nil {
-			panic(fmt.Sprintf("error parsing config file: %v", err))
-		}
-	case ".yml", ".yaml":
-		err = yaml.Unmarshal(confFile, gMap)
-		if err != nil {
-			panic(fmt.Sprintf("error parsing config file: %v", err))
-		}
-	default:
-		panic(fmt.Sprintf("unsupported config file type: %s. Supported "+
-			"file types are .json, .yml, .yaml", fp.Base(*fileName)))
+// loadConfigPath adds setters for values found in a single config file to
+// values
+func (c *configurer) loadConfigPath(fileName string, name string, format string, fs *pflag.FlagSet, values sourceSetters) {
+	var gMap map[string]any
+	var files []string // Files read, for CheckFilePerms
+	dir := fp.Dir(fileName)
+	if doc, ok := strings.CutPrefix(fileName, base64Prefix); ok {
+		// The config file was given inline. Its relative paths are relative
+		// to the working directory.
+		if c.opts.ConfigVerifier != nil {
+			panic(fmt.Sprintf("config in %s can't be verified because it was given inline", name))
+		}
+		fileName, dir = name, "."
+		gMap = parseInlineConfigFile(fileName, decodeBase64Document(fileName, doc), format)
+	} else {
+		gMap = c.readConfigFile(fileName, format)
+		files = append(files, fileName)
+
+		// Merge override files that exist over the config file
+		if c.opts.ConfigOverrides {
+			for _, o := range overrideFileNames(fileName, c.profile(fs)) {
+				if _, err := os.Stat(o); err == nil {
+					mergeMaps(gMap, c.readConfigFile(o, format))
+					files = append(files, o)
+				}
+			}
+		}
+	}
+
+	// Merge the selected profile over the rest of the file
+	gMap = c.applyProfile(fileName, fs, gMap)
+
+	// Upgrade old config file layouts
+	if c.opts.ConfigVersion != "" {
+		gMap = c.migrateConfigFile(fileName, gMap)
 	}
 
 	// Set config struct fields based on config values from file stored in
 	// the generic map
-	setFlagsFromGenericMap(&gMap, []string{}, fs)
+	if c.opts.FileDecoder != nil {
+		c.fileValues = append(c.fileValues, gMap)
+	}
+	fileValues := sourceSetters{}
+	c.setFlagsFromGenericMap(&gMap, []string{}, fs, dir, sourceFile, fileValues)
+	maps.Copy(values, fileValues)
 
+	// Check that files holding secret values are private
+	if c.opts.CheckFilePerms != "" {
+		for name := range fileValues {
+			if isSecret(fs.Lookup(name)) {
+				c.checkFilePerms(files)
+				break
+			}
+		}
+	}
+}
+
+// KeyStyle is the casing of config file keys
+type KeyStyle string
+
+// Config file key styles
+const (
+	SnakeKeys KeyStyle = "snake" // listen_port. The default
+	CamelKeys KeyStyle = "camel" // listenPort
+	KebabKeys KeyStyle = "kebab" // listen-port
+)
+
+// configNameFromFileKey converts a config file key in the configured
+// FileKeyStyle to a config name
+func (c *configurer) configNameFromFileKey(key string) string {
+	switch c.opts.FileKeyStyle {
+	case "", SnakeKeys:
+		return key
+	case CamelKeys:
+		return toSnake(key)
+	case KebabKeys:
+		return strings.ReplaceAll(key, "-", "_")
+	}
+	panic(fmt.Sprintf("unsupported config file key style: %s", c.opts.FileKeyStyle))
 }
 
-// setFlagsFromGenericMap sets flag values from a generic map recursively. This
-// is called after reading the config file.
+// fileKeyFromConfigName converts a config name, without its ancestors, to a
+// config file key in the configured FileKeyStyle
+func (c *configurer) fileKeyFromConfigName(name string) string {
+	switch c.opts.FileKeyStyle {
+	case "", SnakeKeys:
+		return name
+	case CamelKeys:
+		return toLowerCamel(name)
+	case KebabKeys:
+		return strings.ReplaceAll(name, "_", "-")
+	}
+	panic(fmt.Sprintf("unsupported config file key style: %s", c.opts.FileKeyStyle))
+}
+
+// readConfigFile reads, verifies and parses a config file based on its
+// extension
+func (c *configurer) readConfigFile(fileName string, format string) map[string]any {
+	confFile, err := os.ReadFile(fileName)
+	if err != nil {
+		panic(fmt.Sprintf("error reading config file %s: %v ", fileName, err))
+	}
+	if c.opts.ConfigVerifier != nil {
+		if err := c.opts.ConfigVerifier.Verify(fileName, confFile); err != nil {
+			panic(fmt.Sprintf("error verifying config file %s: %v", fileName, err))
+		}
+	}
+
+	ext := fp.Ext(strings.ToLower(fileName))
+	if format != "" {
+		ext = formatExt(format)
+	}
+	if ext != ".json" && ext != ".yml" && ext != ".yaml" {
+		panic(fmt.Sprintf("unsupported config file type: %s. Supported "+
+			"file types are .json, .yml, .yaml", fp.Base(fileName)))
+	}
+	gMap, err := parseConfigDocument(confFile, ext)
+	if err != nil {
+		panic(fmt.Sprintf("error parsing config file: %v", err))
+	}
+	return gMap
+}
+
+// Prefix of config documents given base64 encoded instead of as a file name
+// or as is
+const base64Prefix = "base64:"
+
+// decodeBase64Document decodes a base64 encoded config document. Whitespace,
+// such as line breaks added by the platform that set it, is ignored. name is
+// used in errors.
+func decodeBase64Document(name, doc string) []byte {
+	doc = strings.Join(strings.Fields(doc), "")
+	data, err := base64.StdEncoding.DecodeString(doc)
+	if err != nil {
+		data, err = base64.RawStdEncoding.DecodeString(doc)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("error decoding base64 config in %s: %v", name, err))
+	}
+	return data
+}
+
+// parseInlineConfigFile parses a config file given inline as JSON if it is
+// an object or as YAML otherwise. name is used in errors.
+func parseInlineConfigFile(name string, data []byte, format string) map[string]any {
+	ext := ".yaml"
+	if format != "" {
+		ext = formatExt(format)
+	} else if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		ext = ".json"
+	}
+	gMap, err := parseConfigDocument(data, ext)
+	if err != nil {
+		panic(fmt.Sprintf("error parsing config in %s: %v", name, err))
+	}
+	return gMap
+}
+
+// formatExt returns the file extension of a config file format given by the
+// format tag or Options.ConfigFileFormat
+func formatExt(format string) string {
+	switch strings.ToLower(format) {
+	case "yaml", "yml":
+		return ".yaml"
+	case "json":
+		return ".json"
+	}
+	panic(fmt.Sprintf("unsupported config file format: %s. Supported formats are yaml, json", format))
+}
+
+// parseConfigDocument parses the contents of a config file with the given
+// extension: .json, .yml or .yaml
+func parseConfigDocument(data []byte, ext string) (map[string]any, error) {
+	gMap := make(map[string]any)
+	var err error
+	if ext == ".json" {
+		err = json.Unmarshal(data, &gMap)
+	} else {
+		err = unmarshalYAML(data, gMap)
+	}
+	return gMap, err
+}
+
+// overrideFileNames returns the names of the files that override a config
+// file in the order they are applied: config.<profile>.yaml, then
+// config.local.yaml for a config file named config.yaml
+func overrideFileNames(fileName string, profile string) []string {
+	ext := fp.Ext(fileName)
+	base := strings.TrimSuffix(fileName, ext)
+
+	names := []string{}
+	if profile != "" {
+		names = append(names, base+"."+profile+ext)
+	}
+	return append(names, base+".local"+ext)
+}
+
+// Prefix of config file keys that are ignored. They can hold YAML anchors
+// shared by other keys.
+const configFileExtensionPrefix = "x-"
+
+// setFlagsFromGenericMap adds setters for flag values from a generic map
+// recursively. This is called after reading the config file. YAML anchors,
+// aliases and merge keys have already been resolved by the YAML decoder.
 //
 // Parameters:
 // - gMap: a pointer to a map[string]any
 // - path: a slice of strings representing the path
 // - fs: a pointer to a pflag.FlagSet
-func setFlagsFromGenericMap(gMap *map[string]any, ancestors []string, fs *pflag.FlagSet) {
+// - dir: the config file's directory, which relpath fields are relative to
+// - source: the source of the values
+// - values: the setters to add to
+func (c *configurer) setFlagsFromGenericMap(gMap *map[string]any, ancestors []string, fs *pflag.FlagSet, dir string, source string, values sourceSetters) {
 	for k, v := range *gMap {
 
+		// Skip extension keys
+		if strings.HasPrefix(k, configFileExtensionPrefix) {
+			continue
+		}
+
+		// Use the config name of keys given by yaml or json tags
+		if name, ok := c.fileKeyAliases[strings.Join(append(ancestors, k), "_")]; ok {
+			k = name
+		} else {
+			k = c.configNameFromFileKey(k)
+		}
+
+		// Yaml unmarshals timestamps into time.Time
+		if tm, ok := v.(time.Time); ok {
+			v = formatTime(tm)
+		}
+
 		// Yaml unmarshals into a map[any]any for
 		// sub-objects. Convert them to a map[string]any
 		if ifaceIfaceMap, ok := v.(map[any]any); ok {
@@ -132,7 +364,7 @@ func setFlagsFromGenericMap(gMap *map[string]any, ancestors []string, fs *pflag.
 				v = strings.Join(vstr, ",")
 			} else {
 				// It's nested config
-				setFlagsFromGenericMap(&nested, append(ancestors, k), fs)
+				c.setFlagsFromGenericMap(&nested, append(ancestors, k), fs, dir, source, values)
 				continue
 			}
 		}
@@ -141,27 +373,44 @@ func setFlagsFromGenericMap(gMap *map[string]any, ancestors []string, fs *pflag.
 		k = strings.Join(append(ancestors, k), "_")
 
 		// Make sure flag exists
-		if flg := fs.Lookup(k); flg == nil {
-			panic(fmt.Sprintf("unknown configuration file field: %s", k))
+		flg := fs.Lookup(k)
+		if flg == nil {
+			c.errors = append(c.errors, fmt.Sprintf("unknown configuration file field: %s", k))
+			continue
 		}
+		_, relPath := flg.Annotations[annotationRelPath]
 
 		// Reformat slice/array values so that pflag Values can parse them
 		// If the value is a slice, join the values
 		if reflect.ValueOf(v).Kind() == reflect.Slice {
 
-			writeCsv := false
 			vals := make([]string, len(v.([]any)))
 
-			// Populate vals and check if we need to write csv
+			// Populate vals
 			for idx, val := range v.([]any) {
+				if tm, ok := val.(time.Time); ok {
+					val = formatTime(tm)
+				}
 				vals[idx] = fmt.Sprintf("%v", val)
+			}
+			vals = sliceElements(flg, c.opts, vals)
+
+			// Check if we need to write csv
+			writeCsv := false
+			for idx := range vals {
+				if relPath {
+					vals[idx] = resolvePath(dir, vals[idx])
+				}
 				if strings.Contains(vals[idx], `"`) || strings.Contains(vals[idx], `,`) {
 					writeCsv = true
 				}
 			}
 
-			// csv write for string type slices
-			if writeCsv {
+			// Join elements with the delim of fields that have one
+			if d, ok := flg.Value.(*delimitedValue); ok {
+				v = strings.Join(vals, d.delim)
+			} else if writeCsv {
+				// csv write for string type slices
 				b := &bytes.Buffer{}
 				w := csv.NewWriter(b)
 				w.Write(vals)
@@ -171,11 +420,83 @@ func setFlagsFromGenericMap(gMap *map[string]any, ancestors []string, fs *pflag.
 			} else {
 				v = strings.Join(vals, ",")
 			}
+		} else if relPath {
+			v = resolvePath(dir, fmt.Sprintf("%v", v))
+		} else if isSliceValue(flg.Value) {
+			v = sliceValue(flg, c.opts, fmt.Sprintf("%v", v))
 		}
 
 		// Set the value
-		if err := setFlagValue(k, fmt.Sprintf("%v", v), fs); err != nil {
-			panic(fmt.Sprintf("unable to set value for %s: %v", k, err))
+		val := fmt.Sprintf("%v", v)
+		if source == sourceFile && c.opts.FileDecoder != nil {
+			// The value is decoded into the struct by decodeConfigFiles()
+			values[k] = sourceSetter{source, func() { flg.Changed = true }}
+			continue
+		}
+		values[k] = sourceSetter{source, func() {
+			if err := setFlagValue(k, val, fs); err != nil {
+				c.errors = append(c.errors, maskSecret(flg, fmt.Sprintf("unable to set value for %s: %v", k, err), val))
+			}
+		}}
+	}
+}
+
+// fileKeyAliases returns the config file keys given by the yaml and json
+// tags of the fields of struct type t, and of the config structs nested in it,
+// mapped to the field's config name. Keys are prefixed by the config names of
+// their ancestors so that they are unique.
+func fileKeyAliases(types *Types, tagNames map[string]string, t reflect.Type, ancestors []string) map[string]string {
+	aliases := map[string]string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := configField(t, t.Field(i), tagNames)
+		if !f.IsExported() {
+			continue
+		}
+		tags := f.Tag
+		if _, ok := tags.Lookup("ignore"); ok {
+			continue
 		}
+
+		// Fields of anonymous structs have the same ancestors
+		if f.Anonymous {
+			maps.Copy(aliases, fileKeyAliases(types, tagNames, f.Type, ancestors))
+			continue
+		}
+
+		// Config name of this field without its ancestors. This mirrors
+		// collectStructFields() for nested config structs.
+		name := fieldNameToConfigName(f.Name, &tags, []string{})
+		if isSubConfig(types, f.Type) {
+			name = f.Name
+			if nm, ok := tags.Lookup("name"); ok {
+				name = nm
+			}
+			name = toSnake(name)
+		}
+
+		for _, tagName := range []string{"yaml", "json"} {
+			key, _, _ := strings.Cut(tags.Get(tagName), ",")
+			if key != "" && key != "-" && key != name {
+				aliases[strings.Join(append(ancestors, key), "_")] = name
+			}
+		}
+
+		if isSubConfig(types, f.Type) {
+			newAncestors := ancestors
+			if name != "" {
+				newAncestors = slices.Concat(ancestors, []string{name})
+			}
+			maps.Copy(aliases, fileKeyAliases(types, tagNames, subConfigType(f.Type), newAncestors))
+		}
+	}
+	return aliases
+}
+
+// resolvePath returns path joined to dir if it is a relative path
+func resolvePath(dir string, path string) string {
+	if path == "" || fp.IsAbs(path) {
+		return path
 	}
+	return fp.Join(dir, path)
 }
@@ -0,0 +1,571 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !configurature_core
+
+package configurature_test
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestConfigFile(t *testing.T) {
+	assert := assert.New(t)
+
+	tmp, _ := os.CreateTemp("", "cfgr-test-*.yml")
+	defer os.Remove(tmp.Name())
+	tmp.Write([]byte("foo_int: 4\nsub_foo_string: 'yes'\nkeepalive_timeout: 3m\nbool: true\n"))
+	tmp.Close()
+
+	c := co.Configure[TestConfigFileStruct](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--cool_file", tmp.Name()},
+	})
+	assert.Equal(uint32(4), c.FooInt)
+	assert.Equal("yes", c.SubFooString)
+	assert.Equal(time.Duration(3)*time.Minute, c.KeepaliveTimeout)
+	assert.Equal(true, c.Bool)
+}
+
+func TestConfigFile_BadField(t *testing.T) {
+	if os.Getenv("TEST_PASSTHROUGH") == "1" {
+		tmp, _ := os.CreateTemp("", "cfgr-test-*.yml")
+		defer os.Remove(tmp.Name())
+		tmp.Write([]byte("foo_int: 4\nsub_string: 'yes'\nkeepalive_timeout: 3m\nbool: true\n"))
+		tmp.Close()
+
+		co.Configure[TestConfigFileStruct](&co.Options{
+			Args: []string{"--cool_file", tmp.Name()},
+		})
+		os.Exit(0)
+	}
+	assert := assert.New(t)
+	stdout, stderr := runExternal(t)
+
+	assert.Equal("", stdout)
+	assert.Equal("error parsing configuration: unknown configuration file field: sub_string\n", stderr)
+
+}
+
+func TestConfigFile_BadValue(t *testing.T) {
+	if os.Getenv("TEST_PASSTHROUGH") == "1" {
+		tmp, _ := os.CreateTemp("", "cfgr-test-*.yml")
+		defer os.Remove(tmp.Name())
+		tmp.Write([]byte("foo_int: asdf\nsub_foo_string: 'ok'\nkeepalive_timeout: 3m\nbool: true\n"))
+		tmp.Close()
+
+		co.Configure[TestConfigFileStruct](&co.Options{
+			Args: []string{"--cool_file", tmp.Name()},
+		})
+		os.Exit(0)
+	}
+	assert := assert.New(t)
+	stdout, stderr := runExternal(t)
+
+	assert.Equal("", stdout)
+	assert.Equal("error parsing configuration: unable to set value for foo_int: "+
+		"strconv.ParseUint: parsing \"asdf\": invalid syntax\n", stderr)
+
+}
+
+func TestNested_Yaml(t *testing.T) {
+	assert := assert.New(t)
+
+	tmp, _ := os.CreateTemp("", "cfgr-test-*.yml")
+	defer os.Remove(tmp.Name())
+	tmp.Write([]byte(`s_slice: [af, 'as"oidjf', 3]
+my_map:
+  foo: bar
+  a: b
+name_age_map:
+  joshua: 33
+  josh: 24
+  dave: 35
+sub:
+  default_lock_timeout: 30s
+  foo_seconds: 20
+  foo_int: 2
+  foo_ints: 
+    - 2
+    - 4
+    - 5
+os:
+  sub_foo_string: "there and everywhere"
+`))
+	tmp.Close()
+
+	c := co.Configure[TestNestedConfig](&co.Options{
+		Args:      []string{"--cool_file", tmp.Name()},
+		NoRecover: true,
+	})
+
+	assert.Equal(map[string]int{"joshua": 33, "josh": 24, "dave": 35}, c.NameAgeMap, "NameAgeMap should be joshua[33] josh[24] dave[35]")
+	assert.Equal(map[string]string{"foo": "bar", "a": "b"}, c.MyMap, "MyMap should be foo[bar] a[b]")
+	assert.Equal([]string{"af", "as\"oidjf", "3"}, c.SSlice, "SSlie should be af,as\"oidjf,3")
+	assert.Equal(time.Duration(30)*time.Second, c.Sub.DefaultLockTimeout, "DefaultLockTimeout should be 30s")
+	assert.Equal(uint(20), c.Sub.FooSeconds, "FooSeconds should be 20")
+	assert.Equal(uint32(2), c.Sub.FooInt, "FooInt should be 2")
+	assert.Equal([]uint{2, 4, 5}, c.Sub.FooInts, "FooInts should be [2 4 5]")
+	assert.Equal("there and everywhere", c.OS.SubFooString, "SubFooString should be there and everywhere")
+}
+
+func TestConfigFile_MergeSources(t *testing.T) {
+	assert := assert.New(t)
+
+	tmp, _ := os.CreateTemp("", "cfgr-test-*.yml")
+	defer os.Remove(tmp.Name())
+	tmp.Write([]byte("s_slice: [d, e]\nsub:\n  foo_ints: [1, 2]\n  foo_int: 9\n"))
+	tmp.Close()
+
+	os.Setenv("MERGE_SUB_FOO_INTS", "3,4")
+	defer os.Unsetenv("MERGE_SUB_FOO_INTS")
+
+	c := co.Configure[TestNestedConfig](&co.Options{
+		NoRecover: true,
+		EnvPrefix: "MERGE_",
+		Args:      []string{"--sub_foo_int", "7", "--cool_file", tmp.Name()},
+	})
+
+	assert.Equal([]string{"d", "e"}, c.SSlice)
+	assert.Equal([]uint{3, 4}, c.Sub.FooInts)
+	assert.Equal(uint32(7), c.Sub.FooInt)
+}
+
+func TestConfigFile_RelPath(t *testing.T) {
+	type Conf struct {
+		Conf    co.ConfigFile
+		Cert    string   `relpath:""`
+		Key     string   `relpath:""`
+		Plugins []string `relpath:""`
+		Other   string
+	}
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	confFile := dir + "/conf.yaml"
+	os.WriteFile(confFile, []byte("cert: certs/cert.pem\nkey: /etc/key.pem\n"+
+		"plugins: [a.so, ../b.so]\nother: rel/path\n"), 0644)
+
+	c := co.Configure[Conf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--conf", confFile},
+	})
+	assert.Equal(dir+"/certs/cert.pem", c.Cert)
+	assert.Equal("/etc/key.pem", c.Key)
+	assert.Equal([]string{dir + "/a.so", filepath.Dir(dir) + "/b.so"}, c.Plugins)
+	assert.Equal("rel/path", c.Other)
+
+	// Relative paths specified on the command line are unchanged
+	c = co.Configure[Conf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--conf", confFile, "--cert", "cert.pem"},
+	})
+	assert.Equal("cert.pem", c.Cert)
+}
+
+func TestConfigFile_YamlAnchors(t *testing.T) {
+	assert := assert.New(t)
+
+	tmp, _ := os.CreateTemp("", "cfgr-test-*.yml")
+	defer os.Remove(tmp.Name())
+	tmp.Write([]byte(`x-sub-defaults: &sub_defaults
+  default_lock_timeout: 30s
+  foo_seconds: 20
+x-names: &names
+  joshua: 33
+  josh: 24
+x-host: &host db.local
+s_slice: [a, b]
+my_map:
+  host: *host
+name_age_map:
+  <<: *names
+  dave: 35
+  josh: 25
+sub:
+  <<: *sub_defaults
+  foo_seconds: 10
+  foo_ints: [2, 4]
+os:
+  sub_foo_string: shared
+`))
+	tmp.Close()
+
+	c := co.Configure[TestNestedConfig](&co.Options{
+		Args:      []string{"--cool_file", tmp.Name()},
+		NoRecover: true,
+	})
+
+	assert.Equal([]string{"a", "b"}, c.SSlice)
+	assert.Equal(map[string]string{"host": "db.local"}, c.MyMap)
+	assert.Equal(map[string]int{"joshua": 33, "josh": 25, "dave": 35}, c.NameAgeMap)
+	assert.Equal(time.Duration(30)*time.Second, c.Sub.DefaultLockTimeout)
+	assert.Equal(uint(10), c.Sub.FooSeconds)
+	assert.Equal([]uint{2, 4}, c.Sub.FooInts)
+	assert.Equal("shared", c.OS.SubFooString)
+}
+
+func TestConfigFile_StructTagKeys(t *testing.T) {
+	type DB struct {
+		Host string `yaml:"hostname" json:"hostname"`
+		Port int    `json:"port,omitempty"`
+	}
+	type Conf struct {
+		Conf       co.ConfigFile `yaml:"-"`
+		ListenAddr string        `yaml:"listenAddr" json:"listenAddr"`
+		LogLevel   string        `yaml:"log_level"`
+		Renamed    string        `name:"other" json:"renamed"`
+		DB         DB            `yaml:"database" json:"database"`
+	}
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	yamlFile := dir + "/conf.yaml"
+	os.WriteFile(yamlFile, []byte("listenAddr: :80\nlog_level: debug\n"+
+		"database:\n  hostname: db.local\n  port: 5432\n"), 0644)
+
+	c := co.Configure[Conf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--conf", yamlFile},
+	})
+	assert.Equal(":80", c.ListenAddr)
+	assert.Equal("debug", c.LogLevel)
+	assert.Equal("db.local", c.DB.Host)
+	assert.Equal(5432, c.DB.Port)
+
+	// Config names are still accepted, and can be mixed with tag keys
+	jsonFile := dir + "/conf.json"
+	os.WriteFile(jsonFile, []byte(`{"listen_addr": ":81", "renamed": "r",
+		"db": {"hostname": "db2.local"}}`), 0644)
+
+	c = co.Configure[Conf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--conf", jsonFile},
+	})
+	assert.Equal(":81", c.ListenAddr)
+	assert.Equal("r", c.Renamed)
+	assert.Equal("db2.local", c.DB.Host)
+}
+
+func TestConfigFile_FileKeyStyle(t *testing.T) {
+	assert := assert.New(t)
+
+	tmp, _ := os.CreateTemp("", "cfgr-test-*.yml")
+	defer os.Remove(tmp.Name())
+	tmp.Write([]byte("fooInt: 4\nsubFooString: 'yes'\nkeepaliveTimeout: 3m\n"))
+	tmp.Close()
+
+	c := co.Configure[TestConfigFileStruct](&co.Options{
+		NoRecover:    true,
+		Args:         []string{"--cool_file", tmp.Name()},
+		FileKeyStyle: co.CamelKeys,
+	})
+	assert.Equal(uint32(4), c.FooInt)
+	assert.Equal("yes", c.SubFooString)
+	assert.Equal(time.Duration(3)*time.Minute, c.KeepaliveTimeout)
+
+	assert.PanicsWithValue("unsupported config file key style: upper", func() {
+		co.Configure[TestConfigFileStruct](&co.Options{
+			NoRecover:    true,
+			Args:         []string{"--cool_file", tmp.Name()},
+			FileKeyStyle: "upper",
+		})
+	})
+}
+
+func TestConfigFile_Overrides(t *testing.T) {
+	type Conf struct {
+		Conf  co.ConfigFile
+		Level string `default:"info"`
+		Port  int
+		Names []string
+		Sub   struct {
+			Host string
+			User string
+		}
+	}
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	os.WriteFile(dir+"/config.yaml", []byte("level: warn\nport: 80\nnames: [a, b]\n"+
+		"sub:\n  host: h\n  user: u\n"), 0644)
+	os.WriteFile(dir+"/config.prod.yaml", []byte("port: 443\nsub:\n  host: prod\n"), 0644)
+	os.WriteFile(dir+"/config.local.yaml", []byte("names: [c]\nport: 8080\n"), 0644)
+
+	// Overrides are not loaded by default
+	c := co.Configure[Conf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--conf", dir + "/config.yaml"},
+	})
+	assert.Equal(80, c.Port)
+
+	c = co.Configure[Conf](&co.Options{
+		NoRecover:       true,
+		Args:            []string{"--conf", dir + "/config.yaml"},
+		ConfigOverrides: true,
+	})
+	assert.Equal("warn", c.Level)
+	assert.Equal(8080, c.Port)
+	assert.Equal([]string{"c"}, c.Names)
+	assert.Equal("h", c.Sub.Host)
+
+	c = co.Configure[Conf](&co.Options{
+		NoRecover:       true,
+		Args:            []string{"--conf", dir + "/config.yaml"},
+		ConfigOverrides: true,
+		Profile:         "prod",
+	})
+	assert.Equal(8080, c.Port)
+	assert.Equal("prod", c.Sub.Host)
+	assert.Equal("u", c.Sub.User)
+
+	// Missing override files are skipped
+	os.Remove(dir + "/config.local.yaml")
+	c = co.Configure[Conf](&co.Options{
+		NoRecover:       true,
+		Args:            []string{"--conf", dir + "/config.yaml"},
+		ConfigOverrides: true,
+		Profile:         "staging",
+	})
+	assert.Equal(80, c.Port)
+}
+
+func TestConfigFile_Base64(t *testing.T) {
+	assert := assert.New(t)
+
+	yamlDoc := base64.StdEncoding.EncodeToString([]byte("foo_int: 4\nsub_foo_string: 'yes'\n"))
+	c := co.Configure[TestConfigFileStruct](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--cool_file", "base64:" + yamlDoc},
+	})
+	assert.Equal(uint32(4), c.FooInt)
+	assert.Equal("yes", c.SubFooString)
+
+	// JSON and line breaks added by the environment
+	jsonDoc := base64.StdEncoding.EncodeToString([]byte(`{"foo_int": 5}`))
+	t.Setenv("B64_COOL_FILE", "base64:"+jsonDoc[:6]+"\n"+jsonDoc[6:])
+	c = co.Configure[TestConfigFileStruct](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+		EnvPrefix: "B64_",
+	})
+	assert.Equal(uint32(5), c.FooInt)
+
+	assert.PanicsWithValue("error decoding base64 config in cool_file: illegal base64 data at input byte 3", func() {
+		co.Configure[TestConfigFileStruct](&co.Options{
+			NoRecover: true,
+			Args:      []string{"--cool_file", "base64:foo!"},
+		})
+	})
+}
+
+func TestConfigFile_Options(t *testing.T) {
+	type Conf struct {
+		Host string `default:"localhost"`
+		Port int    `default:"80"`
+	}
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	confFile := filepath.Join(dir, "conf.yaml")
+	os.WriteFile(confFile, []byte("host: example.com\nport: 8080\n"), 0600)
+
+	c := co.Configure[Conf](&co.Options{
+		NoRecover:  true,
+		Args:       []string{"--port", "9090"},
+		ConfigFile: confFile,
+	})
+	assert.Equal("example.com", c.Host)
+	assert.Equal(9090, c.Port)
+
+	// The path is read from an environment variable
+	c = co.Configure[Conf](&co.Options{
+		NoRecover:  true,
+		Args:       []string{},
+		ConfigFile: "$APP_CONFIG",
+		Environ:    map[string]string{"APP_CONFIG": confFile},
+	})
+	assert.Equal("example.com", c.Host)
+
+	c = co.Configure[Conf](&co.Options{
+		NoRecover:  true,
+		Args:       []string{},
+		ConfigFile: "$APP_CONFIG",
+		Environ:    map[string]string{},
+	})
+	assert.Equal("localhost", c.Host)
+
+	// A ConfigFile field takes precedence
+	otherFile := filepath.Join(dir, "other.yaml")
+	os.WriteFile(otherFile, []byte("foo_int: 4\n"), 0600)
+	fc := co.Configure[TestConfigFileStruct](&co.Options{
+		NoRecover:  true,
+		Args:       []string{"--cool_file", otherFile},
+		ConfigFile: confFile,
+	})
+	assert.Equal(uint32(4), fc.FooInt)
+
+	assert.PanicsWithValue("error decoding base64 config in APP_CONFIG: illegal base64 data at input byte 3", func() {
+		co.Configure[Conf](&co.Options{
+			NoRecover:  true,
+			Args:       []string{},
+			ConfigFile: "$APP_CONFIG",
+			Environ:    map[string]string{"APP_CONFIG": "base64:foo!"},
+		})
+	})
+}
+
+func TestConfigFile_Multiple(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	secretsFile := filepath.Join(dir, "secrets.yaml")
+	os.WriteFile(secretsFile, []byte("host: secrets\npassword: hunter2\n"), 0600)
+	mainFile := filepath.Join(dir, "main.yaml")
+	os.WriteFile(mainFile, []byte("host: main\n"), 0600)
+
+	args := []string{"--main_file", mainFile, "--secrets_file", secretsFile}
+
+	// Files are loaded in declaration order, not the order of the flags
+	c := co.Configure[MultiConfigFileConf](&co.Options{
+		NoRecover: true,
+		Args:      args,
+	})
+	assert.Equal("main", c.Host)
+	assert.Equal("hunter2", c.Password)
+
+	c = co.ConfigureGenerated[MultiConfigFileConf](&co.Options{
+		NoRecover: true,
+		Args:      args,
+	})
+	assert.Equal("main", c.Host)
+	assert.Equal("hunter2", c.Password)
+
+	// Files that aren't given are skipped
+	c = co.Configure[MultiConfigFileConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+		EnvPrefix: "MULTI_",
+		Environ:   map[string]string{"MULTI_SECRETS_FILE": secretsFile},
+	})
+	assert.Equal("secrets", c.Host)
+}
+
+func TestConfigFile_Glob(t *testing.T) {
+	type Conf struct {
+		Conf co.ConfigFile
+		Host string
+		Port int
+		Cert string `relpath:""`
+	}
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "conf.d"), 0700)
+	os.WriteFile(filepath.Join(dir, "conf.d", "10-base.yaml"), []byte("host: base\nport: 80\ncert: base.pem\n"), 0600)
+	os.WriteFile(filepath.Join(dir, "conf.d", "20-host.yaml"), []byte("host: example.com\n"), 0600)
+	os.WriteFile(filepath.Join(dir, "conf.d", "README"), []byte("not yaml: ["), 0600)
+
+	// Matches are loaded in sorted order
+	c := co.Configure[Conf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--conf", filepath.Join(dir, "conf.d", "*.yaml")},
+	})
+	assert.Equal("example.com", c.Host)
+	assert.Equal(80, c.Port)
+	assert.Equal(filepath.Join(dir, "conf.d", "base.pem"), c.Cert)
+
+	// No matches
+	noMatch := filepath.Join(dir, "*.json")
+	assert.PanicsWithValue("no config files match "+noMatch, func() {
+		co.Configure[Conf](&co.Options{
+			NoRecover: true,
+			Args:      []string{"--conf", noMatch},
+		})
+	})
+
+	// Existing files are loaded even if their names look like patterns
+	literal := filepath.Join(dir, "conf[1].yaml")
+	os.WriteFile(literal, []byte("host: literal\n"), 0600)
+	c = co.Configure[Conf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--conf", literal},
+	})
+	assert.Equal("literal", c.Host)
+
+	assert.PanicsWithValue("error matching config files [: syntax error in pattern", func() {
+		co.Configure[Conf](&co.Options{
+			NoRecover: true,
+			Args:      []string{"--conf", "["},
+		})
+	})
+}
+
+func TestConfigFile_Format(t *testing.T) {
+	type Conf struct {
+		Conf co.ConfigFile `format:"json"`
+		Host string
+	}
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	jsonFile := filepath.Join(dir, "conf")
+	os.WriteFile(jsonFile, []byte(`{"host": "example.com"}`), 0600)
+	yamlFile := filepath.Join(dir, "conf.tmp")
+	os.WriteFile(yamlFile, []byte("host: example.org\n"), 0600)
+
+	// The format tag
+	c := co.Configure[Conf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--conf", jsonFile},
+	})
+	assert.Equal("example.com", c.Host)
+
+	// Options.ConfigFileFormat
+	type HostConf struct {
+		Host string
+	}
+	c3 := co.Configure[HostConf](&co.Options{
+		NoRecover:        true,
+		Args:             []string{},
+		ConfigFile:       yamlFile,
+		ConfigFileFormat: "yaml",
+	})
+	assert.Equal("example.org", c3.Host)
+
+	// The tag takes precedence over the option
+	c = co.Configure[Conf](&co.Options{
+		NoRecover:        true,
+		Args:             []string{"--conf", jsonFile},
+		ConfigFileFormat: "yaml",
+	})
+	assert.Equal("example.com", c.Host)
+
+	assert.PanicsWithValue("unsupported config file format: toml. Supported formats are yaml, json", func() {
+		co.Configure[HostConf](&co.Options{
+			NoRecover:        true,
+			Args:             []string{},
+			ConfigFile:       yamlFile,
+			ConfigFileFormat: "toml",
+		})
+	})
+}
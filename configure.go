@@ -18,164 +18,689 @@ This file provides the Configure function and its helpers
 package configurature
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"reflect"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/iancoleman/strcase"
 	"github.com/spf13/pflag"
 )
 
 // Used for config file special casing in code
 var configFileType = reflect.TypeFor[ConfigFile]()
 
+var (
+	// Cache of config fields found in struct types without a custom type
+	// registry. Used by visitFields()
+	structFieldsCache fieldsCache
+
+	// Incremented when type registrations change, which invalidates all
+	// cached fields, including those cached by each Types
+	structFieldsGen atomic.Uint64
+)
+
+// structFieldsKey is the key of the config fields of a struct type found
+// using tag names
+type structFieldsKey struct {
+	tagNames string // TagNames formatted with fmt, which sorts map keys
+	t        reflect.Type
+}
+
+// fieldsCache is a cache of the config fields of struct types. Each Types
+// has its own, so its entries are discarded along with it.
+type fieldsCache struct {
+	mu     sync.Mutex
+	gen    uint64 // Value of structFieldsGen the fields were collected at
+	fields map[structFieldsKey][]structField
+}
+
+// get returns the cached fields for key if they were collected at generation
+// gen
+func (fc *fieldsCache) get(key structFieldsKey, gen uint64) ([]structField, bool) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	if fc.gen != gen {
+		return nil, false
+	}
+	fields, ok := fc.fields[key]
+	return fields, ok
+}
+
+// put caches fields for key, which were collected at generation gen. Fields
+// of older generations are dropped.
+func (fc *fieldsCache) put(key structFieldsKey, gen uint64, fields []structField) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	if gen < fc.gen {
+		return
+	}
+	if gen > fc.gen || fc.fields == nil {
+		fc.gen = gen
+		fc.fields = make(map[structFieldsKey][]structField)
+	}
+	fc.fields[key] = fields
+}
+
+// structField is a config field found in a struct type
+type structField struct {
+	field     reflect.StructField                                  // The field
+	index     []int                                                // Index sequence of the field for FieldByIndex
+	ancestors []string                                             // Names of the sub-configs containing the field
+	name      string                                               // Config name of the field; see fieldNameToConfigName()
+	flagFn    func(string, string, string, string, *pflag.FlagSet) // Registered function adding the field's flag. nil for pflag types
+}
+
+// clearStructFieldsCache clears the cached config fields, e.g. when a type
+// registration changes which fields are sub-configs
+func clearStructFieldsCache() {
+	structFieldsGen.Add(1)
+}
+
 // configurer is used to populate a config struct
 type configurer struct {
-	config     any
-	opts       *Options
-	configFile struct {
-		Flag  string
-		Short string
-		Value *string
-	}
+	ctx             context.Context // Bounds loading configuration from sources
+	config          any
+	opts            *Options
+	configFileFlags []string          // Names of the ConfigFile fields' flags in declaration order
+	sources         map[string]string // Source of each flag's value that was set
+	fileKeyAliases  map[string]string // Config file keys from yaml and json tags; see fileKeyAliases()
+	generated       bool              // Whether the flags were added by code generated by configurature-gen
+	argErrors       []string          // Invalid flag values given on the command line; see checkErrors()
+	errors          []string          // Errors setting values from other sources; see checkErrors()
+	defaultFiles    map[string]string // Contents of the files named by defaultFile tags; see withDefaultFile()
+	fileValues      []map[string]any  // Values of the config files read, for FileDecoder
+}
+
+// Configuration value sources
+const (
+	sourceFlag    = "flag"
+	sourceEnv     = "env"
+	sourceFile    = "file"
+	sourceKeyring = "keyring"
+)
+
+// Separates the environment variable of a map field from a key. E.g.
+// APP_LABELS__TEAM
+const envMapKeyDelimiter = "__"
+
+// sourceSetter sets a flag's value from a configuration source (config file,
+// Source, environment or keyring)
+type sourceSetter struct {
+	source string // Source of the value
+	set    func() // Sets the flag's value
 }
 
+// sourceSetters maps flag names to their sourceSetter
+type sourceSetters map[string]sourceSetter
+
 // Configure options
 type Options struct {
-	EnvPrefix         string               // Prefix for environment variables
-	Args              []string             // Arguments to parse
-	NilPtrs           bool                 // Leave pointers set to nil if values aren't specified
-	Usage             func(*pflag.FlagSet) // Usage function called when configuration is incorrect or for --help
-	NoRecover         bool                 // Don't recover from panic
-	ShowInternalFlags bool                 // Show hidden internal flags
-	NoShortHelp       bool                 // Don't add "h" as a short help flag
-	RequireNoDefaults bool                 // Require any fields that don't have a default value
+	EnvPrefix          string                    // Prefix for environment variables
+	Args               []string                  // Arguments to parse
+	NilPtrs            bool                      // Leave pointers set to nil if values aren't specified. Ignored by ConfigureGenerated
+	Usage              func(*pflag.FlagSet)      // Usage function called when configuration is incorrect or for --help
+	NoRecover          bool                      // Don't recover from panic
+	ShowInternalFlags  bool                      // Show hidden internal flags
+	NoShortHelp        bool                      // Don't add "h" as a short help flag
+	RequireNoDefaults  bool                      // Require any fields that don't have a default value
+	Name               string                    // Name used to retrieve this configuration with GetNamed[T]()
+	EnvTemplateExport  bool                      // Prefix --print_env_template lines with "export " and single quote values so it can be sourced
+	EnvTemplateBare    bool                      // Omit comments and blank lines from --print_env_template
+	ConfigVersion      string                    // Expected config file "config_version". Older files are upgraded using AddMigration() migrations
+	KeyringService     string                    // Service name used to look up fields tagged secret:"" in Keyring
+	FileKeyStyle       KeyStyle                  // Casing of config file keys accepted when loading and printed by --print_yaml_template. Defaults to SnakeKeys
+	Profile            string                    // Config file profile merged over the rest of the config file
+	ProfileFlag        bool                      // Add a --profile flag, which overrides Profile
+	ConfigOverrides    bool                      // Merge config.<profile>.yaml and config.local.yaml, if they exist, over config.yaml
+	Sources            []Source                  // Additional sources, such as remote key/value stores, applied in order over the config file
+	SourceRetry        RetryPolicy               // Retry policy for loading Sources
+	SourceFallback     bool                      // Use the values last loaded from a Source if it fails to load
+	SourceCacheDir     string                    // Directory in which values loaded from Sources are saved for SourceFallback across restarts
+	WatchSources       bool                      // Reload configuration when a Source implementing WatchingSource changes
+	OnChange           func(config any)          // Called with the new *T after configuration is reloaded
+	EnvMapSeparator    string                    // Separator between key=value pairs of maps in environment variables. Defaults to ","
+	TrimSliceElements  bool                      // Trim whitespace from slice elements in environment variables and config files. Overridden by the trim tag
+	DropEmptyElements  bool                      // Drop empty slice elements in environment variables and config files. Overridden by the empty tag
+	Keyring            Keyring                   // Credential store for secret fields. Defaults to OSKeyring()
+	SecretResolvers    map[string]SecretResolver // Resolvers of LazySecret references by scheme, e.g. "vault"
+	SecretRefresh      time.Duration             // How often LazySecret fields that have been fetched are fetched again, to pick up rotated secrets
+	Output             io.Writer                 // Where usage and templates are printed. Defaults to os.Stdout
+	ErrOutput          io.Writer                 // Where errors and warnings are printed. Defaults to os.Stderr
+	Types              *Types                    // Custom types used in addition to those added with AddType and AddMapValueType
+	ArgsFilter         func([]string) []string   // Rewrites Args before they are parsed. E.g. to translate legacy flags
+	AbbrevFlags        bool                      // Accept unambiguous prefixes of long flag names. E.g. --sub_def for --sub_default_lock_timeout
+	Environ            map[string]string         // Environment variables used instead of the process environment. E.g. under js/wasm
+	Exit               func(code int)            // Called instead of os.Exit. If it returns, Configure panics with an *ExitError
+	Prompt             bool                      // Prompt for required values that weren't provided instead of failing
+	Prompter           Prompter                  // Reads prompted values. Defaults to TerminalPrompter() prompting on ErrOutput
+	DisableFlags       bool                      // Don't accept fields on the command line or show them in usage. Internal flags such as --help still work
+	DisableEnv         bool                      // Don't read fields or the config file name from the environment, and remove --print_env_template
+	ConfigEnv          bool                      // Load a config document from the <EnvPrefix>CONFIG_YAML or <EnvPrefix>CONFIG_JSON environment variable
+	EnvNestedDelimiter string                    // Separator between sub-config names in environment variables. E.g. "__" for APP_SUB__FOO_INT. Defaults to "_"
+	AutoShortFlags     bool                      // Assign a free letter of their names as the short flag of fields without a short tag
+	DryRun             bool                      // Load and validate the configuration, print the result and exit, as with --validate_config
+	ConfigFile         string                    // Config file loaded if no ConfigFile field gives one. "$NAME" reads the path from environment variable NAME
+	ConfigFileFormat   string                    // Format of config files, "yaml" or "json", used instead of their extensions. Overridden by the format tag
+	CheckFilePerms     FilePermCheck             // Warn or fail if config files holding secret values can be accessed by other users, like ssh
+	CheckSecretArgs    SecretArgCheck            // Warn or fail if the values of secret fields are given on the command line, where ps shows them
+	ConfigVerifier     ConfigVerifier            // Verifies config files before they are applied. E.g. ChecksumVerifier() or Ed25519Verifier()
+	FileDecoder        FileDecoder               // Decodes config files straight into the config struct instead of through flags. E.g. with mapstructure hooks. Ignored by ConfigureGenerated
+	TagNames           map[string]string         // Names of tags read instead of configurature's. E.g. {"help": "desc"} reads desc:"..." as help
+	UsageHideDefaults  bool                      // Leave default values out of usage
+	UsageHideTypes     bool                      // Leave value types out of usage
+	UsageOutput        io.Writer                 // Where usage is printed for --help. Defaults to Output. Usage shown because of an error is printed to ErrOutput
+	UsageErrorCode     int                       // Exit code after usage is shown because of an error. Defaults to 2
+	ShowCredentials    bool                      // Don't hide passwords in DSN values shown in usage, templates and --print_changed
+	AuditWriter        io.Writer                 // Where a record of the value and source of every option is written after configuration is loaded. Secrets are redacted
+	AuditFormat        AuditFormat               // Format of AuditWriter records, AuditJSON or AuditLogfmt. Defaults to AuditJSON
+	Metrics            Metrics                   // Receives load durations, reloads and failures, e.g. to alert on failed reloads
+	Snapshots          bool                      // Get[T](), GetNamed[T](), Latest[T]() and Subscribe[T]() hand out copies so callers can't modify shared configuration
+}
+
+// ExitError is the panic value of Configure when Options.Exit returns instead
+// of exiting. Code is 0 after --help or a --print_* flag and non-zero on error.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("configuration exited with code %d", e.Code)
 }
 
 // Configure will populate the supplied struct with options specified on the
 // command line or by environment variables prefixed by the specified envPrefix
 func Configure[T any](opts *Options) *T {
-	if opts == nil {
-		opts = &Options{
-			Args: os.Args[1:],
-		}
-	} else if opts.Args == nil {
-		opts.Args = os.Args[1:]
-	}
+	return ConfigureContext[T](context.Background(), opts)
+}
+
+// ConfigureContext is Configure with a context that bounds loading
+// configuration from sources that may block, such as the keyring. If ctx is
+// canceled or its deadline passes, configuration fails. Watched sources stop
+// being watched when ctx is done.
+func ConfigureContext[T any](ctx context.Context, opts *Options) *T {
+	start := time.Now()
+	opts = optionsWithDefaults(opts)
+	snapshotRegistries()
 
 	c := &configurer{
+		ctx:    ctx,
 		config: new(T),
 		opts:   opts,
 	}
 
 	// Create a flagset
-	f := flagSetFromOptions(opts)
-
-	// This is a chicken and egg situation where we need to parse flags to
-	// determine what the config file flags are, but we want to load the config
-	// from the file first so that flags specified on the command line or via
-	// env vars can overwrite any values set in the config file.
-
-	// Set config file pointer if specified. loadFlags() needs to know what the
-	// flag is for specifying the file.
-	c.setConfigFile()
+	f := flagSetFromOptions(opts, false)
 
 	// loadFlags(), makes the flagset is aware of all the config fields. It
 	// returns setters that will actually set the config values after args have
 	// been parsed.
 	setters := c.loadFlags(c.config, f)
+	if !opts.ShowCredentials {
+		redactCredentials(f)
+	}
 
 	// Recover from panic and print error
 	if !opts.NoRecover {
 		defer func() {
 			if r := recover(); r != nil {
-				fmt.Fprintf(os.Stderr, "error parsing configuration: %s\n", r)
-				os.Exit(1)
+				if _, ok := r.(*ExitError); ok {
+					panic(r)
+				}
+				fmt.Fprintf(opts.errOutput(), "error parsing configuration: %s\n", r)
+				opts.exit(1)
 			}
 		}()
 	}
 
-	// Load config file if the pointer was set by setConfigFile
-	if c.configFile.Value != nil {
-		c.loadConfigFile(f)
+	// Parse CLI args into flagset. The config file can then be determined
+	// from the parsed args.
+	c.argErrors = parseFlags(f, opts)
+
+	// Merge values from the config file and environment into flags that were
+	// not specified on the command line
+	c.applySources(f)
+
+	// Walk through the fields for --init
+	initFile, _ := f.GetString(initFlag)
+	if initFile != "" {
+		c.promptInit(c.config, f)
 	}
 
-	// Load values from environment
-	if opts.EnvPrefix != "" {
-		c.setFromEnv(c.config, f)
+	// Ask for required values that are missing
+	if opts.Prompt && !isDryRun(f, opts) && len(c.argErrors)+len(c.errors) == 0 {
+		c.promptRequired(c.config, f)
 	}
 
-	// Parse CLI args into flagset and run flag setter functions
-	f.Parse(opts.Args)
+	// Run flag setter functions
 	for _, fn := range setters {
 		fn()
 	}
+	if opts.FileDecoder != nil {
+		c.decodeConfigFiles()
+	}
 
 	// Show usage if requested
 	if help, _ := f.GetBool("help"); help {
 		f.Usage()
 	}
 
+	// Print options as JSON
+	if ok, _ := f.GetBool("help_json"); ok {
+		c.printHelpJSON(f)
+		opts.exit(0)
+	}
+
+	// Print changed options
+	if ok, _ := f.GetBool("print_changed"); ok {
+		c.printChanged(f)
+		opts.exit(0)
+	}
+
 	// Generate .env template
 	if ok, _ := f.GetBool("print_env_template"); ok {
 		c.printEnvTemplate(f)
-		os.Exit(0)
+		opts.exit(0)
 	}
 
 	// Generate YAML template
 	if ok, _ := f.GetBool("print_yaml_template"); ok {
 		c.printYamlTemplate(f)
-		os.Exit(0)
+		opts.exit(0)
+	}
+
+	// Print a shell completion script
+	if shell, _ := f.GetString(completionFlag); shell != "" {
+		c.printCompletion(shell, f)
+		opts.exit(0)
+	}
+
+	// Explain an option
+	if name, _ := f.GetString(explainFlag); name != "" {
+		c.explain(name, f)
+		opts.exit(0)
+	}
+
+	// Write the config file created by --init
+	if initFile != "" {
+		c.writeInitFile(initFile, f)
+		opts.exit(0)
 	}
 
 	// Validate config
+	if isDryRun(f, opts) {
+		dryRun(func() { c.validate(c.config, f) }, opts)
+	}
 	c.validate(c.config, f)
 
-	// Used by Get[T]()
-	setLastConfig(c.config)
+	storeConfig[T](c, f, start)
+
+	// Reload when watched sources change
+	if opts.WatchSources {
+		c.watchSources(func() {
+			config, err := reloadConfig[T](c.ctx, *opts)
+			if err != nil {
+				fmt.Fprintf(opts.errOutput(), "error reloading configuration: %v\n", err)
+				if opts.Metrics != nil {
+					opts.Metrics.ReloadFailed(err)
+				}
+				return
+			}
+			if opts.Metrics != nil {
+				opts.Metrics.Reloaded()
+			}
+			publish(config, opts.Snapshots)
+			if opts.OnChange != nil {
+				opts.OnChange(config)
+			}
+		})
+	}
+
+	// Fetch rotated secrets
+	if opts.SecretRefresh > 0 {
+		refreshSecrets[T](c)
+	}
 
 	return c.config.(*T)
 }
 
-// setFromEnv sets configuration values from environment
-func (c *configurer) setFromEnv(s any, fs *pflag.FlagSet) {
+// applySources merges values from config files, Sources, the environment and
+// the keyring into the flags of fs that were not specified on the command
+// line
+func (c *configurer) applySources(fs *pflag.FlagSet) {
+	values := sourceSetters{}
+	if len(c.configFileFlags) > 0 || c.opts.ConfigFile != "" || c.opts.ConfigEnv {
+		c.checkContext()
+		if c.fileKeyAliases == nil {
+			c.fileKeyAliases = fileKeyAliases(c.opts.Types, c.opts.TagNames, reflect.TypeOf(c.config).Elem(), []string{})
+		}
+	}
+	if len(c.configFileFlags) > 0 || c.opts.ConfigFile != "" {
+		c.loadConfigFiles(fs, values)
+	}
+	if len(c.opts.Sources) > 0 {
+		c.loadSources(fs, values)
+	}
+	if c.opts.ConfigEnv {
+		c.loadConfigEnv(fs, values)
+	}
+	if c.opts.EnvPrefix != "" && !c.opts.DisableEnv {
+		c.setFlagsFromEnv(fs, values)
+	}
+	if c.opts.KeyringService != "" {
+		c.checkContext()
+		c.setFromKeyring(fs, values)
+	}
+	c.checkContext()
+	c.sources = values.apply(fs)
+	c.checkSources(fs)
+	c.bindLazySecrets(fs)
+	if c.opts.CheckSecretArgs != "" {
+		c.checkSecretArgs(fs)
+	}
+}
+
+// storeConfig finishes loading a validated config of type T and stores it for
+// Get[T](), Latest[T]() and GetNamed[T]()
+func storeConfig[T any](c *configurer, fs *pflag.FlagSet, start time.Time) {
+	// Leave pointers to sub-configs that weren't specified nil
+	if c.opts.NilPtrs && !c.generated {
+		c.nilUnsetSubConfigs(fs)
+	}
+
+	if c.opts.AuditWriter != nil {
+		c.audit(fs)
+	}
+	if c.opts.Metrics != nil {
+		c.opts.Metrics.LoadDuration(time.Since(start))
+	}
+
+	// Store a copy so that the config returned to the caller can't modify it
+	stored := c.config.(*T)
+	if c.opts.Snapshots {
+		stored = Clone(stored)
+	}
+
+	// Used by Get[T]() and Latest[T]()
+	setLastConfig(stored, c.opts.Snapshots)
+	setLatest(stored, c.opts.Snapshots)
+
+	// Used by GetNamed[T]()
+	if c.opts.Name != "" {
+		setNamedConfig(c.opts.Name, stored, c.opts.Snapshots)
+	}
+}
+
+// checkContext panics if the configurer's context is done
+func (c *configurer) checkContext() {
+	if c.ctx == nil {
+		return
+	}
+	if c.ctx.Err() != nil {
+		panic(fmt.Sprintf("configuration canceled: %v", context.Cause(c.ctx)))
+	}
+}
+
+// optionsWithDefaults returns opts with default values filled in
+func optionsWithDefaults(opts *Options) *Options {
+	if opts == nil {
+		opts = &Options{
+			Args: os.Args[1:],
+		}
+	} else if opts.Args == nil {
+		opts.Args = os.Args[1:]
+	}
+	return opts
+}
+
+// output returns the writer for usage and template output
+func (o *Options) output() io.Writer {
+	if o.Output == nil {
+		return os.Stdout
+	}
+	return o.Output
+}
+
+// usageOutput returns the writer for usage requested with --help
+func (o *Options) usageOutput() io.Writer {
+	if o.UsageOutput == nil {
+		return o.output()
+	}
+	return o.UsageOutput
+}
+
+// usageErrorCode returns the exit code after usage is shown because of an
+// error
+func (o *Options) usageErrorCode() int {
+	if o.UsageErrorCode == 0 {
+		return 2
+	}
+	return o.UsageErrorCode
+}
+
+// errOutput returns the writer for errors and warnings
+func (o *Options) errOutput() io.Writer {
+	if o.ErrOutput == nil {
+		return os.Stderr
+	}
+	return o.ErrOutput
+}
 
-	c.visitFields(s, func(f reflect.StructField, tags *reflect.StructTag, v reflect.Value, ancestors []string) (stop bool) {
-		fName := fieldNameToConfigName(f.Name, tags, ancestors)
-		envVal := os.Getenv(
-			fmt.Sprintf("%s%s", c.opts.EnvPrefix, strcase.ToScreamingSnake(fName)),
-		)
-		if envVal != "" {
-			if err := setFlagValue(fName, envVal, fs); err != nil {
-				panic(fmt.Sprintf("setFromEnv(): error setting value of field %s: %v", f.Name, err))
+// getenv returns the value of the environment variable name from Environ or,
+// if Environ is nil, the process environment. It is always empty if
+// DisableEnv is set.
+func (o *Options) getenv(name string) string {
+	if o.DisableEnv {
+		return ""
+	}
+	if o.Environ != nil {
+		return o.Environ[name]
+	}
+	return os.Getenv(name)
+}
+
+// getenvPrefixed returns the environment variables whose names start with
+// prefix, keyed by the rest of their names
+func (o *Options) getenvPrefixed(prefix string) map[string]string {
+	vars := map[string]string{}
+	if o.DisableEnv {
+		return vars
+	}
+	environ := o.Environ
+	if environ == nil {
+		environ = map[string]string{}
+		for _, kv := range os.Environ() {
+			if k, v, ok := strings.Cut(kv, "="); ok {
+				environ[k] = v
 			}
 		}
-		return stop
-	}, []string{})
+	}
+	for k, v := range environ {
+		if name, ok := strings.CutPrefix(k, prefix); ok && name != "" && v != "" {
+			vars[name] = v
+		}
+	}
+	return vars
+}
+
+// exit calls Exit or os.Exit with code. If Exit returns, it panics with an
+// *ExitError so that configuration stops.
+func (o *Options) exit(code int) {
+	if o.Exit == nil {
+		os.Exit(code)
+	}
+	o.Exit(code)
+	panic(&ExitError{Code: code})
+}
+
+// filteredArgs returns the Args to parse after applying ArgsFilter
+func (o *Options) filteredArgs() []string {
+	if o.ArgsFilter == nil {
+		return o.Args
+	}
+	return o.ArgsFilter(slices.Clone(o.Args))
+}
+
+// envVarName returns the environment variable for the config name fName of
+// a field in the sub-configs ancestors. Sub-config names are separated by
+// EnvNestedDelimiter if it is set.
+func (o *Options) envVarName(fName string, ancestors []string) string {
+	if o.EnvNestedDelimiter == "" || len(ancestors) == 0 {
+		return o.EnvPrefix + toScreamingSnake(fName)
+	}
+	parts := []string{}
+	for _, a := range ancestors {
+		parts = append(parts, toScreamingSnake(a))
+	}
+	parts = append(parts, toScreamingSnake(stripAncestors(fName, ancestors)))
+	return o.EnvPrefix + strings.Join(parts, o.EnvNestedDelimiter)
+}
+
+// addEnvSetter adds a setter for flag fl to values if the environment
+// variable envName is set. Errors setting the value are added to c.errors.
+func (c *configurer) addEnvSetter(fl *pflag.Flag, envName string, values sourceSetters) {
+	if rawVal := c.opts.getenv(envName); rawVal != "" {
+		envVal := c.envMapValue(fl, rawVal)
+		envVal = sliceValue(fl, c.opts, envVal)
+		values[fl.Name] = sourceSetter{sourceEnv, func() {
+			if err := fl.Value.Set(envVal); err != nil {
+				c.errors = append(c.errors, maskSecret(fl, fmt.Sprintf("invalid value %q for environment variable %s (%s): %v",
+					rawVal, envName, fl.Value.Type(), err), rawVal))
+			}
+		}}
+	}
+	if strings.HasPrefix(fl.Value.Type(), "stringTo") {
+		c.addEnvMapEntriesSetter(fl, envName, values)
+	}
+}
+
+// addEnvMapEntriesSetter adds a setter for map flag fl to values if
+// environment variables for individual keys, named envName, "__" and the key,
+// are set. The entries are merged over the values from other sources.
+func (c *configurer) addEnvMapEntriesSetter(fl *pflag.Flag, envName string, values sourceSetters) {
+	prefix := envName + envMapKeyDelimiter
+	entries := c.opts.getenvPrefixed(prefix)
+	if len(entries) == 0 {
+		return
+	}
+	prev, hasPrev := values[fl.Name]
+	values[fl.Name] = sourceSetter{sourceEnv, func() {
+		if hasPrev {
+			prev.set()
+		}
+		for _, key := range slices.Sorted(maps.Keys(entries)) {
+			rawVal := entries[key]
+			entry := strings.ToLower(key) + "=" + rawVal
+			if fl.Value.Type() == "stringToString" {
+				// Values are parsed as CSV and may contain commas
+				entry = `"` + strings.ReplaceAll(entry, `"`, `""`) + `"`
+			}
+			if err := fl.Value.Set(entry); err != nil {
+				c.errors = append(c.errors, maskSecret(fl, fmt.Sprintf("invalid value %q for environment variable %s (%s): %v",
+					rawVal, prefix+key, fl.Value.Type(), err), rawVal))
+			}
+		}
+	}}
+}
+
+// checkErrors reports the errors encountered setting values along with the
+// validation errors errs, so that they can all be fixed at once. Invalid flag
+// values are reported with the usage, unless the configuration is only being
+// validated.
+func (c *configurer) checkErrors(fs *pflag.FlagSet, errs []string) {
+	errs = slices.Concat(c.argErrors, c.errors, errs)
+	if len(c.argErrors) > 0 && !isDryRun(fs, c.opts) {
+		usageError(fs, c.opts, strings.Join(errs, "\n"))
+	}
+	if len(errs) > 0 {
+		failValidation(c.opts, errs)
+	}
+}
+
+// envMapValue converts the value of an environment variable for a map flag
+// whose key=value pairs are separated by EnvMapSeparator to the
+// comma-separated form the flag accepts
+func (c *configurer) envMapValue(fl *pflag.Flag, envVal string) string {
+	if c.opts.EnvMapSeparator == "" || c.opts.EnvMapSeparator == "," ||
+		!strings.HasPrefix(fl.Value.Type(), "stringTo") {
+		return envVal
+	}
+	return delimitedToCSV(envVal, c.opts.EnvMapSeparator)
+}
+
+// apply runs the setters for flags that were not specified on the command
+// line in flag name order. It returns the source of each flag value that was
+// set.
+func (s sourceSetters) apply(fs *pflag.FlagSet) map[string]string {
+	sources := map[string]string{}
+	fs.Visit(func(f *pflag.Flag) {
+		sources[f.Name] = sourceFlag
+	})
+	for _, name := range slices.Sorted(maps.Keys(s)) {
+		if _, ok := sources[name]; ok {
+			continue
+		}
+		s[name].set()
+		sources[name] = s[name].source
+	}
+	return sources
 }
 
 // loadFlags() sets field values based on options specified on the command line
 // or by environment variables
 func (c *configurer) loadFlags(s any, fl *pflag.FlagSet) []func() {
 
+	// Report conflicting field names before pflag panics
+	checkFlagNames(c.opts.Types, c.opts.TagNames, reflect.TypeOf(s).Elem(), fl)
+
+	autoShorts := map[string]string{}
+	if c.opts.AutoShortFlags {
+		autoShorts = autoShortFlags(c.opts.Types, c.opts.TagNames, reflect.TypeOf(s).Elem(), fl)
+	}
+
 	setters := []func(){}
 
-	c.visitFields(s, func(f reflect.StructField, tags *reflect.StructTag, v reflect.Value, ancestors []string) (stop bool) {
+	c.visitStructFields(s, func(sf structField, tags *reflect.StructTag, v reflect.Value) (stop bool) {
+		f, ancestors := sf.field, sf.ancestors
 
-		fName := fieldNameToConfigName(f.Name, tags, ancestors)
+		fName := sf.name
 		helpTag, ok := tags.Lookup("help")
 		if !ok {
-			helpTag = strings.ReplaceAll(fieldNameToConfigName(f.Name, tags, ancestors), "_", " ")
+			helpTag = strings.ReplaceAll(fName, "_", " ")
 		}
 		shortTag := tags.Get("short")
+		if shortTag == "" {
+			shortTag = autoShorts[fName]
+		}
 		defaultTag, ok := tags.Lookup("default")
 		noDefault := !ok
 
 		// Special case for ConfigFile field
 		if v.Elem().Type() == configFileType {
-			c.configFile.Flag = fName
-			c.configFile.Short = shortTag
+			c.configFileFlags = append(c.configFileFlags, fName)
+		}
+
+		// Slice elements separated by delim rather than commas
+		delim, hasDelim := tags.Lookup("delim")
+		if hasDelim {
+			if v.Elem().Kind() != reflect.Slice {
+				panic(fmt.Sprintf("delim tag on field %s: only slice fields support delim", f.Name))
+			}
+			if !noDefault {
+				defaultTag = delimitedToCSV(defaultTag, delim)
+			}
+		}
+
+		// Bytes fields may be hex encoded
+		hexBytes := bytesEncoding(f.Name, v.Elem().Type(), tags) == "hex"
+		if hexBytes && !noDefault {
+			defaultTag = hexToBase64(f.Name, defaultTag)
 		}
 
 		enumProvided := false
@@ -183,27 +708,76 @@ func (c *configurer) loadFlags(s any, fl *pflag.FlagSet) []func() {
 			helpTag += fmt.Sprintf(" (%s)", strings.Replace(enums, ",", "|", -1))
 			enumProvided = true
 		}
-		addToFlagSet(v.Type(), enumProvided, fl, fName, shortTag, defaultTag, helpTag)
+		if parser, ok := tags.Lookup("parser"); ok {
+			addParsedToFlagSet(c.opts.Types, v.Elem().Type(), parser, fl, fName, shortTag, defaultTag, helpTag)
+		} else {
+			addToFlagSet(c.opts.Types, v.Type(), sf.flagFn, enumProvided, fl, fName, shortTag, defaultTag, helpTag)
+		}
+		if hasDelim {
+			setFlagDelim(fl, fName, delim)
+		}
+		if hexBytes {
+			setFlagHex(fl, fName)
+		}
+		if _, ok := tags.Lookup("secret"); ok {
+			markSecret(fl, fName, !noDefault)
+		}
 
 		// Hide hidden flags
 		if _, ok := tags.Lookup("hidden"); ok {
 			fl.MarkHidden(fName)
 		}
 
+		// Value used when the flag is given without one
+		if noOptDef, ok := tags.Lookup("flagdefault"); ok {
+			fl.Lookup(fName).NoOptDefVal = noOptDef
+		}
+
+		// Used to complete enum values
+		if enums := tags.Get("enum"); enums != "" {
+			fl.SetAnnotation(fName, annotationEnum, strings.Split(enums, ","))
+		}
+
+		// Mark paths that are relative to the config file
+		if _, ok := tags.Lookup("relpath"); ok {
+			fl.SetAnnotation(fName, annotationRelPath, []string{"true"})
+		}
+
+		// Slice element policies
+		annotateSlicePolicy(fl, fName, tags)
+
+		// Sources allowed to set the field
+		annotateSources(fl, fName, tags)
+
+		// Usage section
+		if group := tags.Get("group"); group != "" {
+			fl.SetAnnotation(fName, annotationGroup, []string{group})
+		}
+
+		// Format of the ConfigFile field's file
+		if format := tags.Get("format"); format != "" {
+			fl.SetAnnotation(fName, annotationFormat, []string{format})
+		}
+
+		// Used to name the flag's environment variable
+		if len(ancestors) > 0 {
+			fl.SetAnnotation(fName, annotationAncestors, ancestors)
+		}
+
 		isPtr := v.Kind() == reflect.Ptr
 		setters = append(setters, func() {
 			// Don't set pointers if
 			// * No default value was provided
 			// * the NilPtrs option is set
 			// * the value hasn't changed (wasn't specified)
-			if noDefault && c.opts.NilPtrs && isPtr && !fl.Lookup(fName).Changed {
+			if noDefault && c.opts.NilPtrs && isPtr && !c.specified(fName, fl) {
 				return
 			}
-			setNativeValue(v, fName, fl)
+			setNativeValue(c.opts.Types, v, fName, fl)
 		})
 
 		return false
-	}, []string{})
+	})
 
 	return setters
 }
@@ -211,58 +785,150 @@ func (c *configurer) loadFlags(s any, fl *pflag.FlagSet) []func() {
 // visitFields visits the fields of the config struct and calls the
 // provided function on each field.
 func (c *configurer) visitFields(s any, f func(reflect.StructField, *reflect.StructTag, reflect.Value, []string) bool, ancestors []string) bool {
+	return c.visitStructFields(s, func(sf structField, tags *reflect.StructTag, v reflect.Value) bool {
+		return f(sf.field, tags, v, slices.Concat(ancestors, sf.ancestors))
+	})
+}
+
+// visitStructFields is like visitFields, but passes the cached structField of
+// each field
+func (c *configurer) visitStructFields(s any, f func(structField, *reflect.StructTag, reflect.Value) bool) bool {
 	v := reflect.ValueOf(s).Elem()
-	t := v.Type()
+
+	for _, sf := range structFieldsOf(c.opts.Types, c.opts.TagNames, v.Type()) {
+		tags := c.withDefaultFile(sf.field.Name, sf.field.Tag)
+
+		// Call function on field and stop if it returns true
+		if f(sf, &tags, fieldByIndexAlloc(v, sf.index).Addr()) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldByIndexAlloc returns the nested field of struct v with the index
+// sequence, allocating nil pointers to sub-configs on the way
+func fieldByIndexAlloc(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// structFieldsOf returns the config fields of struct type t, using the cache
+// when possible
+func structFieldsOf(types *Types, tagNames map[string]string, t reflect.Type) []structField {
+	key := structFieldsKey{t: t}
+	if len(tagNames) > 0 {
+		key.tagNames = fmt.Sprint(tagNames)
+	}
+	cache := &structFieldsCache
+	if types != nil {
+		cache = &types.fieldsCache
+	}
+
+	gen := structFieldsGen.Load()
+	if fields, ok := cache.get(key, gen); ok {
+		return fields
+	}
+	fields := collectStructFields(types, tagNames, t, []int{}, []string{})
+	cache.put(key, gen, fields)
+	return fields
+}
+
+// collectStructFields recursively collects the config fields of struct type
+// t. index and ancestors are those of t in the top level config struct.
+func collectStructFields(types *Types, tagNames map[string]string, t reflect.Type, index []int, ancestors []string) []structField {
+	fields := []structField{}
 
 	for i := 0; i < t.NumField(); i++ {
 
 		if !t.Field(i).IsExported() {
 			continue
 		}
+		field := configField(t, t.Field(i), tagNames)
 
 		// Parse tags
-		tags := t.Field(i).Tag
+		tags := field.Tag
 
 		// Skip any fields tagged with ignore:""
 		if _, ok := tags.Lookup("ignore"); ok {
 			continue
 		}
 
+		fieldIndex := slices.Concat(index, []int{i})
+
 		// Handle anonymous struct fields, which are sub-configs
-		if t.Field(i).Anonymous {
-			fld := v.Field(i).Addr().Interface()
-			if stop := c.visitFields(fld, f, ancestors); stop {
-				return true
-			}
+		if field.Anonymous {
+			sub := collectStructFields(types, tagNames, field.Type, fieldIndex, ancestors)
+			fields = append(fields, withSubConfigDefaults(field.Name, tags, sub, ancestors)...)
 			continue
 		}
 
 		// Handle nested config structs
-		if t.Field(i).Type.Kind() == reflect.Struct {
-			fld := v.Field(i).Addr().Interface()
-			fName := t.Field(i).Name
+		if isSubConfig(types, field.Type) {
+			fName := field.Name
 			if name, ok := tags.Lookup("name"); ok {
 				fName = name
 			}
 
-			var newAncestors []string
+			newAncestors := ancestors
 			if fName != "" {
-				newAncestors = append(ancestors, strcase.ToSnake(fName))
-			} else {
-				newAncestors = ancestors
-			}
-			if stop := c.visitFields(fld, f, newAncestors); stop {
-				return true
+				newAncestors = slices.Concat(ancestors, []string{toSnake(fName)})
 			}
+			sub := collectStructFields(types, tagNames, subConfigType(field.Type), fieldIndex, newAncestors)
+			fields = append(fields, withSubConfigDefaults(field.Name, tags, sub, newAncestors)...)
 			continue
 		}
 
-		// Call function on field and stop if it returns true
-		if f(t.Field(i), &tags, v.Field(i).Addr(), ancestors) {
-			return true
+		flagFn, _ := getCustomFlagFn(types, subConfigType(field.Type))
+		fields = append(fields, structField{
+			field:     withDescription(t, field),
+			index:     fieldIndex,
+			ancestors: ancestors,
+			name:      fieldNameToConfigName(field.Name, &tags, ancestors),
+			flagFn:    flagFn,
+		})
+	}
+	return fields
+}
+
+// configField returns field of struct type owner with its tags renamed by
+// tagNames, its cfg tag expanded and the tags from its registered metadata
+// added, in order of precedence
+func configField(owner reflect.Type, field reflect.StructField, tagNames map[string]string) reflect.StructField {
+	return withFieldMeta(owner, withCfgTag(withTagNames(field, tagNames)))
+}
+
+// isSubConfig returns whether a field of type t is a nested config struct, or
+// a pointer to one, rather than a struct type with a registered Value. E.g.
+// time.Time
+func isSubConfig(types *Types, t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		if _, ok := getCustomFlagFn(types, t); ok {
+			return false
 		}
+		t = t.Elem()
 	}
-	return false
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	_, ok := getCustomFlagFn(types, t)
+	return !ok
+}
+
+// subConfigType returns the struct type of a sub-config field of type t
+func subConfigType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
 }
 
 // fieldNameToConfigName converts a struct field name and its ancestor path to
@@ -271,14 +937,60 @@ func fieldNameToConfigName(name string, tags *reflect.StructTag, ancestors []str
 	if nm, ok := tags.Lookup("name"); ok && nm != "" {
 		name = nm
 	}
-	return strings.Join(append(ancestors, strcase.ToSnake(name)), "_")
+	return strings.Join(append(ancestors, toSnake(name)), "_")
+}
+
+// parseFlags parses the Args of opts into fs after applying ArgsFilter and
+// expanding abbreviated long flags. All unknown flags are reported at once.
+// Invalid flag values don't stop parsing. They are returned to be reported
+// along with the other configuration errors. Values of secret flags are kept
+// out of errors. Only internal flags are accepted if DisableFlags is set.
+func parseFlags(fs *pflag.FlagSet, opts *Options) []string {
+	args := opts.filteredArgs()
+	if opts.AbbrevFlags {
+		var err error
+		if args, err = expandAbbrevFlags(fs, args); err != nil {
+			usageError(fs, opts, err.Error())
+		}
+	}
+	valueErrors := []string{}
+	err := unknownFlagsError(fs, args, opts)
+	if err == nil {
+		err = fs.ParseAll(args, func(fl *pflag.Flag, value string) error {
+			if err := setFlagMasked(fs, fl, value); err != nil {
+				// The flag was given, so it isn't also reported as missing
+				valueErrors = append(valueErrors, err.Error())
+				fl.Changed = true
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		usageError(fs, opts, err.Error())
+	}
+	return valueErrors
+}
+
+// usageError prints msg and the usage to ErrOutput and exits, as
+// pflag.ExitOnError would with the built-in usage
+func usageError(fs *pflag.FlagSet, opts *Options, msg string) {
+	fmt.Fprintln(fs.Output(), msg)
+	if opts.Usage != nil {
+		fs.Usage()
+	} else {
+		printUsage(opts.errOutput(), fs, opts)
+	}
+	opts.exit(opts.usageErrorCode())
 }
 
 // flagSetFromOptions creates and returns a *pflag.FlagSet based on the
-// provided options
-func flagSetFromOptions(opts *Options) *pflag.FlagSet {
+// provided options. generated leaves out the internal flags that
+// ConfigureGenerated does not support.
+func flagSetFromOptions(opts *Options, generated bool) *pflag.FlagSet {
 
-	f := pflag.NewFlagSet("config", pflag.ExitOnError)
+	// Errors are handled by parseFlags()
+	f := pflag.NewFlagSet("config", pflag.ContinueOnError)
+	f.SetOutput(opts.errOutput())
 
 	// Set up help flag
 	if opts.NoShortHelp {
@@ -292,22 +1004,67 @@ func flagSetFromOptions(opts *Options) *pflag.FlagSet {
 		f.Usage = func() { opts.Usage(f) }
 	} else {
 		f.Usage = func() {
-			fmt.Println("Command usage:")
-			fmt.Println(f.FlagUsages())
-			os.Exit(0)
+			printUsage(opts.usageOutput(), f, opts)
+			opts.exit(0)
 		}
 	}
 
+	// print_changed flag setup
+	f.Bool("print_changed", false, "Print options that differ from their defaults and exit")
+	if !opts.ShowInternalFlags {
+		f.MarkHidden("print_changed")
+	}
+
 	// print_env_template flag setup
-	f.Bool("print_env_template", false, "Print example environment variables and exit")
+	if !opts.DisableEnv {
+		f.Bool("print_env_template", false, "Print example environment variables and exit")
+		if !opts.ShowInternalFlags {
+			f.MarkHidden("print_env_template")
+		}
+	}
+
+	// Flags that need reflection aren't supported by generated configs
+	if !generated {
+		// help_json flag setup
+		f.Bool("help_json", false, "Print configuration options as JSON and exit")
+		if !opts.ShowInternalFlags {
+			f.MarkHidden("help_json")
+		}
+
+		// print_yaml_template flag setup
+		f.Bool("print_yaml_template", false, "Print example YAML config file and exit")
+		if !opts.ShowInternalFlags {
+			f.MarkHidden("print_yaml_template")
+		}
+
+		// init flag setup
+		f.String(initFlag, "", "Interactively create a YAML config file at the given path and exit")
+		if !opts.ShowInternalFlags {
+			f.MarkHidden(initFlag)
+		}
+
+		// explain flag setup
+		f.String(explainFlag, "", "Print everything known about the given option and exit")
+		if !opts.ShowInternalFlags {
+			f.MarkHidden(explainFlag)
+		}
+	}
+
+	// completion flag setup
+	f.String(completionFlag, "", "Print a shell completion script for bash, zsh or fish and exit")
 	if !opts.ShowInternalFlags {
-		f.MarkHidden("print_env_template")
+		f.MarkHidden(completionFlag)
 	}
 
-	// print_yaml_template flag setup
-	f.Bool("print_yaml_template", false, "Print example YAML config file and exit")
+	// validate_config flag setup
+	f.Bool(validateConfigFlag, false, "Load and validate the configuration, print the result and exit")
 	if !opts.ShowInternalFlags {
-		f.MarkHidden("print_yaml_template")
+		f.MarkHidden(validateConfigFlag)
+	}
+
+	// profile flag setup
+	if opts.ProfileFlag {
+		f.String(profileFlag, opts.Profile, "Configuration file profile to use")
 	}
 
 	return f
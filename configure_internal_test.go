@@ -0,0 +1,123 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStructFieldsOf(t *testing.T) {
+	type Inner struct {
+		Port int
+	}
+	type Embedded struct {
+		Host string
+	}
+	type Outer struct {
+		Embedded
+		Name    string
+		ignored string
+		Skip    string `ignore:""`
+		DB      Inner
+		Other   Inner `name:""`
+	}
+
+	typ := reflect.TypeFor[Outer]()
+	clearStructFieldsCache()
+
+	fields := structFieldsOf(nil, nil, typ)
+	names := []string{}
+	for _, sf := range fields {
+		names = append(names, sf.name)
+	}
+	assert.Equal(t, []string{"host", "name", "db_port", "port"}, names)
+	assert.Equal(t, []int{4, 0}, fields[2].index)
+
+	// Second call is served from the cache
+	assert.Same(t, &fields[0], &structFieldsOf(nil, nil, typ)[0])
+}
+
+func TestStructFieldsOf_TypesAndTagNames(t *testing.T) {
+	type Conf struct {
+		Name string `desc:"the name"`
+	}
+	typ := reflect.TypeFor[Conf]()
+	types := NewTypes()
+	tagNames := map[string]string{"help": "desc"}
+
+	fields := structFieldsOf(types, tagNames, typ)
+	assert.Equal(t, "the name", fields[0].field.Tag.Get("help"))
+	assert.Same(t, &fields[0], &structFieldsOf(types, map[string]string{"help": "desc"}, typ)[0])
+
+	// Other tag names and registries are cached separately
+	assert.Equal(t, "", structFieldsOf(types, nil, typ)[0].field.Tag.Get("help"))
+	assert.NotSame(t, &fields[0], &structFieldsOf(NewTypes(), tagNames, typ)[0])
+}
+
+func TestStructFieldsOf_RegisterType(t *testing.T) {
+	type Point struct {
+		X, Y int
+	}
+	type Conf struct {
+		Origin Point
+	}
+	typ := reflect.TypeFor[Conf]()
+	types := NewTypes()
+
+	fields := structFieldsOf(types, nil, typ)
+	assert.Equal(t, []string{"origin_x", "origin_y"}, []string{fields[0].name, fields[1].name})
+
+	// Registering the type makes Point a value rather than a sub-config
+	fn := func(string, string, string, string, *pflag.FlagSet) {}
+	registerType(types, reflect.TypeFor[Point](), fn, nil)
+
+	fields = structFieldsOf(types, nil, typ)
+	assert.Len(t, fields, 1)
+	assert.Equal(t, "origin", fields[0].name)
+	assert.NotNil(t, fields[0].flagFn)
+}
+
+func TestStructFieldsOf_Generations(t *testing.T) {
+	type Conf struct {
+		Name string
+	}
+	typ := reflect.TypeFor[Conf]()
+	types := NewTypes()
+
+	fields := structFieldsOf(types, nil, typ)
+	assert.Len(t, types.fieldsCache.fields, 1)
+	assert.Same(t, &fields[0], &structFieldsOf(types, nil, typ)[0])
+
+	// Fields of older generations are dropped rather than kept alongside
+	clearStructFieldsCache()
+	assert.NotSame(t, &fields[0], &structFieldsOf(types, nil, typ)[0])
+	assert.Len(t, types.fieldsCache.fields, 1)
+	assert.Equal(t, structFieldsGen.Load(), types.fieldsCache.gen)
+
+	// Fields collected before a registration change aren't cached
+	types.fieldsCache.put(structFieldsKey{t: reflect.TypeFor[int]()}, structFieldsGen.Load()-1, nil)
+	assert.Len(t, types.fieldsCache.fields, 1)
+}
+
+func TestPrompter_ErrOutput(t *testing.T) {
+	errOut := &strings.Builder{}
+	c := &configurer{opts: &Options{ErrOutput: errOut}}
+	assert.Equal(t, terminalPrompter{out: errOut}, c.prompter())
+}
@@ -15,6 +15,7 @@
 package configurature_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -227,66 +228,145 @@ func TestFlagOverrideEnv(t *testing.T) {
 	assert.Equal("0.0.0.0:443", c.ListenAddress, "ListenAddress should be 0.0.0.0:443")
 }
 
-func TestConfigFile_FromEnv(t *testing.T) {
+func TestBadEnvVar(t *testing.T) {
+	if os.Getenv("TEST_PASSTHROUGH") == "1" {
+		os.Setenv("TEST_CONF_FOO_INT", "asdf")
+		co.Configure[TestConfigFileStruct](&co.Options{
+			EnvPrefix: "TEST_CONF_",
+		})
+		os.Exit(0)
+	}
+
 	assert := assert.New(t)
+	stdout, stderr := runExternal(t)
 
-	tmp, _ := os.CreateTemp("", "cfgr-test-*.yml")
-	defer os.Remove(tmp.Name())
-	tmp.Write([]byte("foo_int: 4\nsub_foo_string: 'yes'\nkeepalive_timeout: 3m\nbool: true\n"))
-	tmp.Close()
+	assert.Equal("", stdout)
+	assert.Equal("error parsing configuration: invalid value \"asdf\" for environment variable TEST_CONF_FOO_INT (uint32): "+
+		"strconv.ParseUint: parsing \"asdf\": invalid syntax\n", stderr)
+}
 
-	os.Setenv("TEST_CONF_COOL_FILE", tmp.Name())
-	defer os.Setenv("TEST_CONF_COOL_FILE", "")
+func TestBadEnvVars(t *testing.T) {
+	type Conf struct {
+		Port    co.Port
+		Timeout time.Duration
+		Sub     struct {
+			Count int
+		}
+	}
+	t.Setenv("BAD_ENV_PORT", "99999")
+	t.Setenv("BAD_ENV_TIMEOUT", "soon")
+	t.Setenv("BAD_ENV_SUB_COUNT", "many")
+
+	assert.PanicsWithValue(t, `invalid value "99999" for environment variable BAD_ENV_PORT (port): `+
+		`invalid port "99999": must be a number between 1 and 65535, `+
+		`invalid value "many" for environment variable BAD_ENV_SUB_COUNT (int): `+
+		`strconv.ParseInt: parsing "many": invalid syntax, `+
+		`invalid value "soon" for environment variable BAD_ENV_TIMEOUT (duration): `+
+		`time: invalid duration "soon"`, func() {
+		co.Configure[Conf](&co.Options{
+			NoRecover: true,
+			EnvPrefix: "BAD_ENV_",
+			Args:      []string{},
+		})
+	})
+}
+
+func TestArgsFilter(t *testing.T) {
+	type Conf struct {
+		Name  string
+		Count int
+	}
+	assert := assert.New(t)
 
-	c := co.Configure[TestConfigFileStruct](&co.Options{
+	args := []string{"-name", "x", "--count", "3"}
+	c := co.Configure[Conf](&co.Options{
 		NoRecover: true,
-		EnvPrefix: "TEST_CONF_",
+		Args:      args,
+		ArgsFilter: func(args []string) []string {
+			// Translate legacy single dash long flags
+			for i, a := range args {
+				if len(a) > 2 && a[0] == '-' && a[1] != '-' {
+					args[i] = "-" + a
+				}
+			}
+			return args
+		},
 	})
-
-	assert.Equal(uint32(4), c.FooInt, "FooInt should be 4")
-	assert.Equal("yes", c.SubFooString, "SubFooString should be 'yes'")
-	assert.Equal(time.Duration(3)*time.Minute, c.KeepaliveTimeout, "KeepaliveTimeout should be 3m")
-	assert.Equal(true, c.Bool, "Bool should be true")
+	assert.Equal("x", c.Name)
+	assert.Equal(3, c.Count)
+	assert.Equal([]string{"-name", "x", "--count", "3"}, args)
 }
 
-func TestConfigFile_Precedence(t *testing.T) {
+func TestFlagDefault(t *testing.T) {
+	type Conf struct {
+		Profile string `default:"none" flagdefault:"default"`
+		Level   int    `flagdefault:"1"`
+		Debug   bool   `flagdefault:"false"`
+	}
 	assert := assert.New(t)
 
-	os.Setenv("TEST_CONF_FOO_INT", "7")
-	os.Setenv("TEST_CONF_SUB_FOO_STRING", "asdf")
-
-	tmp, _ := os.CreateTemp("", "cfgr-test-*.yml")
-	defer os.Remove(tmp.Name())
-	tmp.Write([]byte("foo_int: 4\nsub_foo_string: 'yes'\nkeepalive_timeout: 3m\nbool: false\n"))
-	tmp.Close()
+	c := co.Configure[Conf](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+	})
+	assert.Equal("none", c.Profile)
+	assert.Equal(0, c.Level)
 
-	c := co.Configure[TestConfigFileStruct](&co.Options{
+	c = co.Configure[Conf](&co.Options{
 		NoRecover: true,
-		EnvPrefix: "TEST_CONF_",
-		Args:      []string{"--cool_file", tmp.Name(), "--foo_int", "22"},
+		Args:      []string{"--profile", "--level", "--debug"},
 	})
-	assert.Equal(uint32(22), c.FooInt, "FooInt should be 22")
-	assert.Equal("asdf", c.SubFooString, "SubFooString should be 'asdf'")
-	assert.Equal(time.Duration(3)*time.Minute, c.KeepaliveTimeout, "KeepaliveTimeout should be 3m")
-	assert.Equal(false, c.Bool, "Bool should be false")
+	assert.Equal("default", c.Profile)
+	assert.Equal(1, c.Level)
+	assert.False(c.Debug)
 
+	c = co.Configure[Conf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--profile=prod", "--level=3", "--debug=true"},
+	})
+	assert.Equal("prod", c.Profile)
+	assert.Equal(3, c.Level)
+	assert.True(c.Debug)
 }
 
-func TestBadEnvVar(t *testing.T) {
-	if os.Getenv("TEST_PASSTHROUGH") == "1" {
-		os.Setenv("TEST_CONF_FOO_INT", "asdf")
-		co.Configure[TestConfigFileStruct](&co.Options{
-			EnvPrefix: "TEST_CONF_",
-		})
-		os.Exit(0)
+func TestBadValues_WithValidation(t *testing.T) {
+	type Conf struct {
+		Count int
+		Name  string `required:""`
+		Mode  string `enum:"a,b" default:"a"`
 	}
+	t.Setenv("ALL_ERR_COUNT", "many")
 
+	assert.PanicsWithValue(t, `invalid value "many" for environment variable ALL_ERR_COUNT (int): `+
+		`strconv.ParseInt: parsing "many": invalid syntax, name is required`, func() {
+		co.Configure[Conf](&co.Options{
+			NoRecover: true,
+			EnvPrefix: "ALL_ERR_",
+			Args:      []string{},
+		})
+	})
+}
+
+func TestBadFlagValues_Aggregated(t *testing.T) {
+	type Conf struct {
+		Port  co.Port
+		Count int
+		Name  string `required:""`
+		Key   string `required:""`
+	}
 	assert := assert.New(t)
-	stdout, stderr := runExternal(t)
+	configure := func(opts *co.Options) { co.Configure[Conf](opts) }
 
-	assert.Equal("", stdout)
-	assert.Equal("error parsing configuration: setFromEnv(): error setting value of field FooInt: "+
-		"strconv.ParseUint: parsing \"asdf\": invalid syntax\n", stderr)
+	code, _, errOut := configureExit(t, configure, co.Options{
+		Args: []string{"--port", "99999", "--count", "many", "--key", "k"},
+	})
+
+	assert.Equal(2, code)
+	assert.True(strings.HasPrefix(errOut, `invalid argument "99999" for "--port" flag: `+
+		`invalid port "99999": must be a number between 1 and 65535`+"\n"+
+		`invalid argument "many" for "--count" flag: strconv.ParseInt: parsing "many": invalid syntax`+"\n"+
+		"name is required\n"), errOut)
+	assert.Contains(errOut, "Command usage:")
 }
 
 func TestBadFlagValue(t *testing.T) {
@@ -301,8 +381,8 @@ func TestBadFlagValue(t *testing.T) {
 	assert := assert.New(t)
 	stdout, stderr := runExternal(t)
 
-	assert.Equal("", stderr)
-	assert.True(strings.HasPrefix(stdout, "Command usage:"))
+	assert.Equal("", stdout)
+	assert.Contains(stderr, "Command usage:")
 }
 
 func TestBadFlag(t *testing.T) {
@@ -317,8 +397,8 @@ func TestBadFlag(t *testing.T) {
 	assert := assert.New(t)
 	stdout, stderr := runExternal(t)
 
-	assert.Equal("", stderr)
-	assert.True(strings.HasPrefix(stdout, "Command usage:"))
+	assert.Equal("", stdout)
+	assert.Contains(stderr, "Command usage:")
 }
 
 func TestNested_Defaults(t *testing.T) {
@@ -442,12 +522,17 @@ func TestUsage_ShowInternalFlags(t *testing.T) {
 
 	assert.Equal("", stderr)
 	assert.Equal(`Command usage:
+      --completion string                   Print a shell completion script for bash, zsh or fish and exit
       --cool_file configFile                Configuration file
+      --explain string                      Print everything known about the given option and exit
   -h, --help                                show help and exit
+      --help_json                           Print configuration options as JSON and exit
+      --init string                         Interactively create a YAML config file at the given path and exit
       --my_enum string                      My enum (a|b|c) (default "a")
       --my_map stringToString               Map of strings (default [])
       --name_age_map stringToInt            Map of ages (default [])
       --os_sub_foo_string string            Something (default "here")
+      --print_changed                       Print options that differ from their defaults and exit
       --print_env_template                  Print example environment variables and exit
       --print_yaml_template                 Print example YAML config file and exit
       --s_slice strings                     Slice of strings (default [a,b,c])
@@ -458,6 +543,7 @@ func TestUsage_ShowInternalFlags(t *testing.T) {
   -c, --sub_no_clear_on_disconnect          Do not clear locks on client disconnect
       --sub_req_int int                     Required int
   -s, --sub_state_file string               File in which to store lock state
+      --validate_config                     Load and validate the configuration, print the result and exit
 
 `, stdout)
 }
@@ -652,3 +738,221 @@ func TestNilPtrs_False(t *testing.T) {
 	assert.Equal(t, []string{"a", "b", "c"}, *conf.PStringsDef)
 
 }
+
+func TestConfigureContext(t *testing.T) {
+	assert := assert.New(t)
+
+	c := co.ConfigureContext[TestConfigFileStruct](context.Background(), &co.Options{
+		NoRecover: true,
+		Args:      []string{"--foo_int", "3"},
+	})
+	assert.Equal(uint32(3), c.FooInt)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.PanicsWithValue("configuration canceled: context canceled", func() {
+		co.ConfigureContext[TestConfigFileStruct](ctx, &co.Options{
+			NoRecover: true,
+			Args:      []string{"--foo_int", "3"},
+		})
+	})
+}
+
+func TestMapFlags_Repeated(t *testing.T) {
+	type Conf struct {
+		Labels map[string]string `default:"x=y"`
+		Ages   map[string]int
+	}
+	assert := assert.New(t)
+
+	c := co.Configure[Conf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--labels", "k1=v1", "--labels", "k2=v2,k3=v3", "--ages", "a=1", "--ages", "b=2"},
+	})
+	assert.Equal(map[string]string{"k1": "v1", "k2": "v2", "k3": "v3"}, c.Labels)
+	assert.Equal(map[string]int{"a": 1, "b": 2}, c.Ages)
+
+	t.Setenv("MAPS_LABELS", "k1=a,b;k2=c")
+	t.Setenv("MAPS_AGES", "a=1;b=2")
+	c = co.Configure[Conf](&co.Options{
+		NoRecover:       true,
+		Args:            []string{},
+		EnvPrefix:       "MAPS_",
+		EnvMapSeparator: ";",
+	})
+	assert.Equal(map[string]string{"k1": "a,b", "k2": "c"}, c.Labels)
+	assert.Equal(map[string]int{"a": 1, "b": 2}, c.Ages)
+}
+
+func TestEnviron(t *testing.T) {
+	type Conf struct {
+		Host string `default:"localhost"`
+		Port int    `default:"80"`
+	}
+	assert := assert.New(t)
+
+	// The process environment is ignored when Environ is set
+	t.Setenv("ENVIRON_HOST", "from-process")
+	c := co.Configure[Conf](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+		EnvPrefix: "ENVIRON_",
+		Environ:   map[string]string{"ENVIRON_PORT": "8080"},
+	})
+	assert.Equal("localhost", c.Host)
+	assert.Equal(8080, c.Port)
+}
+
+func TestExit(t *testing.T) {
+	type Conf struct {
+		Port int `default:"80"`
+	}
+	assert := assert.New(t)
+
+	var code int
+	exit := func(c int) { code = c }
+	exitCode := func(fn func()) (exitErr *co.ExitError) {
+		defer func() {
+			exitErr, _ = recover().(*co.ExitError)
+		}()
+		fn()
+		return nil
+	}
+	out := &strings.Builder{}
+
+	assert.Equal(&co.ExitError{Code: 0}, exitCode(func() {
+		co.Configure[Conf](&co.Options{
+			Args:   []string{"--help"},
+			Exit:   exit,
+			Output: out,
+		})
+	}))
+	assert.Equal(0, code)
+	assert.Contains(out.String(), "--port")
+
+	errOut := &strings.Builder{}
+	assert.Equal(&co.ExitError{Code: 1}, exitCode(func() {
+		co.Configure[Conf](&co.Options{
+			Args:      []string{},
+			EnvPrefix: "EXIT_",
+			Environ:   map[string]string{"EXIT_PORT": "x"},
+			Exit:      exit,
+			ErrOutput: errOut,
+		})
+	}))
+	assert.Equal(1, code)
+	assert.Contains(errOut.String(), "invalid value \"x\" for environment variable EXIT_PORT")
+
+	assert.Equal(&co.ExitError{Code: 2}, exitCode(func() {
+		co.Configure[Conf](&co.Options{
+			Args:      []string{"--nope"},
+			Usage:     func(*pflag.FlagSet) {},
+			Exit:      exit,
+			Output:    out,
+			ErrOutput: errOut,
+		})
+	}))
+	assert.Equal(2, code)
+}
+
+func TestDisableFlags(t *testing.T) {
+	type Conf struct {
+		Host string `default:"localhost" short:"o"`
+		Port int    `default:"80"`
+	}
+	assert := assert.New(t)
+
+	c := co.Configure[Conf](&co.Options{
+		NoRecover:    true,
+		Args:         []string{},
+		EnvPrefix:    "DISABLE_",
+		Environ:      map[string]string{"DISABLE_PORT": "8080"},
+		DisableFlags: true,
+	})
+	assert.Equal(8080, c.Port)
+
+	out := &strings.Builder{}
+	errOut := &strings.Builder{}
+	exit := func(int) {}
+	assert.Panics(func() {
+		co.Configure[Conf](&co.Options{
+			Args:         []string{"--port", "8080"},
+			DisableFlags: true,
+			Usage:        func(*pflag.FlagSet) {},
+			Exit:         exit,
+			Output:       out,
+			ErrOutput:    errOut,
+		})
+	})
+	assert.Contains(errOut.String(), "unknown flag: --port")
+
+	out.Reset()
+	assert.Panics(func() {
+		co.Configure[Conf](&co.Options{
+			Args:         []string{"--help"},
+			DisableFlags: true,
+			Exit:         exit,
+			Output:       out,
+		})
+	})
+	assert.Equal("Command usage:\n  -h, --help   show help and exit\n\n", out.String())
+
+	// Templates still include the fields
+	out.Reset()
+	assert.Panics(func() {
+		co.Configure[Conf](&co.Options{
+			Args:         []string{"--print_env_template"},
+			EnvPrefix:    "DISABLE_",
+			DisableFlags: true,
+			Exit:         exit,
+			Output:       out,
+		})
+	})
+	assert.Contains(out.String(), `DISABLE_PORT="80"`)
+
+	fields := co.Fields[Conf](&co.Options{DisableFlags: true})
+	assert.Equal("", fields[0].Short)
+}
+
+func TestEnvNestedDelimiter(t *testing.T) {
+	type Conf struct {
+		FooInt int `default:"1"`
+		Sub    struct {
+			FooInt int `default:"2"`
+			Deeper struct {
+				LockTimeout int `default:"3"`
+			}
+		}
+	}
+	assert := assert.New(t)
+
+	t.Setenv("NESTED_FOO_INT", "10")
+	t.Setenv("NESTED_SUB__FOO_INT", "20")
+	t.Setenv("NESTED_SUB__DEEPER__LOCK_TIMEOUT", "30")
+	t.Setenv("NESTED_SUB_FOO_INT", "99")
+	c := co.Configure[Conf](&co.Options{
+		NoRecover:          true,
+		Args:               []string{},
+		EnvPrefix:          "NESTED_",
+		EnvNestedDelimiter: "__",
+	})
+	assert.Equal(10, c.FooInt)
+	assert.Equal(20, c.Sub.FooInt)
+	assert.Equal(30, c.Sub.Deeper.LockTimeout)
+
+	fields := co.Fields[Conf](&co.Options{EnvPrefix: "NESTED_", EnvNestedDelimiter: "__"})
+	assert.Equal("NESTED_SUB__DEEPER__LOCK_TIMEOUT", fields[2].EnvVar)
+
+	out := &strings.Builder{}
+	assert.Panics(func() {
+		co.Configure[Conf](&co.Options{
+			Args:               []string{"--print_env_template"},
+			EnvPrefix:          "NESTED_",
+			EnvNestedDelimiter: "__",
+			EnvTemplateBare:    true,
+			Output:             out,
+			Exit:               func(int) {},
+		})
+	})
+	assert.Equal("NESTED_FOO_INT=\"10\"\nNESTED_SUB__DEEPER__LOCK_TIMEOUT=\"30\"\nNESTED_SUB__FOO_INT=\"20\"\n", out.String())
+}
@@ -0,0 +1,227 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !configurature_core
+
+package configurature_test
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestConfigFile_FromEnv(t *testing.T) {
+	assert := assert.New(t)
+
+	tmp, _ := os.CreateTemp("", "cfgr-test-*.yml")
+	defer os.Remove(tmp.Name())
+	tmp.Write([]byte("foo_int: 4\nsub_foo_string: 'yes'\nkeepalive_timeout: 3m\nbool: true\n"))
+	tmp.Close()
+
+	os.Setenv("TEST_CONF_COOL_FILE", tmp.Name())
+	defer os.Setenv("TEST_CONF_COOL_FILE", "")
+
+	c := co.Configure[TestConfigFileStruct](&co.Options{
+		NoRecover: true,
+		EnvPrefix: "TEST_CONF_",
+	})
+
+	assert.Equal(uint32(4), c.FooInt, "FooInt should be 4")
+	assert.Equal("yes", c.SubFooString, "SubFooString should be 'yes'")
+	assert.Equal(time.Duration(3)*time.Minute, c.KeepaliveTimeout, "KeepaliveTimeout should be 3m")
+	assert.Equal(true, c.Bool, "Bool should be true")
+}
+
+func TestConfigFile_Precedence(t *testing.T) {
+	assert := assert.New(t)
+
+	os.Setenv("TEST_CONF_FOO_INT", "7")
+	os.Setenv("TEST_CONF_SUB_FOO_STRING", "asdf")
+
+	tmp, _ := os.CreateTemp("", "cfgr-test-*.yml")
+	defer os.Remove(tmp.Name())
+	tmp.Write([]byte("foo_int: 4\nsub_foo_string: 'yes'\nkeepalive_timeout: 3m\nbool: false\n"))
+	tmp.Close()
+
+	c := co.Configure[TestConfigFileStruct](&co.Options{
+		NoRecover: true,
+		EnvPrefix: "TEST_CONF_",
+		Args:      []string{"--cool_file", tmp.Name(), "--foo_int", "22"},
+	})
+	assert.Equal(uint32(22), c.FooInt, "FooInt should be 22")
+	assert.Equal("asdf", c.SubFooString, "SubFooString should be 'asdf'")
+	assert.Equal(time.Duration(3)*time.Minute, c.KeepaliveTimeout, "KeepaliveTimeout should be 3m")
+	assert.Equal(false, c.Bool, "Bool should be false")
+
+}
+
+func TestBadValues_AllSources(t *testing.T) {
+	type Conf struct {
+		Conf  co.ConfigFile
+		Port  co.Port
+		Count int
+		Level slog.Level
+	}
+	confFile := t.TempDir() + "/conf.yaml"
+	os.WriteFile(confFile, []byte("port: 99999\nlevel: loud\nunknown: 1\n"), 0644)
+	t.Setenv("ALL_SRC_COUNT", "many")
+
+	assert.PanicsWithValue(t, `unknown configuration file field: unknown, `+
+		`invalid value "many" for environment variable ALL_SRC_COUNT (int): `+
+		`strconv.ParseInt: parsing "many": invalid syntax, `+
+		`unable to set value for level: invalid Level: "loud", `+
+		`unable to set value for port: invalid port "99999": must be a number between 1 and 65535`, func() {
+		co.Configure[Conf](&co.Options{
+			NoRecover: true,
+			EnvPrefix: "ALL_SRC_",
+			Args:      []string{"--conf", confFile},
+		})
+	})
+}
+
+func TestOutput(t *testing.T) {
+	if os.Getenv("TEST_PASSTHROUGH") == "1" {
+		out, _ := os.Create(os.Getenv("TEST_OUTPUT_FILE"))
+		co.Configure[TestConfigFileStruct](&co.Options{
+			Args:      strings.Split(os.Getenv("TEST_OUTPUT_ARGS"), " "),
+			EnvPrefix: "TEST_CONF_",
+			Output:    out,
+			ErrOutput: os.Stdout,
+		})
+		os.Exit(0)
+	}
+
+	assert := assert.New(t)
+	outFile := t.TempDir() + "/out"
+	t.Setenv("TEST_OUTPUT_FILE", outFile)
+	output := func() string {
+		b, _ := os.ReadFile(outFile)
+		return string(b)
+	}
+
+	t.Setenv("TEST_OUTPUT_ARGS", "--print_env_template")
+	stdout, _ := runExternal(t)
+	assert.Equal("", stdout)
+	assert.Contains(output(), `TEST_CONF_FOO_INT="7"`)
+
+	t.Setenv("TEST_OUTPUT_ARGS", "-h")
+	stdout, _ = runExternal(t)
+	assert.Equal("", stdout)
+	assert.Contains(output(), "Command usage:")
+
+	// Errors are printed to ErrOutput
+	t.Setenv("TEST_OUTPUT_ARGS", "--foo_int asdf")
+	stdout, stderr := runExternal(t)
+	assert.Contains(stdout, `invalid argument "asdf" for "-o, --foo_int" flag`)
+	assert.Equal("", stderr)
+
+	t.Setenv("TEST_OUTPUT_ARGS", "--cool_file /does/not/exist.yaml")
+	stdout, stderr = runExternal(t)
+	assert.Contains(stdout, "error parsing configuration:")
+	assert.Equal("", stderr)
+}
+
+func TestDisableEnv(t *testing.T) {
+	type Conf struct {
+		Conf co.ConfigFile
+		Port int `default:"80"`
+	}
+	assert := assert.New(t)
+
+	confFile := t.TempDir() + "/conf.yaml"
+	os.WriteFile(confFile, []byte("port: 443\n"), 0644)
+	t.Setenv("DISABLE_PORT", "8080")
+	t.Setenv("DISABLE_CONF", confFile)
+
+	c := co.Configure[Conf](&co.Options{
+		NoRecover:  true,
+		Args:       []string{},
+		EnvPrefix:  "DISABLE_",
+		DisableEnv: true,
+	})
+	assert.Equal(80, c.Port)
+
+	c = co.Configure[Conf](&co.Options{
+		NoRecover:  true,
+		Args:       []string{"--conf", confFile},
+		EnvPrefix:  "DISABLE_",
+		DisableEnv: true,
+	})
+	assert.Equal(443, c.Port)
+
+	errOut := &strings.Builder{}
+	assert.Panics(func() {
+		co.Configure[Conf](&co.Options{
+			Args:       []string{"--print_env_template"},
+			EnvPrefix:  "DISABLE_",
+			DisableEnv: true,
+			Usage:      func(*pflag.FlagSet) {},
+			Exit:       func(int) {},
+			Output:     &strings.Builder{},
+			ErrOutput:  errOut,
+		})
+	})
+	assert.Contains(errOut.String(), "unknown flag: --print_env_template")
+
+	fields := co.Fields[Conf](&co.Options{EnvPrefix: "DISABLE_", DisableEnv: true})
+	assert.Equal("", fields[1].EnvVar)
+}
+
+func TestEnvMapEntries(t *testing.T) {
+	type Conf struct {
+		Conf   co.ConfigFile
+		Labels map[string]string `default:"x=y"`
+		Ages   map[string]int
+	}
+	assert := assert.New(t)
+
+	t.Setenv("ENTRIES_LABELS__TEAM", "core,platform")
+	t.Setenv("ENTRIES_AGES__BOB", "42")
+	c := co.Configure[Conf](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+		EnvPrefix: "ENTRIES_",
+	})
+	assert.Equal(map[string]string{"team": "core,platform"}, c.Labels)
+	assert.Equal(map[string]int{"bob": 42}, c.Ages)
+
+	// Entries are merged with the config file and the whole map variable
+	confFile := t.TempDir() + "/conf.yaml"
+	os.WriteFile(confFile, []byte("labels:\n  env: prod\n  team: none\nages:\n  alice: 30\n"), 0644)
+	t.Setenv("ENTRIES_AGES", "carol=50")
+	c = co.Configure[Conf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--conf", confFile},
+		EnvPrefix: "ENTRIES_",
+	})
+	assert.Equal(map[string]string{"env": "prod", "team": "core,platform"}, c.Labels)
+	assert.Equal(map[string]int{"bob": 42, "carol": 50}, c.Ages)
+
+	t.Setenv("ENTRIES_AGES__DAVE", "old")
+	assert.PanicsWithValue(`invalid value "old" for environment variable ENTRIES_AGES__DAVE (stringToInt): strconv.Atoi: parsing "old": invalid syntax`, func() {
+		co.Configure[Conf](&co.Options{
+			NoRecover: true,
+			Args:      []string{},
+			EnvPrefix: "ENTRIES_",
+		})
+	})
+}
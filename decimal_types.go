@@ -0,0 +1,167 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Value interface implementations for the Decimal and
+Money types which are used to specify exact fixed-point values, such as
+prices, on a configurature struct
+*/
+package configurature
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Type representing an exact decimal number, e.g. "19.99", "-0.005" or
+// "1,250,000.00". Commas and underscores may be used to group digits. Up to
+// 18 significant digits are supported.
+type Decimal struct {
+	coef  int64 // The value without its decimal point
+	scale int   // Number of digits after the decimal point
+}
+
+// parseDecimal parses a decimal number
+func parseDecimal(v string) (Decimal, error) {
+	s := strings.NewReplacer(",", "", "_", "").Replace(strings.TrimSpace(v))
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+
+	whole, frac, _ := strings.Cut(s, ".")
+	digits := whole + frac
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Decimal{}, fmt.Errorf("invalid decimal \"%s\"", v)
+	}
+	coef, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || len(strings.TrimLeft(digits, "0")) > 18 {
+		return Decimal{}, fmt.Errorf("invalid decimal \"%s\": more than 18 significant digits", v)
+	}
+	if neg {
+		coef = -coef
+	}
+	return Decimal{coef: coef, scale: len(frac)}, nil
+}
+
+func (d *Decimal) String() string {
+	s := strconv.FormatInt(d.coef, 10)
+	if d.scale == 0 {
+		return s
+	}
+	sign := ""
+	if d.coef < 0 {
+		sign, s = "-", s[1:]
+	}
+	if len(s) <= d.scale {
+		s = strings.Repeat("0", d.scale-len(s)+1) + s
+	}
+	return sign + s[:len(s)-d.scale] + "." + s[len(s)-d.scale:]
+}
+
+func (d *Decimal) Set(v string) error {
+	dec, err := parseDecimal(v)
+	if err != nil {
+		return err
+	}
+	*d = dec
+	return nil
+}
+
+func (d *Decimal) Type() string {
+	return "decimal"
+}
+
+// Units returns the value in units of 10^-scale, e.g. cents for a scale of 2,
+// and false if that would lose precision or overflow
+func (d Decimal) Units(scale int) (int64, bool) {
+	r := new(big.Rat).SetFrac(big.NewInt(d.coef), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.scale)), nil))
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)))
+	if !r.IsInt() || !r.Num().IsInt64() {
+		return 0, false
+	}
+	return r.Num().Int64(), true
+}
+
+// Cmp returns -1, 0 or +1 if d is less than, equal to or greater than other
+func (d Decimal) Cmp(other Decimal) int {
+	return d.rat().Cmp(other.rat())
+}
+
+// rat returns d as a big.Rat
+func (d Decimal) rat() *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(d.coef), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.scale)), nil))
+}
+
+// Type representing an amount of money in a currency, e.g. "19.99 USD" or
+// "EUR 5". The currency is a three letter ISO 4217 code, which is normalized
+// to upper case.
+type Money struct {
+	amount   Decimal
+	currency string
+}
+
+func (m *Money) String() string {
+	if m.currency == "" {
+		return ""
+	}
+	return m.amount.String() + " " + m.currency
+}
+
+func (m *Money) Set(v string) error {
+	parts := strings.Fields(v)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid money \"%s\": must be <amount> <currency>", v)
+	}
+	amount, currency := parts[0], parts[1]
+	if isCurrencyCode(amount) && !isCurrencyCode(currency) {
+		amount, currency = currency, amount
+	}
+	if !isCurrencyCode(currency) {
+		return fmt.Errorf("invalid money \"%s\": currency must be a three letter code", v)
+	}
+	d, err := parseDecimal(amount)
+	if err != nil {
+		return fmt.Errorf("invalid money \"%s\": %w", v, err)
+	}
+	*m = Money{amount: d, currency: strings.ToUpper(currency)}
+	return nil
+}
+
+func (m *Money) Type() string {
+	return "money"
+}
+
+// Amount returns the amount of money
+func (m Money) Amount() Decimal {
+	return m.amount
+}
+
+// Currency returns the three letter currency code, or "" if m is not set
+func (m Money) Currency() string {
+	return m.currency
+}
+
+// isCurrencyCode returns whether s is a three letter currency code
+func isCurrencyCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, c := range strings.ToUpper(s) {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestDecimalTypes(t *testing.T) {
+	type BillingConf struct {
+		TaxRate co.Decimal `default:"0.0825"`
+		Credit  co.Decimal `default:"-0.5"`
+		Price   co.Money   `default:"19.99 usd"`
+		Limit   co.Money
+	}
+	assert := assert.New(t)
+
+	c := co.Configure[BillingConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--limit", "EUR 1,250,000.00"},
+	})
+
+	assert.Equal("0.0825", c.TaxRate.String())
+	assert.Equal("-0.5", c.Credit.String())
+	assert.Equal("19.99 USD", c.Price.String())
+	assert.Equal("USD", c.Price.Currency())
+	assert.Equal("1250000.00 EUR", c.Limit.String())
+
+	cents, ok := c.Price.Amount().Units(2)
+	assert.True(ok)
+	assert.Equal(int64(1999), cents)
+	_, ok = c.TaxRate.Units(2)
+	assert.False(ok)
+	units, ok := c.Credit.Units(3)
+	assert.True(ok)
+	assert.Equal(int64(-500), units)
+
+	assert.Equal(1, c.Limit.Amount().Cmp(c.Price.Amount()))
+	assert.Equal(-1, c.Credit.Cmp(c.TaxRate))
+	assert.Equal(0, c.Price.Amount().Cmp(mustDecimal("19.990")))
+}
+
+func TestDecimalTypes_Invalid(t *testing.T) {
+	assert := assert.New(t)
+	d := new(co.Decimal)
+	m := new(co.Money)
+
+	assert.EqualError(d.Set("1.2.3"), `invalid decimal "1.2.3"`)
+	assert.EqualError(d.Set("1e5"), `invalid decimal "1e5"`)
+	assert.EqualError(d.Set("1234567890.1234567890"), `invalid decimal "1234567890.1234567890": more than 18 significant digits`)
+	assert.EqualError(m.Set("19.99"), `invalid money "19.99": must be <amount> <currency>`)
+	assert.EqualError(m.Set("19.99 dollars"), `invalid money "19.99 dollars": currency must be a three letter code`)
+	assert.EqualError(m.Set("abc USD"), `invalid money "abc USD": invalid decimal "abc"`)
+}
+
+func mustDecimal(s string) co.Decimal {
+	var d co.Decimal
+	if err := d.Set(s); err != nil {
+		panic(err)
+	}
+	return d
+}
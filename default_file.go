@@ -0,0 +1,88 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the handling of the defaultFile tag, which reads the
+default value of a field from a file
+*/
+package configurature
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// withDefaultFile returns tags with a default tag holding the contents of the
+// file named by the defaultFile tag of field name, if it has one. Files are
+// read once per configurer.
+func (c *configurer) withDefaultFile(name string, tags reflect.StructTag) reflect.StructTag {
+	file, ok := tags.Lookup("defaultFile")
+	if !ok {
+		return tags
+	}
+	if c.defaultFiles == nil {
+		c.defaultFiles = map[string]string{}
+	}
+	def, ok := c.defaultFiles[file]
+	if !ok {
+		def = readDefaultFile(name, file, tags)
+		c.defaultFiles[file] = def
+	}
+	return tags + reflect.StructTag(" default:"+strconv.Quote(def))
+}
+
+// readDefaultFile returns the contents of the file named by the defaultFile
+// tag of field name
+func readDefaultFile(name string, file string, tags reflect.StructTag) string {
+	if _, ok := tags.Lookup("default"); ok {
+		panic(fmt.Sprintf("field %s has both default and defaultFile tags", name))
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		panic(fmt.Sprintf("error reading default value of field %s: %v", name, err))
+	}
+	return string(data)
+}
+
+// setGeneratedDefaultFile sets the default value of the named flag, added by
+// generated code, to the contents of the file named by its defaultFile tag
+func setGeneratedDefaultFile(fs *pflag.FlagSet, name string, tags reflect.StructTag) {
+	fl := fs.Lookup(name)
+	def := readDefaultFile(name, tags.Get("defaultFile"), tags)
+
+	// Replace the elements of pflag slices, which append to their values
+	// after the first Set()
+	var err error
+	if sv, ok := fl.Value.(pflag.SliceValue); ok {
+		var elems []string
+		if elems, err = csv.NewReader(strings.NewReader(def)).Read(); err == nil {
+			err = sv.Replace(elems)
+		}
+	} else {
+		err = fl.Value.Set(def)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("Error setting default value for field %s: %s", name, err))
+	}
+	if d, ok := fl.Value.(defaultMarker); ok {
+		d.markDefault()
+	}
+	fl.DefValue = fl.Value.String()
+}
@@ -0,0 +1,93 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+// chdirTemp changes to a temporary directory for the rest of the test so that
+// relative defaultFile tags can be used
+func chdirTemp(t *testing.T) {
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+type DefaultFileConf struct {
+	Banner string   `defaultFile:"banner.txt"`
+	Hosts  []string `defaultFile:"hosts.txt"`
+}
+
+// RegisterFlags is written as configurature-gen would generate it
+func (c *DefaultFileConf) RegisterFlags(fs *pflag.FlagSet) {
+	fs.StringVarP(&c.Banner, "banner", "", "", "banner")
+	co.AnnotateGeneratedFlag(fs, "banner", `defaultFile:"banner.txt"`)
+	fs.StringSliceVarP(&c.Hosts, "hosts", "", nil, "hosts")
+	co.AnnotateGeneratedFlag(fs, "hosts", `defaultFile:"hosts.txt"`)
+}
+
+func TestDefaultFile(t *testing.T) {
+	assert := assert.New(t)
+	chdirTemp(t)
+	os.WriteFile("banner.txt", []byte("Welcome!\nBe nice.\n"), 0600)
+	os.WriteFile("hosts.txt", []byte("a,b"), 0600)
+
+	c := co.Configure[DefaultFileConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+	})
+	assert.Equal("Welcome!\nBe nice.\n", c.Banner)
+	assert.Equal([]string{"a", "b"}, c.Hosts)
+
+	c = co.Configure[DefaultFileConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--banner", "hi", "--hosts", "c"},
+	})
+	assert.Equal("hi", c.Banner)
+	assert.Equal([]string{"c"}, c.Hosts)
+
+	c = co.ConfigureGenerated[DefaultFileConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--hosts", "c"},
+	})
+	assert.Equal("Welcome!\nBe nice.\n", c.Banner)
+	assert.Equal([]string{"c"}, c.Hosts)
+}
+
+func TestDefaultFile_Errors(t *testing.T) {
+	assert := assert.New(t)
+
+	type MissingConf struct {
+		Banner string `defaultFile:"/nonexistent/banner.txt"`
+	}
+	assert.PanicsWithValue("error reading default value of field Banner: "+
+		"open /nonexistent/banner.txt: no such file or directory", func() {
+		co.Configure[MissingConf](&co.Options{NoRecover: true, Args: []string{}})
+	})
+
+	type BothConf struct {
+		Banner string `default:"hi" defaultFile:"banner.txt"`
+	}
+	assert.PanicsWithValue("field Banner has both default and defaultFile tags", func() {
+		co.Configure[BothConf](&co.Options{NoRecover: true, Args: []string{}})
+	})
+}
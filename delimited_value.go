@@ -0,0 +1,90 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the delimitedValue type used for slice fields with a delim
+tag
+*/
+package configurature
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// delimitedValue wraps the Value of a slice flag so that its elements are
+// separated by delim rather than being comma-separated
+type delimitedValue struct {
+	pflag.Value
+	delim string
+}
+
+func (d *delimitedValue) unwrap() pflag.Value {
+	return d.Value
+}
+
+// Set splits v on delim and sets the wrapped Value to the elements
+func (d *delimitedValue) Set(v string) error {
+	return d.Value.Set(delimitedToCSV(v, d.delim))
+}
+
+// String returns the elements of the wrapped Value separated by delim
+func (d *delimitedValue) String() string {
+	if sv, ok := d.Value.(pflag.SliceValue); ok {
+		return strings.Join(sv.GetSlice(), d.delim)
+	}
+	v := strings.TrimSuffix(strings.TrimPrefix(d.Value.String(), "["), "]")
+	if v == "" {
+		return ""
+	}
+	elems, err := csv.NewReader(strings.NewReader(v)).Read()
+	if err != nil {
+		return v
+	}
+	return strings.Join(elems, d.delim)
+}
+
+// delimitedToCSV converts a string of elements separated by delim to a CSV
+// record
+func delimitedToCSV(v string, delim string) string {
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	w.Write(strings.Split(v, delim))
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// setFlagDelim wraps the Value of the named flag in a delimitedValue
+func setFlagDelim(fs *pflag.FlagSet, name string, delim string) {
+	fl := fs.Lookup(name)
+	fl.Value = &delimitedValue{Value: fl.Value, delim: delim}
+	fl.DefValue = fl.Value.String()
+}
+
+// valueWrapper is implemented by Values that wrap the Value of a flag to
+// change how it is parsed
+type valueWrapper interface {
+	unwrap() pflag.Value
+}
+
+// unwrapValue returns the Value wrapped by v if it is a valueWrapper
+func unwrapValue(v pflag.Value) pflag.Value {
+	if w, ok := v.(valueWrapper); ok {
+		return w.unwrap()
+	}
+	return v
+}
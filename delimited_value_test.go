@@ -0,0 +1,96 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type DelimConfig struct {
+	Conf  co.ConfigFile
+	Urls  []string  `delim:" " default:"http://a/?x=1,2 http://b"`
+	Dns   []string  `delim:";"`
+	Ports []co.Port `delim:";"`
+}
+
+func TestDelim(t *testing.T) {
+	assert := assert.New(t)
+
+	c := co.Configure[DelimConfig](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--dns", "cn=a,dc=b;cn=c,dc=d", "--ports", "80;443"},
+	})
+	assert.Equal([]string{"http://a/?x=1,2", "http://b"}, c.Urls)
+	assert.Equal([]string{"cn=a,dc=b", "cn=c,dc=d"}, c.Dns)
+	assert.Equal([]co.Port{80, 443}, c.Ports)
+
+	t.Setenv("DELIM_DNS", "cn=e,dc=f;cn=g")
+	t.Setenv("DELIM_PORTS", "1;2")
+	c = co.Configure[DelimConfig](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+		EnvPrefix: "DELIM_",
+	})
+	assert.Equal([]string{"cn=e,dc=f", "cn=g"}, c.Dns)
+	assert.Equal([]co.Port{1, 2}, c.Ports)
+}
+
+func TestDelim_NotSlice(t *testing.T) {
+	type Conf struct {
+		Name string `delim:";"`
+	}
+	assert.PanicsWithValue(t, "delim tag on field Name: only slice fields support delim", func() {
+		co.Configure[Conf](&co.Options{NoRecover: true, Args: []string{}})
+	})
+}
+
+func TestDelim_String(t *testing.T) {
+	assert := assert.New(t)
+	configure := func(opts *co.Options) { co.Configure[DelimConfig](opts) }
+
+	_, out, _ := configureExit(t, configure, co.Options{
+		Args:            []string{"--print_env_template", "--dns", "cn=a,dc=b;cn=c", "--ports", "80;443"},
+		EnvPrefix:       "DELIM_",
+		EnvTemplateBare: true,
+	})
+	assert.Equal(`DELIM_CONF=""
+DELIM_DNS="cn=a,dc=b;cn=c"
+DELIM_PORTS="80;443"
+DELIM_URLS="http://a/?x=1,2 http://b"
+`, out)
+
+	// The printed values are read back as the same elements
+	t.Setenv("DELIM_DNS", "cn=a,dc=b;cn=c")
+	t.Setenv("DELIM_PORTS", "80;443")
+	t.Setenv("DELIM_URLS", "http://a/?x=1,2 http://b")
+	c := co.Configure[DelimConfig](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+		EnvPrefix: "DELIM_",
+	})
+	assert.Equal([]string{"cn=a,dc=b", "cn=c"}, c.Dns)
+	assert.Equal([]co.Port{80, 443}, c.Ports)
+	assert.Equal([]string{"http://a/?x=1,2", "http://b"}, c.Urls)
+
+	// Unchanged defaults aren't reported as changed
+	_, out, _ = configureExit(t, configure, co.Options{
+		Args: []string{"--print_changed"},
+	})
+	assert.Equal("", out)
+}
@@ -0,0 +1,40 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !configurature_core
+
+package configurature_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestDelim_ConfigFile(t *testing.T) {
+	assert := assert.New(t)
+
+	fileName := t.TempDir() + "/conf.yaml"
+	os.WriteFile(fileName, []byte("dns: ['cn=a,dc=b', 'cn=c']\nurls: 'http://c?y=1,2 http://d'\n"), 0644)
+
+	c := co.Configure[DelimConfig](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--conf", fileName},
+	})
+	assert.Equal([]string{"cn=a,dc=b", "cn=c"}, c.Dns)
+	assert.Equal([]string{"http://c?y=1,2", "http://d"}, c.Urls)
+}
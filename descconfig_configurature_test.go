@@ -0,0 +1,14 @@
+// Code generated by configurature-gen. DO NOT EDIT.
+
+package configurature_test
+
+import co "github.com/imoore76/configurature"
+
+func init() {
+	co.AddDescriptions[DescConfig](map[string]string{
+		"Listen": "Address to listen on. Use \":0\" for a random port.",
+	})
+	co.AddDescriptions[DescSubConfig](map[string]string{
+		"Host": "Database host name",
+	})
+}
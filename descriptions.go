@@ -0,0 +1,76 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the field description registry and its helpers
+*/
+package configurature
+
+import (
+	"maps"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+var (
+	// Registered field descriptions by struct type and Go field name
+	descriptions = make(map[reflect.Type]map[string]string)
+
+	// Protects descriptions
+	descriptionsMu sync.RWMutex
+)
+
+// AddDescriptions registers descriptions for the fields of struct type T,
+// keyed by Go field name. They are used for fields that do not have a help
+// tag. Code calling this is usually generated from doc comments by
+// configurature-gen -descriptions.
+func AddDescriptions[T any](descs map[string]string) {
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		panic("AddDescriptions: type " + t.String() + " is not a struct")
+	}
+
+	descriptionsMu.Lock()
+	if descriptions[t] == nil {
+		descriptions[t] = make(map[string]string)
+	}
+	maps.Copy(descriptions[t], descs)
+	descriptionsMu.Unlock()
+
+	// Cached fields may have been collected without these descriptions
+	clearStructFieldsCache()
+}
+
+// withDescription returns field with a help tag set to its registered
+// description if it has no help tag
+func withDescription(owner reflect.Type, field reflect.StructField) reflect.StructField {
+	if _, ok := field.Tag.Lookup("help"); ok {
+		return field
+	}
+
+	descriptionsMu.RLock()
+	desc, ok := descriptions[owner][field.Name]
+	descriptionsMu.RUnlock()
+	if !ok {
+		return field
+	}
+
+	tag := string(field.Tag)
+	if tag != "" {
+		tag += " "
+	}
+	field.Tag = reflect.StructTag(tag + "help:" + strconv.Quote(desc))
+	return field
+}
@@ -0,0 +1,77 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+//go:generate go run ./cmd/configurature-gen -type DescConfig -descriptions -output descconfig_configurature_test.go
+
+type DescSubConfig struct {
+	// Database host name
+	Host string
+}
+
+type DescConfig struct {
+	// Address to listen on.
+	// Use ":0" for a random port.
+	Listen string `default:":8080"`
+	Level  string `help:"log level"` // Ignored in favor of the help tag
+	Name   string
+	DB     DescSubConfig
+}
+
+func TestDescriptions_Generated(t *testing.T) {
+	fields := co.Fields[DescConfig](&co.Options{})
+
+	assert := assert.New(t)
+	assert.Len(fields, 4)
+	assert.Equal(`Address to listen on. Use ":0" for a random port.`, fields[0].Description)
+	assert.Equal("log level", fields[1].Description)
+	assert.Equal("name", fields[2].Description)
+	assert.Equal("Database host name", fields[3].Description)
+}
+
+func TestAddDescriptions(t *testing.T) {
+	type AConf struct {
+		Host string `required:""`
+		Port int    `help:"port to use"`
+	}
+
+	// Fields collected before registration are refreshed
+	assert.Equal(t, "host", co.Fields[AConf](&co.Options{})[0].Description)
+
+	co.AddDescriptions[AConf](map[string]string{
+		"Host": "server host",
+		"Port": "not used",
+	})
+	fields := co.Fields[AConf](&co.Options{})
+
+	assert := assert.New(t)
+	assert.Equal("server host", fields[0].Description)
+	assert.True(fields[0].Required)
+	assert.Equal("port to use", fields[1].Description)
+}
+
+func TestAddDescriptions_NotStruct(t *testing.T) {
+	assert.PanicsWithValue(t, "AddDescriptions: type string is not a struct", func() {
+		co.AddDescriptions[string](nil)
+	})
+}
@@ -0,0 +1,50 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the --validate_config flag and Options.DryRun, which load
+and validate the configuration, report the result and exit
+*/
+package configurature
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+const validateConfigFlag = "validate_config"
+
+// isDryRun returns true if the configuration should only be validated
+func isDryRun(fs *pflag.FlagSet, opts *Options) bool {
+	ok, _ := fs.GetBool(validateConfigFlag)
+	return ok || opts.DryRun
+}
+
+// dryRun calls validate, prints "configuration OK" or the validation errors
+// and exits
+func dryRun(validate func(), opts *Options) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(*ExitError); ok {
+				panic(r)
+			}
+			fmt.Fprintf(opts.errOutput(), "configuration invalid: %s\n", r)
+			opts.exit(1)
+		}
+	}()
+	validate()
+	fmt.Fprintln(opts.output(), "configuration OK")
+	opts.exit(0)
+}
@@ -0,0 +1,115 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type DryRunConf struct {
+	Host  string `required:""`
+	Level string `enum:"debug,info" default:"info"`
+}
+
+// configureExit runs configure with opts and returns the exit code and output
+func configureExit(t *testing.T, configure func(*co.Options), opts co.Options) (code int, out string, errOut string) {
+	t.Helper()
+	o, e := &strings.Builder{}, &strings.Builder{}
+	opts.Output = o
+	opts.ErrOutput = e
+	opts.Exit = func(int) {}
+	func() {
+		defer func() {
+			r := recover()
+			exitErr, ok := r.(*co.ExitError)
+			if !assert.True(t, ok, "expected *ExitError, got %v", r) {
+				return
+			}
+			code = exitErr.Code
+		}()
+		configure(&opts)
+	}()
+	return code, o.String(), e.String()
+}
+
+func TestDryRun(t *testing.T) {
+	assert := assert.New(t)
+	configure := func(opts *co.Options) { co.Configure[DryRunConf](opts) }
+
+	code, out, errOut := configureExit(t, configure, co.Options{
+		Args: []string{"--validate_config", "--host", "example.com"},
+	})
+	assert.Equal(0, code)
+	assert.Equal("configuration OK\n", out)
+	assert.Equal("", errOut)
+
+	code, out, errOut = configureExit(t, configure, co.Options{
+		Args: []string{"--validate_config", "--level", "warn"},
+	})
+	assert.Equal(1, code)
+	assert.Equal("", out)
+	assert.Equal("configuration invalid: host is required, level must be one of debug, info\n", errOut)
+
+	// Options.DryRun
+	code, out, _ = configureExit(t, configure, co.Options{
+		DryRun: true,
+		Args:   []string{"--host", "example.com"},
+	})
+	assert.Equal(0, code)
+	assert.Equal("configuration OK\n", out)
+
+	// Errors parsing the configuration are reported as usual
+	code, _, errOut = configureExit(t, configure, co.Options{
+		DryRun: true,
+		Args:   []string{"--nope"},
+		Usage:  func(*pflag.FlagSet) {},
+	})
+	assert.Equal(2, code)
+	assert.Contains(errOut, "unknown flag: --nope")
+
+	// Prompting is skipped
+	p := &testPrompter{answers: []string{"example.com"}}
+	code, _, errOut = configureExit(t, configure, co.Options{
+		DryRun:   true,
+		Prompt:   true,
+		Prompter: p,
+	})
+	assert.Equal(1, code)
+	assert.Empty(p.labels)
+	assert.Equal("configuration invalid: host is required\n", errOut)
+}
+
+func TestDryRun_Generated(t *testing.T) {
+	assert := assert.New(t)
+	configure := func(opts *co.Options) { co.ConfigureGenerated[GenConfig](opts) }
+
+	code, out, _ := configureExit(t, configure, co.Options{
+		Args: []string{"--validate_config", "--db_host", "localhost"},
+	})
+	assert.Equal(0, code)
+	assert.Equal("configuration OK\n", out)
+
+	code, _, errOut := configureExit(t, configure, co.Options{
+		Args: []string{"--validate_config"},
+	})
+	assert.Equal(1, code)
+	assert.Equal("configuration invalid: db_host is required\n", errOut)
+}
@@ -0,0 +1,82 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Value interface implementation for the EndpointList
+type which is used to specify weighted upstream endpoints on a configurature
+struct
+*/
+package configurature
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Endpoint is a "host:port" address and its weight
+type Endpoint struct {
+	Address HostPort
+	Weight  int
+}
+
+// Type representing a list of comma separated weighted endpoints. E.g.
+// "a:8080=3,b:8080=1". The weight of an endpoint without one is 1.
+type EndpointList []Endpoint
+
+func (e *EndpointList) String() string {
+	eps := make([]string, len(*e))
+	for i, ep := range *e {
+		eps[i] = fmt.Sprintf("%s=%d", ep.Address, ep.Weight)
+	}
+	return strings.Join(eps, ",")
+}
+
+func (e *EndpointList) Set(v string) error {
+	list := EndpointList{}
+	for _, s := range strings.Split(v, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		ep := Endpoint{Weight: 1}
+		addr, weight, hasWeight := strings.Cut(s, "=")
+		if hasWeight {
+			w, err := strconv.Atoi(weight)
+			if err != nil || w < 1 {
+				return fmt.Errorf("invalid endpoint \"%s\": weight must be a positive integer", s)
+			}
+			ep.Weight = w
+		}
+		if err := ep.Address.Set(addr); err != nil {
+			return fmt.Errorf("invalid endpoint \"%s\": %w", s, err)
+		}
+		list = append(list, ep)
+	}
+	*e = list
+	return nil
+}
+
+func (e *EndpointList) Type() string {
+	return "endpoints"
+}
+
+// TotalWeight returns the sum of the weights of the endpoints in the list
+func (e EndpointList) TotalWeight() int {
+	total := 0
+	for _, ep := range e {
+		total += ep.Weight
+	}
+	return total
+}
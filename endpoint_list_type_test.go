@@ -0,0 +1,54 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestEndpointList(t *testing.T) {
+	type EPConf struct {
+		Upstreams co.EndpointList `default:"a:8080=3,b:8080"`
+		Backups   co.EndpointList
+	}
+	assert := assert.New(t)
+
+	c := co.Configure[EPConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--backups", "[::1]:9000=2, c:80=5"},
+	})
+
+	assert.Equal(co.EndpointList{{Address: "a:8080", Weight: 3}, {Address: "b:8080", Weight: 1}}, c.Upstreams)
+	assert.Equal("a:8080=3,b:8080=1", c.Upstreams.String())
+	assert.Equal(4, c.Upstreams.TotalWeight())
+	assert.Equal("::1", c.Backups[0].Address.Host())
+	port, err := c.Backups[1].Address.Port()
+	assert.NoError(err)
+	assert.Equal(co.ListenPort(80), port)
+	assert.Equal(7, c.Backups.TotalWeight())
+}
+
+func TestEndpointList_Invalid(t *testing.T) {
+	assert := assert.New(t)
+	e := new(co.EndpointList)
+
+	assert.EqualError(e.Set("a:80=0"), `invalid endpoint "a:80=0": weight must be a positive integer`)
+	assert.EqualError(e.Set("a:80=x"), `invalid endpoint "a:80=x": weight must be a positive integer`)
+	assert.EqualError(e.Set("a=2"), `invalid endpoint "a=2": invalid host:port "a": address a: missing port in address`)
+}
@@ -0,0 +1,104 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the --explain flag, which prints everything known about a
+single option
+*/
+package configurature
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+const explainFlag = "explain"
+
+// explain prints the description, type, default, current value, source,
+// environment variable, config file key and validation rules of the option
+// with the given flag name
+func (c *configurer) explain(name string, fs *pflag.FlagSet) {
+	name = strings.TrimLeft(name, "-")
+
+	var fi *FieldInfo
+	for _, info := range c.fieldInfos(fs) {
+		if info.Name == name {
+			fi = &info
+			break
+		}
+	}
+	if fi == nil {
+		panic(fmt.Sprintf("unknown option: %s", name))
+	}
+	fl := fs.Lookup(name)
+
+	out := c.opts.output()
+	line := func(label string, value string) {
+		fmt.Fprintf(out, "  %-13s%s\n", label+":", value)
+	}
+
+	fmt.Fprintln(out, fi.Name)
+	line("Description", fi.Description)
+	line("Type", fi.Type)
+	if fi.Short != "" {
+		line("Short flag", "-"+fi.Short)
+	}
+
+	def := "none"
+	if fi.HasDefault {
+		def = fi.Default
+		if isSecret(fl) && def != "" {
+			def = redactedValue
+		}
+	}
+	line("Default", def)
+
+	line("Value", redactedFlagValue(fl))
+
+	source, ok := c.sources[name]
+	if !ok {
+		source = "default"
+	}
+	line("Source", source)
+
+	// Environment variables are only read with a prefix
+	if fi.EnvVar != "" && c.opts.EnvPrefix != "" {
+		line("Env var", fi.EnvVar)
+	}
+	if !slices.Contains(c.configFileFlags, name) {
+		keys := []string{}
+		for _, a := range fi.Ancestors {
+			keys = append(keys, c.fileKeyFromConfigName(a))
+		}
+		keys = append(keys, c.fileKeyFromConfigName(stripAncestors(name, fi.Ancestors)))
+		line("File key", strings.Join(keys, "."))
+	}
+
+	rules := []string{}
+	if len(fi.Enum) > 0 {
+		line("Enum", strings.Join(fi.Enum, ", "))
+		rules = append(rules, "must be one of "+strings.Join(fi.Enum, ", "))
+	} else if fi.Required {
+		rules = append(rules, "required")
+	}
+	if isSecret(fl) {
+		rules = append(rules, "secret")
+	}
+	if len(rules) > 0 {
+		line("Validation", strings.Join(rules, ", "))
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type ExplainConf struct {
+	Conf     co.ConfigFile `help:"Configuration file"`
+	Level    string        `help:"log level" enum:"debug,info" default:"info"`
+	Password string        `secret:"" required:""`
+	DB       struct {
+		Port int `help:"db port" default:"5432" short:"p"`
+	}
+}
+
+func TestExplain(t *testing.T) {
+	assert := assert.New(t)
+	configure := func(opts *co.Options) { co.Configure[ExplainConf](opts) }
+
+	code, out, _ := configureExit(t, configure, co.Options{
+		EnvPrefix:    "EXPLAIN_",
+		Environ:      map[string]string{"EXPLAIN_DB_PORT": "6543"},
+		FileKeyStyle: co.CamelKeys,
+		Args:         []string{"--explain", "db_port"},
+	})
+	assert.Equal(0, code)
+	assert.Equal(`db_port
+  Description: db port
+  Type:        int
+  Short flag:  -p
+  Default:     5432
+  Value:       6543
+  Source:      env
+  Env var:     EXPLAIN_DB_PORT
+  File key:    db.port
+`, out)
+
+	_, out, _ = configureExit(t, configure, co.Options{
+		Args: []string{"--explain", "--level"},
+	})
+	assert.Equal(`level
+  Description: log level
+  Type:        string
+  Default:     info
+  Value:       info
+  Source:      default
+  File key:    level
+  Enum:        debug, info
+  Validation:  must be one of debug, info
+`, out)
+
+	_, out, _ = configureExit(t, configure, co.Options{
+		Args: []string{"--password", "hunter2", "--explain", "password"},
+	})
+	assert.Equal(`password
+  Description: password
+  Type:        string
+  Default:     none
+  Value:       xxxxx
+  Source:      flag
+  File key:    password
+  Validation:  required, secret
+`, out)
+
+	code, _, errOut := configureExit(t, configure, co.Options{
+		Args: []string{"--explain", "nope"},
+	})
+	assert.Equal(1, code)
+	assert.Equal("error parsing configuration: unknown option: nope\n", errOut)
+}
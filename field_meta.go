@@ -0,0 +1,126 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the field metadata registry for struct types that can't
+be tagged, such as third-party structs
+*/
+package configurature
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// Registered field metadata by struct type and Go field name
+	fieldMetas = make(map[reflect.Type]map[string]FieldMeta)
+
+	// Protects fieldMetas
+	fieldMetasMu sync.RWMutex
+)
+
+// FieldMeta describes a field in place of struct tags. Tags on the field take
+// precedence.
+type FieldMeta struct {
+	Help     string   // Description, as with the help tag
+	Default  string   // Default value, as with the default tag. Use Tag for an empty default
+	Name     string   // Name used instead of the field name, as with the name tag
+	Short    string   // Short flag, as with the short tag
+	Enum     []string // Allowed values, as with the enum tag
+	Required bool     // A value must be specified, as with the required tag
+	Secret   bool     // The value is sensitive, as with the secret tag
+	Hidden   bool     // Hide the flag from usage, as with the hidden tag
+	Ignore   bool     // Not a config field, as with the ignore tag
+	Tag      string   // Any other struct tags. E.g. `relpath:"" group:"Database"`
+}
+
+// DescribeType registers metadata for the fields of struct type T, keyed by
+// Go field name, for struct types that can't be tagged.
+func DescribeType[T any](fields map[string]FieldMeta) {
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		panic("DescribeType: type " + t.String() + " is not a struct")
+	}
+	for name := range fields {
+		if _, ok := t.FieldByName(name); !ok {
+			panic(fmt.Sprintf("DescribeType: type %s has no field %s", t, name))
+		}
+	}
+
+	fieldMetasMu.Lock()
+	if fieldMetas[t] == nil {
+		fieldMetas[t] = make(map[string]FieldMeta)
+	}
+	maps.Copy(fieldMetas[t], fields)
+	fieldMetasMu.Unlock()
+
+	// Cached fields may have been collected without this metadata
+	clearStructFieldsCache()
+}
+
+// withFieldMeta returns field with the tags from its registered metadata
+// appended. Its own tags are found first by reflect.StructTag.Lookup.
+func withFieldMeta(owner reflect.Type, field reflect.StructField) reflect.StructField {
+	fieldMetasMu.RLock()
+	m, ok := fieldMetas[owner][field.Name]
+	fieldMetasMu.RUnlock()
+	if !ok {
+		return field
+	}
+
+	tags := []string{}
+	if field.Tag != "" {
+		tags = append(tags, string(field.Tag))
+	}
+	add := func(key, value string) {
+		tags = append(tags, key+":"+strconv.Quote(value))
+	}
+	if m.Help != "" {
+		add("help", m.Help)
+	}
+	if m.Default != "" {
+		add("default", m.Default)
+	}
+	if m.Name != "" {
+		add("name", m.Name)
+	}
+	if m.Short != "" {
+		add("short", m.Short)
+	}
+	if len(m.Enum) > 0 {
+		add("enum", strings.Join(m.Enum, ","))
+	}
+	if m.Required {
+		add("required", "")
+	}
+	if m.Secret {
+		add("secret", "")
+	}
+	if m.Hidden {
+		add("hidden", "")
+	}
+	if m.Ignore {
+		add("ignore", "")
+	}
+	if m.Tag != "" {
+		tags = append(tags, m.Tag)
+	}
+	field.Tag = reflect.StructTag(strings.Join(tags, " "))
+	return field
+}
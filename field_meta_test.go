@@ -0,0 +1,82 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+// MetaDBConfig stands in for a struct from another package that can't be
+// tagged
+type MetaDBConfig struct {
+	Addr     string
+	Port     int `default:"5432"`
+	Password string
+	Mode     string
+	Pool     any
+}
+
+type MetaConf struct {
+	DB MetaDBConfig
+}
+
+func init() {
+	co.DescribeType[MetaDBConfig](map[string]co.FieldMeta{
+		"Addr":     {Help: "database host", Name: "host", Required: true},
+		"Port":     {Default: "1234", Short: "p"},
+		"Password": {Secret: true, Tag: `group:"Database"`},
+		"Mode":     {Enum: []string{"ro", "rw"}, Default: "rw"},
+		"Pool":     {Ignore: true},
+	})
+}
+
+func TestDescribeType(t *testing.T) {
+	assert := assert.New(t)
+
+	c := co.Configure[MetaConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--db_host", "example.com", "--db_password", "hunter2"},
+	})
+	assert.Equal("example.com", c.DB.Addr)
+	assert.Equal(5432, c.DB.Port, "tags take precedence")
+	assert.Equal("hunter2", c.DB.Password)
+	assert.Equal("rw", c.DB.Mode)
+
+	fields := map[string]co.FieldInfo{}
+	for _, fi := range co.Fields[MetaConf](nil) {
+		fields[fi.Name] = fi
+	}
+	assert.Len(fields, 4)
+	assert.Equal("database host", fields["db_host"].Description)
+	assert.True(fields["db_host"].Required)
+	assert.Equal("p", fields["db_port"].Short)
+	assert.Equal("Database", fields["db_password"].Group)
+	assert.Equal([]string{"ro", "rw"}, fields["db_mode"].Enum)
+
+	assert.PanicsWithValue("db_host is required, db_mode must be one of ro, rw", func() {
+		co.Configure[MetaConf](&co.Options{
+			NoRecover: true,
+			Args:      []string{"--db_mode", "x"},
+		})
+	})
+
+	assert.PanicsWithValue("DescribeType: type configurature_test.MetaDBConfig has no field Nope", func() {
+		co.DescribeType[MetaDBConfig](map[string]co.FieldMeta{"Nope": {}})
+	})
+}
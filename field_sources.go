@@ -0,0 +1,62 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the sources tag, which restricts the sources that may set
+a field's value. E.g. sources:"file,env" keeps a secret off the command line,
+where it would show in ps output
+*/
+package configurature
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// annotateSources records the sources tag of a field on its flag
+func annotateSources(fs *pflag.FlagSet, name string, tags *reflect.StructTag) {
+	list, ok := tags.Lookup("sources")
+	if !ok {
+		return
+	}
+	sources := []string{}
+	for _, s := range strings.Split(list, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			sources = append(sources, s)
+		}
+	}
+	if len(sources) == 0 {
+		panic(fmt.Sprintf("sources tag of flag %s: must name at least one source", name))
+	}
+	fs.SetAnnotation(name, annotationSources, sources)
+}
+
+// checkSources records an error for each flag that was set by a source not
+// allowed by its sources tag. Sources are named flag, env, file, keyring or
+// the Name() of a Source.
+func (c *configurer) checkSources(fs *pflag.FlagSet) {
+	for _, name := range slices.Sorted(maps.Keys(c.sources)) {
+		allowed, ok := fs.Lookup(name).Annotations[annotationSources]
+		if !ok || slices.Contains(allowed, c.sources[name]) {
+			continue
+		}
+		c.errors = append(c.errors, fmt.Sprintf("%s cannot be set by %s (allowed sources: %s)",
+			name, c.sources[name], strings.Join(allowed, ", ")))
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type fieldSourcesConf struct {
+	Host     string `default:"localhost"`
+	Password string `secret:"" sources:"env, vault"`
+}
+
+func TestFieldSources(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("FSRC_PASSWORD", "hunter2")
+
+	c := co.Configure[fieldSourcesConf](&co.Options{
+		NoRecover: true,
+		EnvPrefix: "FSRC_",
+		Args:      []string{"--host", "db.local"},
+	})
+	assert.Equal("db.local", c.Host)
+	assert.Equal("hunter2", c.Password)
+
+	c = co.Configure[fieldSourcesConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+		Sources:   []co.Source{&testSource{name: "vault", values: map[string]any{"password": "s3cret"}}},
+	})
+	assert.Equal("s3cret", c.Password)
+}
+
+func TestFieldSources_Disallowed(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.PanicsWithValue("password cannot be set by flag (allowed sources: env, vault)", func() {
+		co.Configure[fieldSourcesConf](&co.Options{
+			NoRecover: true,
+			Args:      []string{"--password", "hunter2"},
+		})
+	})
+	assert.PanicsWithValue("password cannot be set by consul (allowed sources: env, vault)", func() {
+		co.Configure[fieldSourcesConf](&co.Options{
+			NoRecover: true,
+			Args:      []string{},
+			Sources:   []co.Source{&testSource{name: "consul", values: map[string]any{"password": "s3cret"}}},
+		})
+	})
+}
+
+func TestFieldSources_Empty(t *testing.T) {
+	type Conf struct {
+		Token string `sources:" , "`
+	}
+
+	assert.PanicsWithValue(t, "sources tag of flag token: must name at least one source", func() {
+		co.Configure[Conf](&co.Options{NoRecover: true, Args: []string{}})
+	})
+}
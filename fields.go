@@ -0,0 +1,135 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file provides the Fields function for introspecting config structs
+*/
+package configurature
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// FieldInfo describes a configuration field
+type FieldInfo struct {
+	Name        string   `json:"name"`        // Flag name. E.g. "db_host"
+	Field       string   `json:"field"`       // Go struct field name. E.g. "Host"
+	Short       string   `json:"short"`       // Short flag name. Empty if Options.DisableFlags is set
+	EnvVar      string   `json:"env_var"`     // Environment variable name including Options.EnvPrefix. Empty if Options.DisableEnv is set
+	Type        string   `json:"type"`        // Type name as shown in usage. E.g. "duration"
+	Default     string   `json:"default"`     // Default value from the default tag. Secrets and DSN passwords are redacted
+	HasDefault  bool     `json:"has_default"` // Whether the field has a default tag
+	Description string   `json:"description"` // Help text
+	Required    bool     `json:"required"`    // Whether a value must be specified
+	Hidden      bool     `json:"hidden"`      // Whether the flag is hidden from usage
+	Enum        []string `json:"enum"`        // Allowed values if the field is an enum
+	Ancestors   []string `json:"ancestors"`   // Names of the sub-configs containing the field
+	Group       string   `json:"group"`       // Usage section from the group tag
+}
+
+// Fields returns information about each configuration field of the config
+// struct T. opts is used to determine environment variable names and
+// required fields and may be nil.
+func Fields[T any](opts *Options) []FieldInfo {
+	if opts == nil {
+		opts = &Options{}
+	}
+	snapshotRegistries()
+
+	c := &configurer{
+		config: new(T),
+		opts:   opts,
+	}
+
+	// Load flags to determine the type of each field
+	f := flagSetFromOptions(opts, false)
+	c.loadFlags(c.config, f)
+
+	return c.fieldInfos(f)
+}
+
+// printHelpJSON prints information about all visible configuration fields as
+// JSON
+func (c *configurer) printHelpJSON(fs *pflag.FlagSet) {
+	fields := []FieldInfo{}
+	for _, fi := range c.fieldInfos(fs) {
+		if !fi.Hidden {
+			fields = append(fields, fi)
+		}
+	}
+	out, _ := json.MarshalIndent(fields, "", "  ")
+	fmt.Fprintln(c.opts.output(), string(out))
+}
+
+// fieldInfos returns information about each configuration field. The flags
+// for the fields must already have been added to fs.
+func (c *configurer) fieldInfos(f *pflag.FlagSet) []FieldInfo {
+	opts := c.opts
+	fields := []FieldInfo{}
+	c.visitFields(c.config, func(sf reflect.StructField, tags *reflect.StructTag, v reflect.Value, ancestors []string) (stop bool) {
+		fName := fieldNameToConfigName(sf.Name, tags, ancestors)
+		fl := f.Lookup(fName)
+		def, hasDefault := tags.Lookup("default")
+		if isSecret(fl) && def != "" {
+			def = redactedValue
+		} else if _, ok := unwrapValue(fl.Value).(*DSN); ok && !opts.ShowCredentials {
+			def = DSN(def).Redacted()
+		}
+		_, required := tags.Lookup("required")
+		_, hidden := tags.Lookup("hidden")
+
+		help, ok := tags.Lookup("help")
+		if !ok {
+			help = strings.ReplaceAll(fName, "_", " ")
+		}
+
+		short := fl.Shorthand
+		if opts.DisableFlags {
+			short = ""
+		}
+		envVar := opts.envVarName(fName, ancestors)
+		if opts.DisableEnv {
+			envVar = ""
+		}
+
+		var enum []string
+		if enums := tags.Get("enum"); enums != "" {
+			enum = strings.Split(enums, ",")
+		}
+
+		fields = append(fields, FieldInfo{
+			Name:        fName,
+			Field:       sf.Name,
+			Short:       short,
+			EnvVar:      envVar,
+			Type:        fl.Value.Type(),
+			Default:     def,
+			HasDefault:  hasDefault,
+			Description: help,
+			Required:    required || (opts.RequireNoDefaults && !hasDefault),
+			Hidden:      hidden,
+			Enum:        enum,
+			Ancestors:   ancestors,
+			Group:       tags.Get("group"),
+		})
+		return false
+	}, []string{})
+
+	return fields
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestFields(t *testing.T) {
+	fields := co.Fields[TestNestedConfig](&co.Options{EnvPrefix: "APP_"})
+
+	byName := map[string]co.FieldInfo{}
+	for _, f := range fields {
+		byName[f.Name] = f
+	}
+	assert := assert.New(t)
+	assert.Len(fields, 14)
+
+	assert.Equal(co.FieldInfo{
+		Name:        "sub_default_lock_timeout",
+		Field:       "DefaultLockTimeout",
+		Short:       "d",
+		EnvVar:      "APP_SUB_DEFAULT_LOCK_TIMEOUT",
+		Type:        "duration",
+		Default:     "10m",
+		HasDefault:  true,
+		Description: "Lock timeout to use when loading locks from state file on startup",
+		Ancestors:   []string{"sub"},
+	}, byName["sub_default_lock_timeout"])
+
+	assert.Equal([]string{"a", "b", "c"}, byName["my_enum"].Enum)
+	assert.True(byName["hidden_flag"].Hidden)
+	assert.Equal("configFile", byName["cool_file"].Type)
+	assert.False(byName["sub_req_int"].Required)
+}
+
+func TestFields_RequireNoDefaults(t *testing.T) {
+	type FConf struct {
+		Host string
+		Port int    `default:"80"`
+		Name string `required:""`
+	}
+	fields := co.Fields[FConf](&co.Options{RequireNoDefaults: true})
+
+	assert := assert.New(t)
+	assert.True(fields[0].Required)
+	assert.False(fields[1].Required)
+	assert.True(fields[2].Required)
+	assert.Equal("HOST", fields[0].EnvVar)
+	assert.Equal("host", fields[0].Description)
+	assert.Empty(fields[0].Ancestors)
+}
+
+func TestHelpJSON(t *testing.T) {
+	type JConf struct {
+		Host   string `help:"db host" required:""`
+		Port   int    `default:"5432" short:"p"`
+		Level  string `default:"info" enum:"debug,info"`
+		Secret string `hidden:""`
+		Token  string `secret:"" default:"hunter2"`
+		DB     co.DSN `default:"postgres://user:pass@db/app"`
+	}
+
+	if os.Getenv("TEST_PASSTHROUGH") == "1" {
+		co.Configure[JConf](&co.Options{
+			Args:      []string{"--help_json"},
+			EnvPrefix: "J_",
+		})
+		panic("Should have exited")
+	}
+
+	stdout, stderr := runExternal(t)
+	assert := assert.New(t)
+	assert.Equal("", stderr)
+
+	fields := []co.FieldInfo{}
+	assert.Nil(json.Unmarshal([]byte(stdout), &fields))
+	assert.Len(fields, 5)
+	assert.Equal("host", fields[0].Name)
+	assert.True(fields[0].Required)
+	assert.Equal("J_PORT", fields[1].EnvVar)
+	assert.Equal("p", fields[1].Short)
+	assert.Equal("int", fields[1].Type)
+	assert.Equal("5432", fields[1].Default)
+	assert.Equal([]string{"debug", "info"}, fields[2].Enum)
+	assert.Contains(stdout, `"description": "db host"`)
+	assert.Equal("xxxxx", fields[3].Default)
+	assert.Equal("postgres://user:xxxxx@db/app", fields[4].Default)
+	assert.NotContains(stdout, "hunter2")
+}
@@ -0,0 +1,53 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the FileDecoder option, which decodes config files straight
+into the config struct, e.g. with mapstructure and its decode hooks, instead
+of setting each value through its flag
+*/
+package configurature
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FileDecoder decodes the values of a config file into config, a pointer to a
+// new config struct. E.g. a function that calls mapstructure.Decode() with
+// decode hooks.
+type FileDecoder func(values map[string]any, config any) error
+
+// decodeConfigFiles decodes the config files read into a new config struct
+// with Options.FileDecoder, in the order they were read, and copies the fields
+// that were set by a config file, rather than a source taking precedence over
+// it, to the config
+func (c *configurer) decodeConfigFiles() {
+	decoded := reflect.New(reflect.TypeOf(c.config).Elem())
+	for _, values := range c.fileValues {
+		if err := c.opts.FileDecoder(values, decoded.Interface()); err != nil {
+			panic(fmt.Sprintf("error decoding config file: %v", err))
+		}
+	}
+
+	v := reflect.ValueOf(c.config).Elem()
+	for _, sf := range structFieldsOf(c.opts.Types, c.opts.TagNames, v.Type()) {
+		tags := sf.field.Tag
+		name := fieldNameToConfigName(sf.field.Name, &tags, sf.ancestors)
+		if c.sources[name] != sourceFile {
+			continue
+		}
+		fieldByIndexAlloc(v, sf.index).Set(fieldByIndexAlloc(decoded.Elem(), sf.index))
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !configurature_core
+
+package configurature_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type DecoderConf struct {
+	Host   string            `json:"host"`
+	Port   int               `json:"port" default:"80"`
+	Labels map[string]string `json:"labels"`
+	DB     struct {
+		User string `json:"user" required:""`
+	} `json:"db"`
+}
+
+// jsonDecoder decodes values into config through encoding/json
+func jsonDecoder(values map[string]any, config any) error {
+	b, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, config)
+}
+
+func TestFileDecoder(t *testing.T) {
+	assert := assert.New(t)
+	file := filepath.Join(t.TempDir(), "conf.yaml")
+	os.WriteFile(file, []byte("host: db.local\nport: 90\nlabels:\n  team: a,b\ndb:\n  user: app\n"), 0600)
+
+	c := co.Configure[DecoderConf](&co.Options{
+		NoRecover:   true,
+		ConfigFile:  file,
+		FileDecoder: jsonDecoder,
+		Args:        []string{"--port", "81"},
+	})
+
+	assert.Equal("db.local", c.Host)
+	assert.Equal(81, c.Port)
+	assert.Equal(map[string]string{"team": "a,b"}, c.Labels)
+	assert.Equal("app", c.DB.User)
+}
+
+func TestFileDecoder_Error(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "conf.yaml")
+	os.WriteFile(file, []byte("port: eighty\ndb:\n  user: app\n"), 0600)
+
+	assert.PanicsWithValue(t, "error decoding config file: json: cannot unmarshal string into Go struct field DecoderConf.port of type int", func() {
+		co.Configure[DecoderConf](&co.Options{
+			NoRecover:   true,
+			ConfigFile:  file,
+			FileDecoder: jsonDecoder,
+			Args:        []string{},
+		})
+	})
+}
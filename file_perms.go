@@ -0,0 +1,59 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the permission and ownership checks of config files that
+hold secret values
+*/
+package configurature
+
+import (
+	"fmt"
+	"os"
+)
+
+// FilePermCheck is what to do when a config file holding secret values can be
+// accessed by other users
+type FilePermCheck string
+
+// File permission checks
+const (
+	FilePermWarn FilePermCheck = "warn" // Print a warning
+	FilePermFail FilePermCheck = "fail" // Fail to load the configuration
+)
+
+// checkFilePerms warns or fails, as set by Options.CheckFilePerms, if any of
+// the files holding secret values can be accessed by other users or are owned
+// by another user
+func (c *configurer) checkFilePerms(files []string) {
+	for _, f := range files {
+		fi, err := os.Stat(f)
+		if err != nil {
+			continue
+		}
+		problem := filePermProblem(fi)
+		if problem == "" {
+			continue
+		}
+		msg := fmt.Sprintf("config file %s holds secret values but %s", f, problem)
+		switch c.opts.CheckFilePerms {
+		case FilePermWarn:
+			fmt.Fprintf(c.opts.errOutput(), "warning: %s\n", msg)
+		case FilePermFail:
+			panic(msg)
+		default:
+			panic(fmt.Sprintf("unsupported file permission check: %s", c.opts.CheckFilePerms))
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unix && !configurature_core
+
+package configurature_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestCheckFilePerms(t *testing.T) {
+	type Conf struct {
+		Conf     co.ConfigFile
+		Host     string
+		Password string `secret:""`
+	}
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "secret.yaml")
+	os.WriteFile(secretFile, []byte("password: hunter2\n"), 0644)
+	os.Chmod(secretFile, 0644)
+	hostFile := filepath.Join(dir, "host.yaml")
+	os.WriteFile(hostFile, []byte("host: example.com\n"), 0644)
+	os.Chmod(hostFile, 0644)
+
+	// Warn
+	errOut := &strings.Builder{}
+	c := co.Configure[Conf](&co.Options{
+		NoRecover:      true,
+		Args:           []string{"--conf", secretFile},
+		CheckFilePerms: co.FilePermWarn,
+		ErrOutput:      errOut,
+	})
+	assert.Equal("hunter2", c.Password)
+	assert.Equal("warning: config file "+secretFile+" holds secret values but its permissions 0644 allow access by other users\n", errOut.String())
+
+	// Fail
+	assert.PanicsWithValue("config file "+secretFile+" holds secret values but its permissions 0644 allow access by other users", func() {
+		co.Configure[Conf](&co.Options{
+			NoRecover:      true,
+			Args:           []string{"--conf", secretFile},
+			CheckFilePerms: co.FilePermFail,
+		})
+	})
+
+	// Files without secret values aren't checked
+	c = co.Configure[Conf](&co.Options{
+		NoRecover:      true,
+		Args:           []string{"--conf", hostFile},
+		CheckFilePerms: co.FilePermFail,
+	})
+	assert.Equal("example.com", c.Host)
+
+	// Private files
+	os.Chmod(secretFile, 0600)
+	c = co.Configure[Conf](&co.Options{
+		NoRecover:      true,
+		Args:           []string{"--conf", secretFile},
+		CheckFilePerms: co.FilePermFail,
+	})
+	assert.Equal("hunter2", c.Password)
+}
@@ -0,0 +1,42 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unix
+
+/*
+This file contains the config file permission and ownership checks for Unix
+platforms
+*/
+package configurature
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// filePermProblem describes why a file holding secret values is unsafe, or
+// returns "" if it isn't. Like ssh, files that other users can access or that
+// are owned by a user other than the current user or root are unsafe.
+func filePermProblem(fi os.FileInfo) string {
+	if perm := fi.Mode().Perm(); perm&0o077 != 0 {
+		return fmt.Sprintf("its permissions %04o allow access by other users", perm)
+	}
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+		if uid := int(st.Uid); uid != os.Getuid() && uid != 0 {
+			return fmt.Sprintf("it is owned by another user (uid %d)", uid)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,125 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains checks of the flag names that fields resolve to
+*/
+package configurature
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// checkFlagNames panics if fields of struct type t resolve to the same flag
+// name or short flag as each other or as a flag already in fs, such as
+// --help. pflag would panic with only the flag name.
+func checkFlagNames(types *Types, tagNames map[string]string, t reflect.Type, fs *pflag.FlagSet) {
+	paths := map[string]string{}
+	shortPaths := map[string]string{}
+	errors := []string{}
+	for _, sf := range structFieldsOf(types, tagNames, t) {
+		tags := sf.field.Tag
+		name := fieldNameToConfigName(sf.field.Name, &tags, sf.ancestors)
+		path := fieldPath(t, sf.index)
+		if other, ok := paths[name]; ok {
+			errors = append(errors, fmt.Sprintf("fields %s and %s both resolve to flag --%s", other, path, name))
+			continue
+		}
+		if fs.Lookup(name) != nil {
+			errors = append(errors, fmt.Sprintf("field %s resolves to flag --%s, which is reserved", path, name))
+		}
+		paths[name] = path
+
+		short := tags.Get("short")
+		switch {
+		case short == "":
+		case len(short) > 1:
+			errors = append(errors, fmt.Sprintf("short tag of field %s must be a single character: %q", path, short))
+		case shortPaths[short] != "":
+			errors = append(errors, fmt.Sprintf("fields %s and %s both use short flag -%s", shortPaths[short], path, short))
+		case fs.ShorthandLookup(short) != nil:
+			errors = append(errors, fmt.Sprintf("field %s uses short flag -%s, which is reserved", path, short))
+		default:
+			shortPaths[short] = path
+		}
+	}
+	if len(errors) > 0 {
+		panic(strings.Join(errors, ", "))
+	}
+}
+
+// autoShortFlags returns short flags for the visible fields of struct type t
+// without a short tag, keyed by flag name. Each field gets the first free
+// letter of its flag name, trying lower case letters before upper case ones.
+// Letters used by short tags and flags already in fs are not assigned.
+func autoShortFlags(types *Types, tagNames map[string]string, t reflect.Type, fs *pflag.FlagSet) map[string]string {
+	fields := structFieldsOf(types, tagNames, t)
+
+	used := map[string]bool{}
+	fs.VisitAll(func(fl *pflag.Flag) {
+		if fl.Shorthand != "" {
+			used[fl.Shorthand] = true
+		}
+	})
+	for _, sf := range fields {
+		if short := sf.field.Tag.Get("short"); short != "" {
+			used[short] = true
+		}
+	}
+
+	shorts := map[string]string{}
+	for _, sf := range fields {
+		tags := sf.field.Tag
+		_, hidden := tags.Lookup("hidden")
+		if tags.Get("short") != "" || hidden {
+			continue
+		}
+		name := fieldNameToConfigName(sf.field.Name, &tags, sf.ancestors)
+		for _, letters := range []string{strings.ToLower(name), strings.ToUpper(name)} {
+			if short := freeLetter(letters, used); short != "" {
+				used[short] = true
+				shorts[name] = short
+				break
+			}
+		}
+	}
+	return shorts
+}
+
+// freeLetter returns the first ASCII letter in s that is not used
+func freeLetter(s string, used map[string]bool) string {
+	for _, r := range s {
+		if ((r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')) && !used[string(r)] {
+			return string(r)
+		}
+	}
+	return ""
+}
+
+// fieldPath returns the path of the field at index in struct type t. E.g.
+// "Config.DB.Host"
+func fieldPath(t reflect.Type, index []int) string {
+	names := []string{}
+	if t.Name() != "" {
+		names = append(names, t.Name())
+	}
+	for i := range index {
+		names = append(names, t.FieldByIndex(index[:i+1]).Name)
+	}
+	return strings.Join(names, ".")
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type DupServer struct {
+	Port int
+}
+
+type DupConf struct {
+	DupServer
+	ListenPort int `name:"port"`
+	DB         struct {
+		Host string
+	}
+	DBHost string
+}
+
+func TestFlagNames_Duplicate(t *testing.T) {
+	assert.PanicsWithValue(t, "fields DupConf.DupServer.Port and DupConf.ListenPort both resolve to flag --port, "+
+		"fields DupConf.DB.Host and DupConf.DBHost both resolve to flag --db_host", func() {
+		co.Configure[DupConf](&co.Options{
+			NoRecover: true,
+			Args:      []string{},
+		})
+	})
+}
+
+func TestFlagNames_Reserved(t *testing.T) {
+	type Conf struct {
+		Help    bool
+		Profile string
+	}
+	assert.PanicsWithValue(t, "field Conf.Help resolves to flag --help, which is reserved", func() {
+		co.Configure[Conf](&co.Options{
+			NoRecover: true,
+			Args:      []string{},
+		})
+	})
+	assert.PanicsWithValue(t, "field Conf.Help resolves to flag --help, which is reserved, "+
+		"field Conf.Profile resolves to flag --profile, which is reserved", func() {
+		co.Configure[Conf](&co.Options{
+			NoRecover:   true,
+			Args:        []string{},
+			ProfileFlag: true,
+		})
+	})
+}
+
+func TestFlagNames_DuplicateShort(t *testing.T) {
+	type Conf struct {
+		Port    int    `short:"p"`
+		Path    string `short:"p"`
+		Home    string `short:"h"`
+		Verbose bool   `short:"vv"`
+	}
+	assert.PanicsWithValue(t, "fields Conf.Port and Conf.Path both use short flag -p, "+
+		"field Conf.Home uses short flag -h, which is reserved, "+
+		"short tag of field Conf.Verbose must be a single character: \"vv\"", func() {
+		co.Configure[Conf](&co.Options{
+			NoRecover: true,
+			Args:      []string{},
+		})
+	})
+}
+
+func TestAutoShortFlags(t *testing.T) {
+	type Conf struct {
+		Host    string
+		Port    int
+		Pool    int
+		Oops    int    `short:"o"`
+		Hidden  string `hidden:""`
+		Profile string
+		DB      struct {
+			Host string
+		}
+	}
+	assert := assert.New(t)
+
+	shorts := map[string]string{}
+	for _, fi := range co.Fields[Conf](&co.Options{AutoShortFlags: true}) {
+		shorts[fi.Name] = fi.Short
+	}
+	assert.Equal(map[string]string{
+		"host":    "s", // h is taken by --help
+		"port":    "p",
+		"pool":    "l",
+		"oops":    "o",
+		"hidden":  "",
+		"profile": "r",
+		"db_host": "d",
+	}, shorts)
+
+	c := co.Configure[Conf](&co.Options{
+		NoRecover:      true,
+		Args:           []string{"-s", "example.com", "-p", "80", "-d", "db"},
+		AutoShortFlags: true,
+	})
+	assert.Equal("example.com", c.Host)
+	assert.Equal(80, c.Port)
+	assert.Equal("db", c.DB.Host)
+}
@@ -0,0 +1,51 @@
+// Code generated by configurature-gen. DO NOT EDIT.
+
+package configurature_test
+
+import (
+	"github.com/spf13/pflag"
+
+	co "github.com/imoore76/configurature"
+)
+
+// RegisterFlags adds flags bound to the fields of GenConfig to fs
+func (c *GenConfig) RegisterFlags(fs *pflag.FlagSet) {
+	fs.StringVarP(&c.OtherSubConfig.SubFooString, "sub_foo_string", "", co.GeneratedDefault((*pflag.FlagSet).StringVar, "sub_foo_string", "here"), "Something")
+	co.AnnotateGeneratedFlag(fs, "sub_foo_string", "help:\"Something\" default:\"here\"")
+	co.GeneratedPtr(&c.GenEmbedded)
+	fs.StringVarP(&c.GenEmbedded.Region, "region", "", co.GeneratedDefault((*pflag.FlagSet).StringVar, "region", "us"), "region")
+	co.AnnotateGeneratedFlag(fs, "region", "yaml:\"zone\" default:\"us\"")
+	fs.VarP(co.GeneratedValue(&c.Conf, "conf", ""), "conf", "", "Configuration file")
+	co.AnnotateGeneratedFlag(fs, "conf", "help:\"Configuration file\"")
+	fs.DurationVarP(&c.WaitTimeout, "wait_timeout", "", co.GeneratedDefault((*pflag.FlagSet).DurationVar, "wait_timeout", "30s"), "How long to wait for the server")
+	co.AnnotateGeneratedFlag(fs, "wait_timeout", "default:\"30s\"")
+	fs.IPVarP(&c.ListenIP, "listen_ip", "", co.GeneratedDefault((*pflag.FlagSet).IPVar, "listen_ip", "127.0.0.1"), "listen ip")
+	co.AnnotateGeneratedFlag(fs, "listen_ip", "default:\"127.0.0.1\"")
+	fs.StringSliceVarP(&c.Names, "names", "", co.GeneratedDefault((*pflag.FlagSet).StringSliceVar, "names", "a,b,c"), "names")
+	co.AnnotateGeneratedFlag(fs, "names", "default:\"a,b,c\"")
+	fs.StringToIntVarP(&c.Ages, "ages", "", co.GeneratedDefault((*pflag.FlagSet).StringToIntVar, "ages", ""), "ages")
+	co.AnnotateGeneratedFlag(fs, "ages", "")
+	fs.StringVarP(&c.Level, "level", "", co.GeneratedDefault((*pflag.FlagSet).StringVar, "level", "info"), "level (debug|info|warn|error)")
+	co.AnnotateGeneratedFlag(fs, "level", "default:\"info\" enum:\"debug,info,warn,error\"")
+	fs.VarP(co.GeneratedValue(&c.Image, "image", ""), "image", "", "Path to an image")
+	co.AnnotateGeneratedFlag(fs, "image", "help:\"Path to an image\"")
+	fs.StringVarP(co.GeneratedPtr(&c.Secret), "secret", "", co.GeneratedDefault((*pflag.FlagSet).StringVar, "secret", ""), "secret")
+	co.AnnotateGeneratedFlag(fs, "secret", "hidden:\"\"")
+	fs.StringVarP(&c.Color, "color", "", co.GeneratedDefault((*pflag.FlagSet).StringVar, "color", "never"), "color")
+	co.AnnotateGeneratedFlag(fs, "color", "default:\"never\" flagdefault:\"always\"")
+	fs.VarP(co.GeneratedValue(&c.Started, "started", "2024-01-02T03:04:05Z"), "started", "", "started")
+	co.AnnotateGeneratedFlag(fs, "started", "default:\"2024-01-02T03:04:05Z\"")
+	fs.StringVarP(&c.DB.Host, "db_host", "", co.GeneratedDefault((*pflag.FlagSet).StringVar, "db_host", ""), "db host")
+	co.AnnotateGeneratedFlag(fs, "db_host", "help:\"db host\" required:\"\"", "db")
+	fs.IntVarP(&c.DB.Port, "db_port", "p", co.GeneratedDefault((*pflag.FlagSet).IntVar, "db_port", "5432"), "db port")
+	co.AnnotateGeneratedFlag(fs, "db_port", "default:\"5432\" short:\"p\"", "db")
+}
+
+// FileKeyAliases returns the config file keys given by the yaml and json
+// tags of the fields of GenConfig
+func (c *GenConfig) FileKeyAliases() map[string]string {
+	return map[string]string{
+		"database": "db",
+		"zone":     "region",
+	}
+}
@@ -0,0 +1,340 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file provides the ConfigureGenerated function and the helpers called by
+code generated with cmd/configurature-gen. None of this uses reflection to
+walk the config struct.
+*/
+package configurature
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// Flag annotations used to carry struct tag information for generated code
+const (
+	annotationRequired  = "configurature_required"
+	annotationNoDefault = "configurature_no_default"
+	annotationEnum      = "configurature_enum"
+	annotationRelPath   = "configurature_relpath"
+	annotationTrim      = "configurature_trim"
+	annotationEmpty     = "configurature_empty"
+	annotationSecret    = "configurature_secret"
+	annotationAncestors = "configurature_ancestors"
+	annotationGroup     = "configurature_group"
+	annotationFormat    = "configurature_format"
+	annotationSources   = "configurature_sources"
+)
+
+// GeneratedConfig is implemented by config structs that have flag
+// registration code generated by cmd/configurature-gen
+type GeneratedConfig interface {
+	RegisterFlags(fs *pflag.FlagSet) // Add flags bound to the struct's fields
+}
+
+// fileKeyAliaser is implemented by generated configs whose fields have yaml
+// or json tags. See fileKeyAliases().
+type fileKeyAliaser interface {
+	FileKeyAliases() map[string]string
+}
+
+// ConfigureGenerated is like Configure, but uses the RegisterFlags method
+// generated by cmd/configurature-gen instead of reflection. The NilPtrs and
+// FileDecoder options are ignored, and the --help_json,
+// --print_yaml_template, --init and --explain flags are not added.
+func ConfigureGenerated[T any, PT interface {
+	*T
+	GeneratedConfig
+}](opts *Options) *T {
+	start := time.Now()
+	opts = optionsWithDefaults(opts)
+	snapshotRegistries()
+
+	config := PT(new(T))
+	c := &configurer{
+		ctx:            context.Background(),
+		config:         config,
+		opts:           opts,
+		generated:      true,
+		fileKeyAliases: map[string]string{},
+	}
+	if a, ok := any(config).(fileKeyAliaser); ok {
+		c.fileKeyAliases = a.FileKeyAliases()
+	}
+
+	// Create a flagset and add the generated flags to it
+	f := flagSetFromOptions(opts, true)
+	config.RegisterFlags(f)
+	if !opts.ShowCredentials {
+		redactCredentials(f)
+	}
+
+	// Find the ConfigFile flags that were registered
+	c.setGeneratedConfigFiles(f)
+
+	// Recover from panic and print error
+	if !opts.NoRecover {
+		defer func() {
+			if r := recover(); r != nil {
+				if _, ok := r.(*ExitError); ok {
+					panic(r)
+				}
+				fmt.Fprintf(opts.errOutput(), "error parsing configuration: %s\n", r)
+				opts.exit(1)
+			}
+		}()
+	}
+
+	// Parse CLI args into flagset. Flags are bound directly to the struct's
+	// fields so there are no setters to run.
+	c.argErrors = parseFlags(f, opts)
+
+	// Merge values from config files, sources, the environment and the
+	// keyring into flags that were not specified on the command line
+	c.applySources(f)
+
+	// Show usage if requested
+	if help, _ := f.GetBool("help"); help {
+		f.Usage()
+	}
+
+	// Print changed options
+	if ok, _ := f.GetBool("print_changed"); ok {
+		c.printChanged(f)
+		opts.exit(0)
+	}
+
+	// Generate .env template
+	if ok, _ := f.GetBool("print_env_template"); ok {
+		c.printEnvTemplate(f)
+		opts.exit(0)
+	}
+
+	if shell, _ := f.GetString(completionFlag); shell != "" {
+		c.printCompletion(shell, f)
+		opts.exit(0)
+	}
+
+	// Validate config
+	if isDryRun(f, opts) {
+		dryRun(func() { c.checkErrors(f, c.validateFlags(f)) }, opts)
+	}
+	c.checkErrors(f, c.validateFlags(f))
+
+	storeConfig[T](c, f, start)
+
+	return c.config.(PT)
+}
+
+// GeneratedDefault returns the native value of the default string def for a
+// flag type by using the supplied pflag.FlagSet Var method to parse it. E.g.
+//
+//	GeneratedDefault((*pflag.FlagSet).DurationVar, "timeout", "30s")
+func GeneratedDefault[T any](varFn func(*pflag.FlagSet, *T, string, T, string), name string, def string) T {
+	var zero, v T
+	if def == "" {
+		return v
+	}
+	fs := pflag.NewFlagSet("default", pflag.ContinueOnError)
+	varFn(fs, &v, name, zero, "")
+	if err := fs.Set(name, def); err != nil {
+		panic(fmt.Sprintf("Error setting default value for field %s: %s", name, err))
+	}
+	return v
+}
+
+// GeneratedValueDefault sets the default value of a custom Value type field
+// before it is added to a FlagSet by generated code
+func GeneratedValueDefault(v Value, name string, def string) {
+	if def == "" {
+		return
+	}
+	if err := v.Set(def); err != nil {
+		panic(fmt.Sprintf("Error setting default value for field %s: %s", name, err))
+	}
+	if d, ok := v.(defaultMarker); ok {
+		d.markDefault()
+	}
+}
+
+// GeneratedValue returns the Value that generated code binds to the field p
+// points to. If *T is not itself a Value, the Value registered for T, e.g.
+// for time.Time, is used and the field is set whenever the Value is. def is
+// the default value of the field named name.
+func GeneratedValue[T any](p *T, name string, def string) Value {
+	if v, ok := any(p).(Value); ok {
+		GeneratedValueDefault(v, name, def)
+		return v
+	}
+	fn, ok := getCustomFlagFn(nil, reflect.TypeFor[T]())
+	if !ok {
+		panic(fmt.Sprintf("GeneratedValue: unsupported type %s of field %s", reflect.TypeFor[T](), name))
+	}
+	fs := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	fn(name, "", def, "", fs)
+	b := &boundValue[T]{Value: fs.Lookup(name).Value, p: p}
+	b.store()
+	return b
+}
+
+// boundValue sets the field p points to from the value of the registered
+// Value it wraps
+type boundValue[T any] struct {
+	Value
+	p *T
+}
+
+func (b *boundValue[T]) unwrap() pflag.Value {
+	return b.Value
+}
+
+func (b *boundValue[T]) Set(v string) error {
+	if err := b.Value.Set(v); err != nil {
+		return err
+	}
+	b.store()
+	return nil
+}
+
+// store sets the field to the value of the wrapped Value, if it has one
+func (b *boundValue[T]) store() {
+	i, ok := b.Value.(interfacer)
+	if !ok {
+		return
+	}
+	switch v := i.Interface().(type) {
+	case T:
+		*b.p = v
+	case *T:
+		if v != nil {
+			*b.p = *v
+		}
+	}
+}
+
+// GeneratedPtr initializes the pointer field p points to if it is nil and
+// returns it so that generated code can bind a flag to it
+func GeneratedPtr[T any](p **T) *T {
+	if *p == nil {
+		*p = new(T)
+	}
+	return *p
+}
+
+// AnnotateGeneratedFlag records the configurature struct tags of a field on
+// the flag that generated code added for it. ancestors are the names of the
+// sub-configs containing the field and are used to name its environment
+// variable.
+func AnnotateGeneratedFlag(fs *pflag.FlagSet, name string, tag string, ancestors ...string) {
+	tags := reflect.StructTag(tag)
+	if len(ancestors) > 0 {
+		fs.SetAnnotation(name, annotationAncestors, ancestors)
+	}
+	if _, ok := tags.Lookup("hidden"); ok {
+		fs.MarkHidden(name)
+	}
+	if _, ok := tags.Lookup("required"); ok {
+		fs.SetAnnotation(name, annotationRequired, []string{"true"})
+	}
+	if _, ok := tags.Lookup("defaultFile"); ok {
+		setGeneratedDefaultFile(fs, name, tags)
+	} else if _, ok := tags.Lookup("default"); !ok {
+		fs.SetAnnotation(name, annotationNoDefault, []string{"true"})
+	}
+	if enums := tags.Get("enum"); enums != "" {
+		fs.SetAnnotation(name, annotationEnum, strings.Split(enums, ","))
+	}
+	if _, ok := tags.Lookup("relpath"); ok {
+		fs.SetAnnotation(name, annotationRelPath, []string{"true"})
+	}
+	if delim, ok := tags.Lookup("delim"); ok {
+		setFlagDelim(fs, name, delim)
+	}
+	if noOptDef, ok := tags.Lookup("flagdefault"); ok {
+		fs.Lookup(name).NoOptDefVal = noOptDef
+	}
+	annotateSlicePolicy(fs, name, &tags)
+	annotateSources(fs, name, &tags)
+	if group := tags.Get("group"); group != "" {
+		fs.SetAnnotation(name, annotationGroup, []string{group})
+	}
+	if format := tags.Get("format"); format != "" {
+		fs.SetAnnotation(name, annotationFormat, []string{format})
+	}
+	if _, ok := tags.Lookup("secret"); ok {
+		_, hasDefault := tags.Lookup("default")
+		_, hasDefaultFile := tags.Lookup("defaultFile")
+		markSecret(fs, name, hasDefault || hasDefaultFile)
+	}
+}
+
+// setGeneratedConfigFiles looks for flags holding a ConfigFile and adds their
+// names to configFileFlags in the order they were registered
+func (c *configurer) setGeneratedConfigFiles(fs *pflag.FlagSet) {
+	sortFlags := fs.SortFlags
+	fs.SortFlags = false
+	defer func() { fs.SortFlags = sortFlags }()
+	fs.VisitAll(func(f *pflag.Flag) {
+		if _, ok := f.Value.(*ConfigFile); ok {
+			c.configFileFlags = append(c.configFileFlags, f.Name)
+		}
+	})
+}
+
+// setFlagsFromEnv adds setters for flag values found in the environment to
+// values
+func (c *configurer) setFlagsFromEnv(fs *pflag.FlagSet, values sourceSetters) {
+	fs.VisitAll(func(f *pflag.Flag) {
+		if _, ok := internalFlags[f.Name]; ok {
+			return
+		}
+		c.addEnvSetter(f, c.opts.envVarName(f.Name, f.Annotations[annotationAncestors]), values)
+	})
+}
+
+// validateFlags returns the errors validating flags using the annotations
+// added by AnnotateGeneratedFlag
+func (c *configurer) validateFlags(fs *pflag.FlagSet) []string {
+	errors := []string{}
+	fs.VisitAll(func(f *pflag.Flag) {
+		// Check that required values are specified
+		_, required := f.Annotations[annotationRequired]
+		if !required && c.opts.RequireNoDefaults {
+			_, required = f.Annotations[annotationNoDefault]
+		}
+
+		// Values from sources other than flags don't mark the flag changed
+		if _, ok := c.sources[f.Name]; required && !ok && !f.Changed {
+			errors = append(errors, fmt.Sprintf("%s is required", f.Name))
+			return
+		}
+
+		// Check enums
+		if enums, ok := f.Annotations[annotationEnum]; ok {
+			if !slices.Contains(enums, f.Value.String()) {
+				errors = append(errors, fmt.Sprintf("%s must be one of %s", f.Name, strings.Join(enums, ", ")))
+			}
+		}
+	})
+
+	return errors
+}
@@ -0,0 +1,165 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+//go:generate go run ./cmd/configurature-gen -type GenConfig -output genconfig_configurature_test.go
+
+type GenSubConfig struct {
+	Host string `help:"db host" required:""`
+	Port int    `default:"5432" short:"p"`
+}
+
+type GenEmbedded struct {
+	Region string `yaml:"zone" default:"us"`
+}
+
+type GenConfig struct {
+	OtherSubConfig
+	*GenEmbedded
+	Conf co.ConfigFile `help:"Configuration file"`
+	// How long to wait for the server
+	WaitTimeout time.Duration `default:"30s"`
+	ListenIP    net.IP        `default:"127.0.0.1"`
+	Names       []string      `default:"a,b,c"`
+	Ages        map[string]int
+	Level       string       `default:"info" enum:"debug,info,warn,error"`
+	Image       ImageFile    `help:"Path to an image"`
+	Secret      *string      `hidden:""`
+	Color       string       `default:"never" flagdefault:"always"`
+	Started     time.Time    `default:"2024-01-02T03:04:05Z"`
+	Ignored     string       `ignore:""`
+	DB          GenSubConfig `yaml:"database"`
+}
+
+func TestConfigureGenerated_Defaults(t *testing.T) {
+	assert := assert.New(t)
+	c := co.ConfigureGenerated[GenConfig](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--db_host", "localhost"},
+	})
+
+	assert.Equal("here", c.SubFooString)
+	assert.Equal(30*time.Second, c.WaitTimeout)
+	assert.Equal("127.0.0.1", c.ListenIP.String())
+	assert.Equal([]string{"a", "b", "c"}, c.Names)
+	assert.Equal("info", c.Level)
+	assert.Equal("", *c.Secret)
+	assert.Equal("localhost", c.DB.Host)
+	assert.Equal(5432, c.DB.Port)
+	assert.Equal("us", c.Region)
+	assert.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), c.Started)
+}
+
+func TestConfigureGenerated_Flags(t *testing.T) {
+	assert := assert.New(t)
+	c := co.ConfigureGenerated[GenConfig](&co.Options{
+		NoRecover: true,
+		Args: []string{"--db_host", "db", "-p", "1", "--names", "x,y",
+			"--ages", "joe=3", "--secret", "s", "--sub_foo_string", "there"},
+	})
+
+	assert.Equal("there", c.SubFooString)
+	assert.Equal([]string{"x", "y"}, c.Names)
+	assert.Equal(map[string]int{"joe": 3}, c.Ages)
+	assert.Equal("s", *c.Secret)
+	assert.Equal(1, c.DB.Port)
+	assert.Equal("never", c.Color)
+
+	c = co.ConfigureGenerated[GenConfig](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--db_host", "db", "--color"},
+	})
+	assert.Equal("always", c.Color)
+}
+
+func TestConfigureGenerated_Validate(t *testing.T) {
+	assert.PanicsWithValue(t, "db_host is required, level must be one of debug, info, warn, error", func() {
+		co.ConfigureGenerated[GenConfig](&co.Options{
+			NoRecover: true,
+			Args:      []string{"--level", "nope"},
+		})
+	})
+}
+
+func TestConfigureGenerated_Latest(t *testing.T) {
+	t.Cleanup(co.ResetForTest)
+	c := co.ConfigureGenerated[GenConfig](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--db_host", "localhost"},
+	})
+
+	assert.Equal(t, c, co.Latest[GenConfig]())
+}
+
+func TestConfigureGenerated_EnvNestedDelimiter(t *testing.T) {
+	t.Setenv("GEN_DB__HOST", "nested")
+	t.Setenv("GEN_DB__PORT", "44")
+
+	c := co.ConfigureGenerated[GenConfig](&co.Options{
+		NoRecover:          true,
+		EnvPrefix:          "GEN_",
+		EnvNestedDelimiter: "__",
+		Args:               []string{},
+	})
+
+	assert.Equal(t, "nested", c.DB.Host)
+	assert.Equal(t, 44, c.DB.Port)
+}
+
+type GenEnumConfig struct {
+	Mode string
+}
+
+func (c *GenEnumConfig) RegisterFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&c.Mode, "mode", "", "mode")
+	co.AnnotateGeneratedFlag(fs, "mode", `required:"" enum:"fast,slow"`)
+}
+
+func TestConfigureGenerated_RequiredEnum(t *testing.T) {
+	assert.PanicsWithValue(t, "mode is required", func() {
+		co.ConfigureGenerated[GenEnumConfig](&co.Options{
+			NoRecover: true,
+			Args:      []string{},
+		})
+	})
+	assert.PanicsWithValue(t, "mode must be one of fast, slow", func() {
+		co.ConfigureGenerated[GenEnumConfig](&co.Options{
+			NoRecover: true,
+			Args:      []string{"--mode", "medium"},
+		})
+	})
+}
+
+func TestConfigureGenerated_InternalFlags(t *testing.T) {
+	code, _, errOut := configureExit(t, func(opts *co.Options) {
+		co.ConfigureGenerated[GenConfig](opts)
+	}, co.Options{
+		Args:    []string{"--db_host", "localhost", "--help_json"},
+		NilPtrs: true,
+	})
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errOut, "unknown flag: --help_json")
+}
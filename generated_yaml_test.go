@@ -0,0 +1,50 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !configurature_core
+
+package configurature_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestConfigureGenerated_EnvAndFile(t *testing.T) {
+	assert := assert.New(t)
+
+	tmp, _ := os.CreateTemp("", "cfgr-test-*.yml")
+	defer os.Remove(tmp.Name())
+	tmp.Write([]byte("level: warn\nnames: [d, e]\nzone: eu\ndatabase:\n  port: 22\n  host: dbhost\n"))
+	tmp.Close()
+
+	os.Setenv("GEN_DB_PORT", "33")
+	defer os.Unsetenv("GEN_DB_PORT")
+
+	c := co.ConfigureGenerated[GenConfig](&co.Options{
+		NoRecover: true,
+		EnvPrefix: "GEN_",
+		Args:      []string{"--conf", tmp.Name()},
+	})
+
+	assert.Equal("warn", c.Level)
+	assert.Equal([]string{"d", "e"}, c.Names)
+	assert.Equal(33, c.DB.Port)
+	assert.Equal("dbhost", c.DB.Host)
+	assert.Equal("eu", c.Region)
+}
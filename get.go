@@ -17,12 +17,24 @@ package configurature
 import (
 	"errors"
 	"reflect"
+	"sync"
 )
 
 var (
 	// lastConfigLoaded is the last loaded configuration
 	lastConfigLoaded any
 
+	// lastSnapshot is set if the last loaded configuration was loaded with
+	// Options.Snapshots set
+	lastSnapshot *snapshot
+
+	// namedConfigs holds configurations loaded with Options.Name set
+	namedConfigs = make(map[string]any)
+
+	// namedSnapshots holds the named configurations loaded with
+	// Options.Snapshots set
+	namedSnapshots = make(map[string]*snapshot)
+
 	// ErrConfigNotLoaded is returned when the last loaded configuration is nil
 	ErrConfigNotLoaded = errors.New("configuration not loaded - did you run Configure[]()?")
 
@@ -31,19 +43,30 @@ var (
 
 	// For disabling type caching
 	DisableGetTypeCache = false
+
+	// Protects lastConfigLoaded, lastSnapshot, namedConfigs, namedSnapshots
+	// and getConfigTypeCache
+	configsMu sync.Mutex
 )
 
 // Get returns a pointer to the configuration of type T found anywhere in the
-// last loaded configuration.
+// last loaded configuration, or to a copy of it if the configuration was
+// loaded with Options.Snapshots set.
 // Returns (nil, ErrConfigNotLoaded) if the last loaded configuration is nil.
 // Returns (nil, nil) if no configuration of type T is found
 func Get[T any]() (*T, error) {
+	configsMu.Lock()
+	defer configsMu.Unlock()
+
 	if lastConfigLoaded == nil {
 		return nil, ErrConfigNotLoaded
 	}
+	if lastSnapshot != nil {
+		lastSnapshot.check()
+	}
 	switch t := lastConfigLoaded.(type) {
 	case *T:
-		return t, nil
+		return snapshotOf(t, lastSnapshot), nil
 	}
 
 	var t any
@@ -58,7 +81,30 @@ func Get[T any]() (*T, error) {
 	} else {
 		t = findStructOfType[T](lastConfigLoaded)
 	}
-	return t.(*T), nil
+	return snapshotOf(t.(*T), lastSnapshot), nil
+}
+
+// GetNamed returns a pointer to the configuration of type T found anywhere in
+// the configuration loaded with Options.Name set to name, or to a copy of it
+// if the configuration was loaded with Options.Snapshots set.
+// Returns (nil, ErrConfigNotLoaded) if no configuration was loaded with name.
+// Returns (nil, nil) if no configuration of type T is found
+func GetNamed[T any](name string) (*T, error) {
+	configsMu.Lock()
+	config, ok := namedConfigs[name]
+	snap := namedSnapshots[name]
+	configsMu.Unlock()
+	if !ok {
+		return nil, ErrConfigNotLoaded
+	}
+	if snap != nil {
+		snap.check()
+	}
+	switch t := config.(type) {
+	case *T:
+		return snapshotOf(t, snap), nil
+	}
+	return snapshotOf(findStructOfType[T](config), snap), nil
 }
 
 // findStructOfType recursively searches for a struct of type T in struct s
@@ -88,11 +134,31 @@ func findStructOfType[T any](s any) *T {
 	return nil
 }
 
-// setLastConfig sets the last loaded configuration
-func setLastConfig(config any) {
+// setLastConfig sets the last loaded configuration. Copies of it are returned
+// by Get[T]() if snapshots is true.
+func setLastConfig(config any, snapshots bool) {
+	configsMu.Lock()
+	defer configsMu.Unlock()
+
 	// Set last config
 	lastConfigLoaded = config
+	lastSnapshot = nil
+	if snapshots {
+		lastSnapshot = newSnapshot(config)
+	}
 
 	// Clear getConfigTypeCache each time a new config is loaded
 	getConfigTypeCache = make(map[reflect.Type]any)
 }
+
+// setNamedConfig stores a configuration for retrieval by GetNamed. Copies of
+// it are returned if snapshots is true.
+func setNamedConfig(name string, config any, snapshots bool) {
+	configsMu.Lock()
+	defer configsMu.Unlock()
+	namedConfigs[name] = config
+	delete(namedSnapshots, name)
+	if snapshots {
+		namedSnapshots[name] = newSnapshot(config)
+	}
+}
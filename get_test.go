@@ -140,3 +140,39 @@ func TestGet_NotFound(t *testing.T) {
 	assert.Nil(err)
 	assert.Nil(c)
 }
+
+func TestGetNamed(t *testing.T) {
+	assert := assert.New(t)
+
+	co.Configure[TestNestedConfig](&co.Options{
+		Name: "server",
+		Args: []string{"--os_sub_foo_string", "server"},
+	})
+	co.Configure[TestConfig](&co.Options{
+		Name: "agent",
+		Args: []string{"--sub_foo_string", "agent"},
+	})
+
+	sub, err := co.GetNamed[OtherSubConfig]("server")
+	assert.Nil(err)
+	assert.NotNil(sub)
+	assert.Equal("server", sub.SubFooString)
+
+	sub, err = co.GetNamed[OtherSubConfig]("agent")
+	assert.Nil(err)
+	assert.NotNil(sub)
+	assert.Equal("agent", sub.SubFooString)
+
+	top, err := co.GetNamed[TestConfig]("agent")
+	assert.Nil(err)
+	assert.NotNil(top)
+}
+
+func TestGetNamed_NotLoaded(t *testing.T) {
+	assert := assert.New(t)
+
+	c, err := co.GetNamed[OtherSubConfig]("nope")
+
+	assert.ErrorIs(err, co.ErrConfigNotLoaded)
+	assert.Nil(c)
+}
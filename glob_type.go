@@ -0,0 +1,91 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Value interface implementation for the Glob type which
+is used to specify include and exclude patterns on a configurature struct
+*/
+package configurature
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Type representing a slash separated path pattern, e.g. "src/**/*.go". Each
+// path segment is matched as by path.Match, and a "**" segment matches any
+// number of segments.
+type Glob string
+
+func (g *Glob) String() string {
+	return (string)(*g)
+}
+
+func (g *Glob) Set(v string) error {
+	for _, seg := range strings.Split(v, "/") {
+		if seg != "**" && strings.Contains(seg, "**") {
+			return fmt.Errorf("invalid glob \"%s\": ** must be a whole path segment", v)
+		}
+		if _, err := path.Match(seg, ""); err != nil {
+			return fmt.Errorf("invalid glob \"%s\": %w", v, err)
+		}
+	}
+	*g = (Glob)(v)
+	return nil
+}
+
+func (g *Glob) Type() string {
+	return "glob"
+}
+
+// Match returns whether the slash separated path name matches the pattern
+func (g Glob) Match(name string) bool {
+	return matchSegments(strings.Split(string(g), "/"), strings.Split(name, "/"))
+}
+
+// matchSegments returns whether the path segments of name match the pattern
+// segments
+func matchSegments(pattern []string, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Match the rest of the pattern at every remaining segment
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// MatchAnyGlob returns whether the slash separated path name matches any of
+// the patterns. E.g. to check a list of exclude patterns.
+func MatchAnyGlob(patterns []Glob, name string) bool {
+	for _, g := range patterns {
+		if g.Match(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestGlob(t *testing.T) {
+	type GlobConf struct {
+		Include co.Glob   `default:"src/**/*.go"`
+		Exclude []co.Glob `default:"**/*_test.go,vendor/**"`
+	}
+	assert := assert.New(t)
+
+	c := co.Configure[GlobConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+	})
+
+	assert.Equal(co.Glob("src/**/*.go"), c.Include)
+	assert.True(c.Include.Match("src/main.go"))
+	assert.True(c.Include.Match("src/a/b/c.go"))
+	assert.False(c.Include.Match("lib/main.go"))
+	assert.False(c.Include.Match("src/a/readme.md"))
+
+	assert.Equal([]co.Glob{"**/*_test.go", "vendor/**"}, c.Exclude)
+	assert.True(co.MatchAnyGlob(c.Exclude, "main_test.go"))
+	assert.True(co.MatchAnyGlob(c.Exclude, "src/x/main_test.go"))
+	assert.True(co.MatchAnyGlob(c.Exclude, "vendor/a/b.go"))
+	assert.False(co.MatchAnyGlob(c.Exclude, "src/main.go"))
+}
+
+func TestGlob_Invalid(t *testing.T) {
+	assert := assert.New(t)
+	g := new(co.Glob)
+
+	assert.EqualError(g.Set("src/[a-"), `invalid glob "src/[a-": syntax error in pattern`)
+	assert.EqualError(g.Set("src/a**"), `invalid glob "src/a**": ** must be a whole path segment`)
+}
@@ -0,0 +1,70 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Value interface implementation for the HostPort type
+which is used to specify a "host:port" network address on a configurature
+struct
+*/
+package configurature
+
+import (
+	"fmt"
+	"net"
+)
+
+// Type representing a "host:port" network address. IPv6 hosts must be
+// enclosed in brackets. E.g. "[::1]:8080".
+type HostPort string
+
+func (h *HostPort) String() string {
+	return (string)(*h)
+}
+
+func (h *HostPort) Set(v string) error {
+	_, port, err := net.SplitHostPort(v)
+	if err != nil {
+		return fmt.Errorf("invalid host:port \"%s\": %w", v, err)
+	}
+	if err := new(ListenPort).Set(port); err != nil {
+		return fmt.Errorf("invalid host:port \"%s\": %w", v, err)
+	}
+	*h = (HostPort)(v)
+	return nil
+}
+
+func (h *HostPort) Type() string {
+	return "hostPort"
+}
+
+// Host returns the host part of the address without IPv6 brackets
+func (h HostPort) Host() string {
+	host, _, _ := net.SplitHostPort(string(h))
+	return host
+}
+
+// Port returns the port part of the address, which may be 0 to listen on
+// any free port. It returns an error if h was not set to a valid address,
+// e.g. when converted from a string rather than configured.
+func (h HostPort) Port() (ListenPort, error) {
+	_, port, err := net.SplitHostPort(string(h))
+	if err != nil {
+		return 0, fmt.Errorf("invalid host:port \"%s\": %w", h, err)
+	}
+	var p ListenPort
+	if err := p.Set(port); err != nil {
+		return 0, fmt.Errorf("invalid host:port \"%s\": %w", h, err)
+	}
+	return p, nil
+}
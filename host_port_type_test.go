@@ -0,0 +1,66 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestHostPort(t *testing.T) {
+	type HPConf struct {
+		ListenAddress co.HostPort   `default:"localhost:3144"`
+		Peers         []co.HostPort `default:"a:1,[::1]:2"`
+	}
+	assert := assert.New(t)
+
+	c := co.Configure[HPConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--listen_address", "[fe80::1]:443"},
+	})
+
+	assert.Equal(co.HostPort("[fe80::1]:443"), c.ListenAddress)
+	assert.Equal("fe80::1", c.ListenAddress.Host())
+	port, err := c.ListenAddress.Port()
+	assert.NoError(err)
+	assert.Equal(co.ListenPort(443), port)
+	assert.Equal([]co.HostPort{"a:1", "[::1]:2"}, c.Peers)
+	assert.Equal("::1", c.Peers[1].Host())
+}
+
+func TestHostPort_Invalid(t *testing.T) {
+	assert := assert.New(t)
+	h := new(co.HostPort)
+
+	assert.EqualError(h.Set("localhost"), `invalid host:port "localhost": address localhost: missing port in address`)
+	assert.EqualError(h.Set("::1:80"), `invalid host:port "::1:80": address ::1:80: too many colons in address`)
+	assert.EqualError(h.Set("localhost:99999"), `invalid host:port "localhost:99999": invalid port "99999": must be a number between 0 and 65535`)
+}
+
+func TestHostPort_Port(t *testing.T) {
+	assert := assert.New(t)
+
+	port, err := co.HostPort(":0").Port()
+	assert.NoError(err)
+	assert.Equal(co.ListenPort(0), port)
+
+	_, err = co.HostPort("localhost").Port()
+	assert.EqualError(err, `invalid host:port "localhost": address localhost: missing port in address`)
+	_, err = co.HostPort("localhost:http").Port()
+	assert.EqualError(err, `invalid host:port "localhost:http": invalid port "http": must be a number between 0 and 65535`)
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the --init wizard, which creates a config file from
+values entered interactively
+*/
+package configurature
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Name of the flag that starts the wizard
+const initFlag = "init"
+
+// promptInit prompts for the value of each field that can be written to a
+// config file, showing its description, default and enum choices. Secret
+// fields are skipped; they should be kept out of config files.
+func (c *configurer) promptInit(s any, fs *pflag.FlagSet) {
+	prompter := c.prompter()
+
+	c.visitFields(s, func(f reflect.StructField, tags *reflect.StructTag, v reflect.Value, ancestors []string) (stop bool) {
+		if v.Elem().Type() == configFileType {
+			return stop
+		}
+		fName := fieldNameToConfigName(f.Name, tags, ancestors)
+		fl := fs.Lookup(fName)
+		if _, ok := internalFlags[fl.Name]; ok || fl.Hidden || isSecret(fl) {
+			return stop
+		}
+
+		label := fName
+		if fl.Usage != "" && fl.Usage != fName {
+			label += " - " + fl.Usage
+		}
+		var check func(string) error
+		if val := tags.Get("enum"); val != "" {
+			// The enum choices are already in the usage
+			enums := strings.Split(val, ",")
+			check = func(v string) error {
+				if !slices.Contains(enums, v) {
+					return fmt.Errorf("must be one of %s", strings.Join(enums, ", "))
+				}
+				return nil
+			}
+		}
+		if val := fl.Value.String(); val != "" {
+			label += fmt.Sprintf(" [%s]", val)
+		}
+
+		if _, err := c.promptFlag(prompter, fl, label, check); errors.Is(err, ErrNoTerminal) {
+			panic("--init requires a terminal")
+		} else if err != nil {
+			panic(err.Error())
+		}
+		return stop
+	}, []string{})
+}
+
+// writeInitFile writes the config file created by --init to fileName. An
+// existing file is not overwritten.
+func (c *configurer) writeInitFile(fileName string, fs *pflag.FlagSet) {
+	f, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		panic(fmt.Sprintf("error creating config file: %v", err))
+	}
+	defer f.Close()
+	c.writeYamlTemplate(f, fs, true)
+	if err := f.Close(); err != nil {
+		panic(fmt.Sprintf("error writing config file: %v", err))
+	}
+	fmt.Fprintf(c.opts.output(), "Wrote %s\n", fileName)
+}
@@ -0,0 +1,42 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type InitConf struct {
+	Conf     co.ConfigFile `help:"Configuration file"`
+	Host     string        `help:"server host" default:"localhost"`
+	LogLevel string        `help:"log level" enum:"debug,info,warn" default:"info"`
+	Password string        `secret:"" default:"hunter2"`
+	DB       struct {
+		Port int `help:"db port" default:"5432"`
+	}
+}
+
+func TestInit_NoTerminal(t *testing.T) {
+	assert.PanicsWithValue(t, "--init requires a terminal", func() {
+		co.Configure[InitConf](&co.Options{
+			NoRecover: true,
+			Args:      []string{"--init", t.TempDir() + "/conf.yaml"},
+		})
+	})
+}
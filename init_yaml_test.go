@@ -0,0 +1,77 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !configurature_core
+
+package configurature_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestInit(t *testing.T) {
+	assert := assert.New(t)
+
+	confFile := t.TempDir() + "/conf.yaml"
+	p := &testPrompter{answers: []string{"example.com", "loud", "warn", ""}}
+	errOut := &strings.Builder{}
+	out := &strings.Builder{}
+	assert.Panics(func() {
+		co.Configure[InitConf](&co.Options{
+			Args:      []string{"--init", confFile},
+			Prompter:  p,
+			Output:    out,
+			ErrOutput: errOut,
+			Exit:      func(int) {},
+		})
+	})
+	assert.Equal([]string{
+		"host - server host [localhost]",
+		"log_level - log level (debug|info|warn) [info]",
+		"log_level - log level (debug|info|warn) [info]",
+		"db_port - db port [5432]",
+	}, p.labels)
+	assert.Contains(errOut.String(), `invalid value "loud" for log_level: must be one of debug, info, warn`)
+	assert.Equal("Wrote "+confFile+"\n", out.String())
+
+	contents, err := os.ReadFile(confFile)
+	assert.NoError(err)
+	assert.Contains(string(contents), "# server host\nhost: example.com\n")
+	assert.Contains(string(contents), "db:\n\n  # db port\n  port: 5432\n")
+	assert.NotContains(string(contents), "password")
+
+	// The file can be loaded
+	c := co.Configure[InitConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--conf", confFile},
+	})
+	assert.Equal("example.com", c.Host)
+	assert.Equal("warn", c.LogLevel)
+	assert.Equal(5432, c.DB.Port)
+
+	// An existing file is not overwritten
+	assert.PanicsWithValue("error creating config file: open "+confFile+": file exists", func() {
+		co.Configure[InitConf](&co.Options{
+			NoRecover: true,
+			Args:      []string{"--init", confFile},
+			Prompter:  &testPrompter{},
+		})
+	})
+}
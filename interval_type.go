@@ -0,0 +1,218 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Value interface implementation for the Interval type
+which is used to specify schedules as either a duration or a cron expression
+on a configurature struct
+*/
+package configurature
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Type representing a schedule given as a duration, e.g. "5m", or a cron
+// expression with minute, hour, day of month, month and day of week fields,
+// e.g. "*/15 9-17 * * 1-5". The descriptors @hourly, @daily, @weekly,
+// @monthly, @yearly and "@every <duration>" are also accepted. Use Next() to
+// get the next scheduled time either way.
+type Interval struct {
+	value string
+	every time.Duration
+	cron  *cronSchedule
+}
+
+func (i *Interval) String() string {
+	return i.value
+}
+
+func (i *Interval) Set(v string) error {
+	spec := strings.TrimSpace(v)
+	if every, ok := strings.CutPrefix(spec, "@every "); ok {
+		spec = strings.TrimSpace(every)
+	} else if d, ok := cronDescriptors[spec]; ok {
+		spec = d
+	}
+
+	if d, err := time.ParseDuration(spec); err == nil {
+		if d <= 0 {
+			return fmt.Errorf("invalid interval \"%s\": duration must be positive", v)
+		}
+		*i = Interval{value: v, every: d}
+		return nil
+	}
+	cs, err := parseCron(spec)
+	if err != nil {
+		return fmt.Errorf("invalid interval \"%s\": %w", v, err)
+	}
+	*i = Interval{value: v, cron: cs}
+	return nil
+}
+
+func (i *Interval) Type() string {
+	return "interval"
+}
+
+// IsCron returns true if the interval was given as a cron expression
+func (i Interval) IsCron() bool {
+	return i.cron != nil
+}
+
+// Duration returns the duration of an interval given as a duration, or 0 if
+// it was given as a cron expression
+func (i Interval) Duration() time.Duration {
+	return i.every
+}
+
+// Next returns the first scheduled time after t. Durations are added to t,
+// and cron expressions are matched in t's location to the minute. The zero
+// time is returned if the interval is not set or the cron expression never
+// matches.
+func (i Interval) Next(t time.Time) time.Time {
+	if i.cron != nil {
+		return i.cron.next(t)
+	}
+	if i.every == 0 {
+		return time.Time{}
+	}
+	return t.Add(i.every)
+}
+
+// Cron expressions of the descriptors accepted by Interval
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronSchedule holds the values matched by each field of a cron expression
+// as bits
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool // Day fields given as "*"
+}
+
+// Ranges of the cron expression fields
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59}, {"hour", 0, 23}, {"day of month", 1, 31}, {"month", 1, 12}, {"day of week", 0, 7},
+}
+
+// parseCron parses a five field cron expression
+func parseCron(spec string) (*cronSchedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("must be a duration or a cron expression with 5 fields")
+	}
+	bits := make([]uint64, 5)
+	for n, f := range fields {
+		b, err := parseCronField(f, cronFields[n].min, cronFields[n].max)
+		if err != nil {
+			return nil, fmt.Errorf("%s field: %w", cronFields[n].name, err)
+		}
+		bits[n] = b
+	}
+	// Sunday is 0 or 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+	return &cronSchedule{
+		minute: bits[0], hour: bits[1], dom: bits[2], month: bits[3], dow: bits[4],
+		domStar: fields[2] == "*", dowStar: fields[4] == "*",
+	}, nil
+}
+
+// parseCronField parses a comma separated list of "*", values and ranges,
+// each with an optional "/step", into bits of the values matched
+func parseCronField(f string, min int, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(f, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			s, err := strconv.Atoi(stepStr)
+			if err != nil || s < 1 {
+				return 0, fmt.Errorf("invalid step \"%s\"", stepStr)
+			}
+			step = s
+		}
+
+		lo, hi := min, max
+		if rng != "*" {
+			loStr, hiStr, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(loStr); err != nil {
+				return 0, fmt.Errorf("invalid value \"%s\"", part)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(hiStr); err != nil {
+					return 0, fmt.Errorf("invalid value \"%s\"", part)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("\"%s\" is out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// next returns the first time after t, to the minute, matched by the
+// schedule, or the zero time if there is none within 5 years
+func (c *cronSchedule) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches returns whether the day of t is matched. As in cron, if both day
+// fields are restricted, a day matching either one is matched.
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
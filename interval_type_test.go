@@ -0,0 +1,87 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestInterval(t *testing.T) {
+	type IntervalConf struct {
+		Poll    co.Interval `default:"5m"`
+		Report  co.Interval `default:"*/15 9-17 * * 1-5"`
+		Cleanup co.Interval
+		Backup  co.Interval
+	}
+	assert := assert.New(t)
+
+	c := co.Configure[IntervalConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--cleanup", "@daily", "--backup", "@every 90s"},
+	})
+
+	// Friday
+	now := time.Date(2024, 5, 17, 16, 50, 30, 0, time.UTC)
+
+	assert.False(c.Poll.IsCron())
+	assert.Equal(5*time.Minute, c.Poll.Duration())
+	assert.Equal(now.Add(5*time.Minute), c.Poll.Next(now))
+
+	assert.True(c.Report.IsCron())
+	assert.Equal(time.Duration(0), c.Report.Duration())
+	assert.Equal(time.Date(2024, 5, 17, 17, 0, 0, 0, time.UTC), c.Report.Next(now))
+	assert.Equal(time.Date(2024, 5, 20, 9, 0, 0, 0, time.UTC),
+		c.Report.Next(time.Date(2024, 5, 17, 17, 45, 0, 0, time.UTC)))
+	assert.Equal("*/15 9-17 * * 1-5", c.Report.String())
+
+	assert.Equal(time.Date(2024, 5, 18, 0, 0, 0, 0, time.UTC), c.Cleanup.Next(now))
+	assert.Equal(90*time.Second, c.Backup.Duration())
+}
+
+func TestInterval_Cron(t *testing.T) {
+	assert := assert.New(t)
+	i := new(co.Interval)
+	from := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	// Both day fields restricted match either one
+	assert.Nil(i.Set("0 0 15 * 0"))
+	assert.Equal(time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC), i.Next(from))
+
+	// Sunday may be 7
+	assert.Nil(i.Set("30 6 * * 7"))
+	assert.Equal(time.Date(2024, 2, 4, 6, 30, 0, 0, time.UTC), i.Next(from))
+
+	assert.Nil(i.Set("0 0 29 2 *"))
+	assert.Equal(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), i.Next(from))
+
+	assert.Nil(i.Set("0 0 31 2 *"))
+	assert.True(i.Next(from).IsZero())
+}
+
+func TestInterval_Invalid(t *testing.T) {
+	assert := assert.New(t)
+	i := new(co.Interval)
+
+	assert.EqualError(i.Set("soon"), `invalid interval "soon": must be a duration or a cron expression with 5 fields`)
+	assert.EqualError(i.Set("-5m"), `invalid interval "-5m": duration must be positive`)
+	assert.EqualError(i.Set("60 * * * *"), `invalid interval "60 * * * *": minute field: "60" is out of range 0-59`)
+	assert.EqualError(i.Set("* * * * mon"), `invalid interval "* * * * mon": day of week field: invalid value "mon"`)
+	assert.EqualError(i.Set("*/0 * * * *"), `invalid interval "*/0 * * * *": minute field: invalid step "0"`)
+}
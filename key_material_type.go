@@ -0,0 +1,96 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Value interface implementation for the KeyMaterial type
+which is used to specify certificates, keys and other secret material either
+inline or as a file on a configurature struct
+*/
+package configurature
+
+import (
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Prefix of inline base64 KeyMaterial values
+const keyMaterialBase64Prefix = "base64:"
+
+// Type representing certificates, keys and other secret material given
+// inline or as the path of a file holding it. Values starting with
+// "-----BEGIN" are inline PEM, values starting with "base64:" are inline base64
+// and anything else is the path of a file, which is read when the value is
+// set. Inline values are redacted in templates.
+type KeyMaterial struct {
+	value string
+	path  string
+	data  []byte
+}
+
+func (k *KeyMaterial) String() string {
+	return k.value
+}
+
+func (k *KeyMaterial) Set(v string) error {
+	m := KeyMaterial{value: v}
+	switch {
+	case strings.HasPrefix(strings.TrimSpace(v), "-----BEGIN"):
+		if b, _ := pem.Decode([]byte(v)); b == nil {
+			return fmt.Errorf("invalid key material: not valid PEM")
+		}
+		m.data = []byte(v)
+	case strings.HasPrefix(v, keyMaterialBase64Prefix):
+		d, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(v, keyMaterialBase64Prefix))
+		if err != nil {
+			return fmt.Errorf("invalid key material: not valid base64")
+		}
+		m.data = d
+	case v != "":
+		d, err := os.ReadFile(v)
+		if err != nil {
+			return fmt.Errorf("invalid key material: %w", err)
+		}
+		m.path = v
+		m.data = d
+	}
+	*k = m
+	return nil
+}
+
+func (k *KeyMaterial) Type() string {
+	return "keyMaterial"
+}
+
+// Bytes returns the material
+func (k KeyMaterial) Bytes() []byte {
+	return k.data
+}
+
+// Path returns the path of the file the material was read from, or "" if it
+// was given inline
+func (k KeyMaterial) Path() string {
+	return k.path
+}
+
+// Redacted returns "xxxxx" if the material was given inline, or else the path
+// of its file. It is used when printing templates.
+func (k KeyMaterial) Redacted() string {
+	if k.path == "" && k.value != "" {
+		return redactedValue
+	}
+	return k.value
+}
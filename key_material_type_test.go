@@ -0,0 +1,70 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+const testPEM = "-----BEGIN CERTIFICATE-----\nMIIBszCCAVmgAwIBAgIUQ7c=\n-----END CERTIFICATE-----\n"
+
+type KeyMaterialConf struct {
+	Cert co.KeyMaterial
+	Key  co.KeyMaterial
+	Salt co.KeyMaterial
+	None co.KeyMaterial
+}
+
+func TestKeyMaterial(t *testing.T) {
+	assert := assert.New(t)
+	keyFile := filepath.Join(t.TempDir(), "key.pem")
+	os.WriteFile(keyFile, []byte(testPEM), 0600)
+
+	c := co.Configure[KeyMaterialConf](&co.Options{
+		NoRecover: true,
+		EnvPrefix: "KM_TEST_",
+		Environ:   map[string]string{"KM_TEST_CERT": testPEM},
+		Args: []string{"--key", keyFile, "--salt",
+			"base64:" + base64.StdEncoding.EncodeToString([]byte("s4lt"))},
+	})
+
+	assert.Equal([]byte(testPEM), c.Cert.Bytes())
+	assert.Equal("", c.Cert.Path())
+	assert.Equal("xxxxx", c.Cert.Redacted())
+	assert.Equal([]byte(testPEM), c.Key.Bytes())
+	assert.Equal(keyFile, c.Key.Path())
+	assert.Equal(keyFile, c.Key.Redacted())
+	assert.Equal([]byte("s4lt"), c.Salt.Bytes())
+	assert.Nil(c.None.Bytes())
+	assert.Equal("", c.None.Redacted())
+}
+
+func TestKeyMaterial_Invalid(t *testing.T) {
+	assert := assert.New(t)
+	k := new(co.KeyMaterial)
+
+	assert.EqualError(k.Set("-----BEGIN CERTIFICATE-----\nnope"), "invalid key material: not valid PEM")
+	assert.EqualError(k.Set("base64:!!"), "invalid key material: not valid base64")
+	err := k.Set("/nonexistent/key.pem")
+	assert.True(strings.HasPrefix(err.Error(), "invalid key material: open /nonexistent/key.pem"))
+}
@@ -0,0 +1,107 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the credential store secret source
+*/
+package configurature
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// ErrSecretNotFound is returned by a Keyring when no secret is stored for a
+// key
+var ErrSecretNotFound = errors.New("secret not found")
+
+// Keyring is a credential store that fields tagged secret:"" can be loaded
+// from
+type Keyring interface {
+	// Get returns the secret stored for key under service
+	Get(service, key string) (string, error)
+}
+
+// ContextKeyring is a Keyring whose lookups can be bounded by a context. It
+// is used by ConfigureContext.
+type ContextKeyring interface {
+	Keyring
+
+	// GetContext returns the secret stored for key under service
+	GetContext(ctx context.Context, service, key string) (string, error)
+}
+
+// OSKeyring returns the operating system's credential store: the macOS
+// Keychain (using the security command), the Secret Service (using the
+// secret-tool command), or the Windows Credential Manager. Secrets are looked
+// up by service and account name on macOS, by "service" and "username"
+// attributes with the Secret Service, and by the target name
+// "<service>:<key>" on Windows.
+func OSKeyring() Keyring {
+	return osKeyring{}
+}
+
+// setFromKeyring adds setters for values of fields tagged secret:"" found in
+// the credential store to values. Fields specified on the command line or in
+// the environment are not looked up. If the credential store's command is not
+// installed, a warning is printed and no secrets are looked up.
+func (c *configurer) setFromKeyring(fs *pflag.FlagSet, values sourceSetters) {
+	keyring := c.opts.Keyring
+	if keyring == nil {
+		keyring = OSKeyring()
+	}
+
+	unavailable := false
+	fs.VisitAll(func(fl *pflag.Flag) {
+		if unavailable || !isSecret(fl) || fl.Changed || values[fl.Name].source == sourceEnv {
+			return
+		}
+		fName := fl.Name
+
+		var secret string
+		var err error
+		if ck, ok := keyring.(ContextKeyring); ok && c.ctx != nil {
+			secret, err = ck.GetContext(c.ctx, c.opts.KeyringService, fName)
+		} else {
+			secret, err = keyring.Get(c.opts.KeyringService, fName)
+		}
+		if errors.Is(err, ErrSecretNotFound) {
+			return
+		} else if errors.Is(err, exec.ErrNotFound) {
+			fmt.Fprintf(c.opts.errOutput(), "warning: keyring unavailable: %v\n", err)
+			unavailable = true
+			return
+		} else if err != nil {
+			panic(fmt.Sprintf("error reading secret %s from keyring: %v", fName, err))
+		}
+		values[fName] = sourceSetter{sourceKeyring, func() {
+			if err := setFlagValue(fName, secret, fs); err != nil {
+				c.errors = append(c.errors, maskSecret(fl, fmt.Sprintf("setFromKeyring(): error setting value of field %s: %v", fName, err), secret))
+			}
+		}}
+	})
+}
+
+// commandStderr returns the stderr output of a failed command as an error
+func commandStderr(err error) error {
+	if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+		return errors.New(strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return nil
+}
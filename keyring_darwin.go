@@ -0,0 +1,50 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the macOS Keychain credential store
+*/
+package configurature
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// osKeyring reads secrets from the macOS Keychain
+type osKeyring struct{}
+
+// Exit code of the security command when an item could not be found
+const securityItemNotFound = 44
+
+// Get returns the generic password stored for account key and service
+func (k osKeyring) Get(service, key string) (string, error) {
+	return k.GetContext(context.Background(), service, key)
+}
+
+// GetContext is Get bounded by ctx
+func (osKeyring) GetContext(ctx context.Context, service, key string) (string, error) {
+	out, err := exec.CommandContext(ctx, "security", "find-generic-password", "-s", service, "-a", key, "-w").Output()
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == securityItemNotFound {
+		return "", ErrSecretNotFound
+	} else if err != nil {
+		return "", errors.Join(err, commandStderr(err))
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
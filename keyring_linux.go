@@ -0,0 +1,50 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Secret Service credential store
+*/
+package configurature
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// osKeyring reads secrets from the Secret Service (e.g. GNOME Keyring or
+// KWallet)
+type osKeyring struct{}
+
+// Get returns the secret stored with the attributes service and username=key
+func (k osKeyring) Get(service, key string) (string, error) {
+	return k.GetContext(context.Background(), service, key)
+}
+
+// GetContext is Get bounded by ctx
+func (osKeyring) GetContext(ctx context.Context, service, key string) (string, error) {
+	out, err := exec.CommandContext(ctx, "secret-tool", "lookup", "service", service, "username", key).Output()
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) == 0 {
+		// secret-tool exits with an error and no message when nothing was
+		// found
+		return "", ErrSecretNotFound
+	} else if err != nil {
+		return "", errors.Join(err, commandStderr(err))
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !darwin && !linux && !windows
+
+/*
+This file contains the credential store for platforms without one
+*/
+package configurature
+
+import (
+	"context"
+	"errors"
+)
+
+// osKeyring is a credential store that is not supported on this platform
+type osKeyring struct{}
+
+// Get always returns an error
+func (osKeyring) Get(service, key string) (string, error) {
+	return "", errors.New("no OS credential store is supported on this platform")
+}
+
+// GetContext is Get, which does not block, if ctx is not done
+func (k osKeyring) GetContext(ctx context.Context, service, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return k.Get(service, key)
+}
@@ -0,0 +1,154 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+// mapKeyring is a Keyring backed by a map of "<service>/<key>" to secret
+type mapKeyring map[string]string
+
+func (m mapKeyring) Get(service, key string) (string, error) {
+	if v, ok := m[service+"/"+key]; ok {
+		return v, nil
+	}
+	return "", co.ErrSecretNotFound
+}
+
+type errKeyring struct{}
+
+func (errKeyring) Get(service, key string) (string, error) {
+	return "", errors.New("locked")
+}
+
+// blockingKeyring blocks until its context is done
+type blockingKeyring struct{ errKeyring }
+
+func (blockingKeyring) GetContext(ctx context.Context, service, key string) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+type KeyringConfig struct {
+	Token string `secret:""`
+	User  string `default:"me"`
+	DB    struct {
+		Password string `secret:""`
+	}
+}
+
+func TestKeyring(t *testing.T) {
+	kr := mapKeyring{
+		"myapp/token":       "tok",
+		"myapp/user":        "not used",
+		"myapp/db_password": "pw",
+	}
+	c := co.Configure[KeyringConfig](&co.Options{
+		Args:           []string{},
+		KeyringService: "myapp",
+		Keyring:        kr,
+	})
+
+	assert := assert.New(t)
+	assert.Equal("tok", c.Token)
+	assert.Equal("me", c.User)
+	assert.Equal("pw", c.DB.Password)
+}
+
+func TestKeyring_NotFound(t *testing.T) {
+	c := co.Configure[KeyringConfig](&co.Options{
+		Args:           []string{},
+		KeyringService: "myapp",
+		Keyring:        mapKeyring{"other/token": "tok"},
+	})
+	assert.Equal(t, "", c.Token)
+}
+
+func TestKeyring_NoService(t *testing.T) {
+	c := co.Configure[KeyringConfig](&co.Options{
+		Args:    []string{},
+		Keyring: errKeyring{},
+	})
+	assert.Equal(t, "", c.Token)
+}
+
+func TestKeyring_Precedence(t *testing.T) {
+	t.Setenv("APP_DB_PASSWORD", "envpw")
+	c := co.Configure[KeyringConfig](&co.Options{
+		Args:           []string{"--token", "flagtok"},
+		EnvPrefix:      "APP_",
+		KeyringService: "myapp",
+		// Errors would panic if flag or environment values were looked up
+		Keyring: errKeyring{},
+	})
+
+	assert := assert.New(t)
+	assert.Equal("flagtok", c.Token)
+	assert.Equal("envpw", c.DB.Password)
+}
+
+func TestKeyring_Error(t *testing.T) {
+	assert.PanicsWithValue(t, "error reading secret token from keyring: locked", func() {
+		co.Configure[KeyringConfig](&co.Options{
+			Args:           []string{"--db_password", "x"},
+			NoRecover:      true,
+			KeyringService: "myapp",
+			Keyring:        errKeyring{},
+		})
+	})
+}
+
+func TestKeyring_Context(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.PanicsWithValue(t, "error reading secret token from keyring: context deadline exceeded", func() {
+		co.ConfigureContext[KeyringConfig](ctx, &co.Options{
+			Args:           []string{"--db_password", "x"},
+			NoRecover:      true,
+			KeyringService: "myapp",
+			Keyring:        blockingKeyring{},
+		})
+	})
+}
+
+func TestKeyring_CommandNotInstalled(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("the credential store is not read with a command on " + runtime.GOOS)
+	}
+	t.Setenv("PATH", t.TempDir())
+
+	errOut := &strings.Builder{}
+	c := co.Configure[KeyringConfig](&co.Options{
+		Args:           []string{},
+		NoRecover:      true,
+		KeyringService: "myapp",
+		ErrOutput:      errOut,
+	})
+
+	assert.Equal(t, "", c.Token)
+	assert.Equal(t, 1, strings.Count(errOut.String(), "warning: keyring unavailable: "))
+	assert.Contains(t, errOut.String(), "executable file not found")
+}
@@ -0,0 +1,116 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Windows Credential Manager credential store
+*/
+package configurature
+
+import (
+	"bytes"
+	"context"
+	"syscall"
+	"unicode/utf16"
+	"unicode/utf8"
+	"unsafe"
+)
+
+var (
+	advapi32     = syscall.NewLazyDLL("advapi32.dll")
+	procCredRead = advapi32.NewProc("CredReadW")
+	procCredFree = advapi32.NewProc("CredFree")
+)
+
+const (
+	// CRED_TYPE_GENERIC
+	credTypeGeneric = 1
+
+	// ERROR_NOT_FOUND
+	errNotFound = syscall.Errno(1168)
+)
+
+// credential mirrors the Windows CREDENTIALW struct
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// osKeyring reads secrets from the Windows Credential Manager
+type osKeyring struct{}
+
+// Get returns the generic credential stored with target name
+// "<service>:<key>"
+func (osKeyring) Get(service, key string) (string, error) {
+	target, err := syscall.UTF16PtrFromString(service + ":" + key)
+	if err != nil {
+		return "", err
+	}
+
+	var cred *credential
+	r, _, err := procCredRead.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if r == 0 {
+		if err == errNotFound {
+			return "", ErrSecretNotFound
+		}
+		return "", err
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	return decodeCredentialBlob(unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)), nil
+}
+
+// decodeCredentialBlob returns the secret in b. Blobs saved by cmdkey or the
+// Windows credential UI are UTF-16LE, and others are usually UTF-8. b is
+// decoded as UTF-16LE if it has an even length, holds valid UTF-16, and
+// either contains a NUL byte, which UTF-8 text doesn't, or isn't UTF-8.
+func decodeCredentialBlob(b []byte) string {
+	if len(b)%2 != 0 || (bytes.IndexByte(b, 0) < 0 && utf8.Valid(b)) {
+		return string(b)
+	}
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = uint16(b[2*i]) | uint16(b[2*i+1])<<8
+	}
+	// NUL characters and unpaired surrogates aren't text
+	for i := 0; i < len(u); i++ {
+		switch {
+		case u[i] == 0:
+			return string(b)
+		case utf16.IsSurrogate(rune(u[i])):
+			if u[i] >= 0xdc00 || i+1 == len(u) || u[i+1] < 0xdc00 || u[i+1] > 0xdfff {
+				return string(b)
+			}
+			i++
+		}
+	}
+	return string(utf16.Decode(u))
+}
+
+// GetContext is Get, which does not block, if ctx is not done
+func (k osKeyring) GetContext(ctx context.Context, service, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return k.Get(service, key)
+}
@@ -0,0 +1,38 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeCredentialBlob(t *testing.T) {
+	for _, tt := range []struct {
+		blob []byte
+		want string
+	}{
+		{[]byte("s3cret"), "s3cret"},
+		{[]byte("p\xc3\xa4ss"), "päss"},
+		{[]byte("s\x003\x00c\x00r\x00e\x00t\x00"), "s3cret"},
+		{[]byte("p\x00\xe4\x00s\x00s\x00"), "päss"},
+		{[]byte("\x3d\xd8\x11\xdd"), "\U0001f511"},
+		// Unpaired surrogates aren't UTF-16
+		{[]byte("\x3d\xd8a\x00"), "\x3d\xd8a\x00"},
+	} {
+		assert.Equal(t, tt.want, decodeCredentialBlob(tt.blob))
+	}
+}
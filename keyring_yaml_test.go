@@ -0,0 +1,45 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !configurature_core
+
+package configurature_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestKeyring_OverridesFile(t *testing.T) {
+	type Conf struct {
+		Conf  co.ConfigFile
+		Token string `secret:""`
+	}
+	f, err := os.CreateTemp("", "keyring*.yaml")
+	assert.Nil(t, err)
+	defer os.Remove(f.Name())
+	f.WriteString("token: filetok\n")
+	f.Close()
+
+	c := co.Configure[Conf](&co.Options{
+		Args:           []string{"--conf", f.Name()},
+		KeyringService: "myapp",
+		Keyring:        mapKeyring{"myapp/token": "tok"},
+	})
+	assert.Equal(t, "tok", c.Token)
+}
@@ -0,0 +1,137 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the LazySecret type, whose value is a reference to a secret
+that is fetched from a secret store, such as Vault or SSM, when it is first
+used rather than when the configuration is loaded
+*/
+package configurature
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/spf13/pflag"
+)
+
+// SecretResolver fetches secrets referenced by LazySecret fields from a
+// secret store
+type SecretResolver interface {
+	// Resolve returns the secret referenced by ref, which excludes the scheme
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// Type representing a reference to a secret in the form "<scheme>:<ref>",
+// e.g. "vault:secret/data/db#password", which is resolved by the
+// SecretResolver registered for the scheme in Options.SecretResolvers. The
+// secret is fetched on the first call to Get() and cached until Refresh() is
+// called.
+type LazySecret struct {
+	ref     string
+	resolve func(ctx context.Context, refresh bool) (string, bool, error)
+}
+
+func (s *LazySecret) String() string {
+	return s.ref
+}
+
+func (s *LazySecret) Set(v string) error {
+	scheme, ref, ok := strings.Cut(v, ":")
+	if !ok || scheme == "" || ref == "" {
+		return fmt.Errorf("invalid secret reference \"%s\": must be <scheme>:<ref>", v)
+	}
+	*s = LazySecret{ref: v}
+	return nil
+}
+
+func (s *LazySecret) Type() string {
+	return "secretRef"
+}
+
+// Scheme returns the scheme of the secret reference, e.g. "vault"
+func (s LazySecret) Scheme() string {
+	scheme, _, _ := strings.Cut(s.ref, ":")
+	return scheme
+}
+
+// Get returns the secret, fetching it if it hasn't been fetched yet. Failed
+// fetches are retried by the next call.
+func (s LazySecret) Get(ctx context.Context) (string, error) {
+	if s.ref == "" {
+		return "", errors.New("secret reference is not set")
+	}
+	if s.resolve == nil {
+		return "", fmt.Errorf("secret reference %s was not loaded by Configure", s.ref)
+	}
+	secret, _, err := s.resolve(ctx, false)
+	return secret, err
+}
+
+// Refresh fetches the secret again if it has been fetched, so that Get()
+// returns the new secret after it is rotated. It returns whether the secret
+// changed. The cached secret is kept if fetching fails.
+func (s LazySecret) Refresh(ctx context.Context) (bool, error) {
+	if s.resolve == nil {
+		return false, nil
+	}
+	_, changed, err := s.resolve(ctx, true)
+	return changed, err
+}
+
+// bindLazySecrets gives the values of LazySecret flags their SecretResolver.
+// References without a registered resolver are errors.
+func (c *configurer) bindLazySecrets(fs *pflag.FlagSet) {
+	fs.VisitAll(func(fl *pflag.Flag) {
+		s, ok := unwrapValue(fl.Value).(*LazySecret)
+		if !ok || s.ref == "" {
+			return
+		}
+		r, ok := c.opts.SecretResolvers[s.Scheme()]
+		if !ok {
+			c.errors = append(c.errors, fmt.Sprintf("no secret resolver for %s scheme of %s", s.Scheme(), fl.Name))
+			return
+		}
+		s.resolve = cachedResolve(r, strings.TrimPrefix(s.ref, s.Scheme()+":"))
+	})
+}
+
+// cachedResolve returns a function that resolves ref with r and caches the
+// secret once it has been resolved. If refresh is true, a cached secret is
+// resolved again and whether it changed is returned.
+func cachedResolve(r SecretResolver, ref string) func(context.Context, bool) (string, bool, error) {
+	var (
+		mu       sync.Mutex
+		secret   string
+		resolved bool
+	)
+	return func(ctx context.Context, refresh bool) (string, bool, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		// Get a cached secret, or refresh one that has been fetched
+		if resolved != refresh {
+			return secret, false, nil
+		}
+		v, err := r.Resolve(ctx, ref)
+		if err != nil {
+			return secret, false, fmt.Errorf("error resolving secret %s: %w", ref, err)
+		}
+		changed := resolved && v != secret
+		secret, resolved = v, true
+		return secret, changed, nil
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+// testResolver resolves secrets from a map and counts lookups
+type testResolver struct {
+	mu      sync.Mutex
+	secrets map[string]string
+	calls   int
+}
+
+func (r *testResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	s, ok := r.secrets[ref]
+	if !ok {
+		return "", co.ErrSecretNotFound
+	}
+	return s, nil
+}
+
+func TestLazySecret(t *testing.T) {
+	type Conf struct {
+		DBPassword co.LazySecret `default:"vault:db#password"`
+		APIKey     co.LazySecret
+		Unset      co.LazySecret
+	}
+	assert := assert.New(t)
+	vault := &testResolver{secrets: map[string]string{"db#password": "hunter2"}}
+	ssm := &testResolver{secrets: map[string]string{}}
+
+	c := co.Configure[Conf](&co.Options{
+		NoRecover:       true,
+		Args:            []string{"--api_key", "ssm:/prod/api_key"},
+		SecretResolvers: map[string]co.SecretResolver{"vault": vault, "ssm": ssm},
+	})
+
+	// Nothing is fetched until it's used
+	assert.Equal(0, vault.calls)
+	assert.Equal("vault", c.DBPassword.Scheme())
+	assert.Equal("vault:db#password", c.DBPassword.String())
+
+	for range 2 {
+		s, err := c.DBPassword.Get(context.Background())
+		assert.NoError(err)
+		assert.Equal("hunter2", s)
+	}
+	assert.Equal(1, vault.calls)
+
+	// Failures are retried
+	_, err := c.APIKey.Get(context.Background())
+	assert.True(errors.Is(err, co.ErrSecretNotFound))
+	assert.EqualError(err, "error resolving secret /prod/api_key: secret not found")
+	ssm.secrets["/prod/api_key"] = "k3y"
+	s, err := c.APIKey.Get(context.Background())
+	assert.NoError(err)
+	assert.Equal("k3y", s)
+
+	_, err = c.Unset.Get(context.Background())
+	assert.EqualError(err, "secret reference is not set")
+}
+
+func TestLazySecret_Invalid(t *testing.T) {
+	type Conf struct {
+		Token co.LazySecret
+	}
+	assert := assert.New(t)
+
+	assert.PanicsWithValue("no secret resolver for vault scheme of token", func() {
+		co.Configure[Conf](&co.Options{NoRecover: true, Args: []string{"--token", "vault:token"}})
+	})
+	assert.EqualError(new(co.LazySecret).Set("hunter2"), `invalid secret reference "hunter2": must be <scheme>:<ref>`)
+
+	var s co.LazySecret
+	assert.NoError(s.Set("vault:token"))
+	_, err := s.Get(context.Background())
+	assert.EqualError(err, "secret reference vault:token was not loaded by Configure")
+}
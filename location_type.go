@@ -0,0 +1,61 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Value interface implementation used for *time.Location
+struct fields
+*/
+package configurature
+
+import (
+	"fmt"
+	"time"
+)
+
+// locationValue is the Value used for *time.Location struct fields. Values
+// are IANA time zone names such as "America/New_York" or "UTC".
+type locationValue struct {
+	loc *time.Location
+}
+
+func (l *locationValue) String() string {
+	if l.loc == nil {
+		return ""
+	}
+	return l.loc.String()
+}
+
+func (l *locationValue) Set(v string) error {
+	// time.LoadLocation treats "" as UTC, which would hide a missing value
+	if v == "" {
+		return fmt.Errorf("invalid time zone \"\"")
+	}
+	loc, err := time.LoadLocation(v)
+	if err != nil {
+		return fmt.Errorf("invalid time zone \"%s\": %w", v, err)
+	}
+	l.loc = loc
+	return nil
+}
+
+func (l *locationValue) Type() string {
+	return "timeZone"
+}
+
+func (l *locationValue) Interface() any {
+	if l.loc == nil {
+		return nil
+	}
+	return l.loc
+}
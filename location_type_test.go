@@ -0,0 +1,55 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestLocation(t *testing.T) {
+	type TZConf struct {
+		Zone    *time.Location `default:"UTC"`
+		Display *time.Location `help:"display time zone"`
+	}
+	assert := assert.New(t)
+
+	c := co.Configure[TZConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--display", "America/New_York"},
+	})
+
+	assert.Same(time.UTC, c.Zone)
+	assert.Equal("America/New_York", c.Display.String())
+	_, offset := time.Date(2024, 1, 1, 0, 0, 0, 0, c.Display).Zone()
+	assert.Equal(-5*60*60, offset)
+}
+
+func TestLocation_Invalid(t *testing.T) {
+	type TZConf struct {
+		Zone *time.Location `default:"Mars/Olympus_Mons"`
+	}
+
+	assert.PanicsWithValue(t, `Error setting default value for field zone: invalid time zone "Mars/Olympus_Mons": unknown time zone Mars/Olympus_Mons`, func() {
+		co.Configure[TZConf](&co.Options{
+			NoRecover: true,
+			Args:      []string{},
+		})
+	})
+}
@@ -0,0 +1,42 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains functions that add map value types for third party logging
+library levels. The level types are type parameters so that this package does
+not depend on the logging libraries.
+*/
+package configurature
+
+// AddZapLevelType adds a map value type for zap log levels. Call it with
+// zapcore.Level before running Configure:
+//
+//	co.AddZapLevelType[zapcore.Level]()
+func AddZapLevelType[T ~int8]() {
+	AddMapValueType("",
+		[]string{"debug", "info", "warn", "error", "dpanic", "panic", "fatal"},
+		[]T{-1, 0, 1, 2, 3, 4, 5},
+	)
+}
+
+// AddZerologLevelType adds a map value type for zerolog log levels. Call it
+// with zerolog.Level before running Configure:
+//
+//	co.AddZerologLevelType[zerolog.Level]()
+func AddZerologLevelType[T ~int8]() {
+	AddMapValueType("",
+		[]string{"trace", "debug", "info", "warn", "error", "fatal", "panic", "disabled"},
+		[]T{-1, 0, 1, 2, 3, 4, 5, 7},
+	)
+}
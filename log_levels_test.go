@@ -0,0 +1,56 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+// Stand-ins for zapcore.Level and zerolog.Level
+type zapLevel int8
+type zerologLevel int8
+
+func TestAddZapLevelType(t *testing.T) {
+	co.AddZapLevelType[zapLevel]()
+	type ZConf struct {
+		LogLevel zapLevel `default:"info"`
+	}
+
+	c := co.Configure[ZConf](&co.Options{NoRecover: true, Args: []string{}})
+	assert.Equal(t, zapLevel(0), c.LogLevel)
+
+	c = co.Configure[ZConf](&co.Options{NoRecover: true, Args: []string{"--log_level", "WARN"}})
+	assert.Equal(t, zapLevel(1), c.LogLevel)
+
+	c = co.Configure[ZConf](&co.Options{NoRecover: true, Args: []string{"--log_level", "debug"}})
+	assert.Equal(t, zapLevel(-1), c.LogLevel)
+}
+
+func TestAddZerologLevelType(t *testing.T) {
+	co.AddZerologLevelType[zerologLevel]()
+	type ZConf struct {
+		LogLevel zerologLevel `default:"info"`
+	}
+
+	c := co.Configure[ZConf](&co.Options{NoRecover: true, Args: []string{}})
+	assert.Equal(t, zerologLevel(1), c.LogLevel)
+
+	c = co.Configure[ZConf](&co.Options{NoRecover: true, Args: []string{"--log_level", "disabled"}})
+	assert.Equal(t, zerologLevel(7), c.LogLevel)
+}
@@ -19,7 +19,9 @@ package configurature
 
 import (
 	"fmt"
+	"maps"
 	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/spf13/pflag"
@@ -33,6 +35,18 @@ var (
 // AddMapValueType takes a slice of string keys and values and registers it as
 // a string->value map Configurature type.
 func AddMapValueType[T any](typeName string, keys []string, values []T) {
+	addMapValueType(nil, typeName, keys, values)
+}
+
+// AddMapValueTypeTo is AddMapValueType for the types registry rather than the
+// customFlagMap used by every Configure call
+func AddMapValueTypeTo[T any](types *Types, typeName string, keys []string, values []T) {
+	addMapValueType(types, typeName, keys, values)
+}
+
+// addMapValueType registers a map value type in types, or in the
+// customFlagMap if types is nil
+func addMapValueType[T any](types *Types, typeName string, keys []string, values []T) {
 
 	nm := make(map[string]T)
 	for idx := 0; idx < len(keys); idx++ {
@@ -40,9 +54,7 @@ func AddMapValueType[T any](typeName string, keys []string, values []T) {
 		nm[strings.ToLower(keys[idx])] = values[idx]
 	}
 
-	mapValueTypeKeys[reflect.TypeFor[T]().String()] = keys
-
-	customFlagMap[reflect.TypeFor[T]()] = func(name string, short string, def string, help string, fs *pflag.FlagSet) {
+	fn := func(name string, short string, def string, help string, fs *pflag.FlagSet) {
 		l := &mapValueType[T]{
 			mapping:  nm,
 			typeName: typeName,
@@ -63,11 +75,24 @@ func AddMapValueType[T any](typeName string, keys []string, values []T) {
 			},
 		)
 	}
+
+	registerType(types, reflect.TypeFor[T](), fn, keys)
 }
 
 // getMapValueTypeValues returns a pointer to the values in the mapping for a
-// mapValueType or nil if it does not exist
-func getMapValueTypeValues(reflectType string) *[]string {
+// mapValueType in types, or else in the customFlagMap, or nil if it does not
+// exist
+func getMapValueTypeValues(types *Types, reflectType string) *[]string {
+	if types != nil {
+		types.mu.RLock()
+		values, ok := types.mapValueTypeKeys[reflectType]
+		types.mu.RUnlock()
+		if ok {
+			return &values
+		}
+	}
+	typesMu.RLock()
+	defer typesMu.RUnlock()
 	if values, ok := mapValueTypeKeys[reflectType]; !ok {
 		return nil
 	} else {
@@ -107,6 +132,11 @@ func (m *mapValueType[T]) Type() string {
 	return m.typeName
 }
 
+// completions returns the valid values in order
+func (m *mapValueType[T]) completions() []string {
+	return slices.Sorted(maps.Keys(m.mapping))
+}
+
 func (m *mapValueType[T]) Interface() any {
 	if m.value == "" {
 		return nil
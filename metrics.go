@@ -0,0 +1,46 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Metrics hooks called while configuration is loaded
+and reloaded
+*/
+package configurature
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Metrics receives measurements of configuration loading so that they can be
+// exported, e.g. as OpenTelemetry or Prometheus metrics, and alerted on.
+// Methods may be called from the goroutines watching Sources.
+type Metrics interface {
+	LoadDuration(d time.Duration)          // Configuration was loaded, or reloaded, in d
+	Reloaded()                             // Configuration was reloaded because a watched Source changed
+	ReloadFailed(err error)                // Reloading configuration failed. The previous configuration is kept
+	ValidationFailed(err error)            // Configuration failed validation
+	SourceFailed(source string, err error) // An attempt to load a Source failed
+}
+
+// failValidation reports the validation errors to Options.Metrics and panics
+// with them
+func failValidation(opts *Options, errs []string) {
+	msg := strings.Join(errs, ", ")
+	if opts.Metrics != nil {
+		opts.Metrics.ValidationFailed(errors.New(msg))
+	}
+	panic(msg)
+}
@@ -0,0 +1,131 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+// testMetrics records the Metrics calls made to it
+type testMetrics struct {
+	mu           sync.Mutex
+	loads        int
+	reloads      int
+	reloadErrors []string
+	validations  []string
+	sourceErrors []string
+}
+
+func (m *testMetrics) LoadDuration(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.loads++
+}
+
+func (m *testMetrics) Reloaded() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reloads++
+}
+
+func (m *testMetrics) ReloadFailed(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reloadErrors = append(m.reloadErrors, err.Error())
+}
+
+func (m *testMetrics) ValidationFailed(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.validations = append(m.validations, err.Error())
+}
+
+func (m *testMetrics) SourceFailed(source string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sourceErrors = append(m.sourceErrors, source+": "+err.Error())
+}
+
+func TestMetrics(t *testing.T) {
+	assert := assert.New(t)
+	m := &testMetrics{}
+
+	s := &testSource{name: "retry", values: map[string]any{"host": "h"}, failures: 1}
+	co.Configure[SourceConfig](&co.Options{
+		Args:        []string{},
+		NoRecover:   true,
+		Sources:     []co.Source{s},
+		SourceRetry: co.RetryPolicy{Attempts: 2, InitialDelay: time.Millisecond},
+		Metrics:     m,
+	})
+	assert.Equal(1, m.loads)
+	assert.Equal([]string{"retry: unavailable"}, m.sourceErrors)
+
+	type ReqConf struct {
+		Name  string `required:""`
+		Level string `enum:"a,b" default:"c"`
+	}
+	assert.Panics(func() {
+		co.Configure[ReqConf](&co.Options{
+			Args:      []string{},
+			NoRecover: true,
+			Metrics:   m,
+		})
+	})
+	assert.Equal(1, m.loads)
+	assert.Equal([]string{"name is required, level must be one of a, b"}, m.validations)
+}
+
+func TestMetrics_Reload(t *testing.T) {
+	t.Cleanup(co.ResetForTest)
+	assert := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := &testMetrics{}
+
+	s := newWatchSource(map[string]any{"host": "h1"})
+	changes := make(chan *WatchConfig, 1)
+	co.ConfigureContext[WatchConfig](ctx, &co.Options{
+		Args:         []string{},
+		NoRecover:    true,
+		Sources:      []co.Source{s},
+		WatchSources: true,
+		Metrics:      m,
+		OnChange: func(config any) {
+			changes <- config.(*WatchConfig)
+		},
+	})
+
+	s.updates <- map[string]any{"unknown": "x"}
+	s.updates <- map[string]any{"host": "h2"}
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("configuration was not reloaded")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	assert.Equal(2, m.loads)
+	assert.Equal(1, m.reloads)
+	assert.Len(m.reloadErrors, 1)
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the config file migration registry and its helpers
+*/
+package configurature
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Config file key holding the config file's schema version
+const configVersionKey = "config_version"
+
+// Migration upgrades the raw contents of a config file at version to a newer
+// layout. It should set the "config_version" key of the returned map to the
+// version it migrated to.
+type Migration func(version string, raw map[string]any) map[string]any
+
+var (
+	// Registered config file migrations, applied in order
+	migrations []Migration
+
+	// Protects migrations
+	migrationsMu sync.RWMutex
+)
+
+// AddMigration registers a config file migration. When Options.ConfigVersion
+// is set and a config file's "config_version" differs from it, registered
+// migrations are applied in the order they were added.
+func AddMigration(m Migration) {
+	migrationsMu.Lock()
+	defer migrationsMu.Unlock()
+	migrations = append(migrations, m)
+}
+
+// migrateConfigFile applies migrations to the raw contents of a config file
+// until its version matches Options.ConfigVersion and returns the result
+// with the version key removed
+func (c *configurer) migrateConfigFile(fileName string, raw map[string]any) map[string]any {
+	version := rawConfigVersion(raw)
+	fromVersion := version
+
+	if version != c.opts.ConfigVersion {
+		migrationsMu.RLock()
+		defer migrationsMu.RUnlock()
+
+		for _, m := range migrations {
+			raw = m(version, raw)
+			if version = rawConfigVersion(raw); version == c.opts.ConfigVersion {
+				break
+			}
+		}
+
+		if version != c.opts.ConfigVersion {
+			panic(fmt.Sprintf("unsupported config file version \"%s\" in %s: expected \"%s\"",
+				version, fileName, c.opts.ConfigVersion))
+		}
+		fmt.Fprintf(c.opts.errOutput(), "config file %s migrated from version \"%s\" to \"%s\"\n",
+			fileName, fromVersion, version)
+	}
+
+	delete(raw, configVersionKey)
+	return raw
+}
+
+// rawConfigVersion returns the version in the raw contents of a config file
+// or "" if there isn't one
+func rawConfigVersion(raw map[string]any) string {
+	if v, ok := raw[configVersionKey]; ok && v != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return ""
+}
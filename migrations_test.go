@@ -0,0 +1,71 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type MigrateConf struct {
+	Conf co.ConfigFile `help:"configuration file"`
+	DB   struct {
+		Host string
+		Port int
+	}
+}
+
+func addTestMigrations() {
+	// v1 -> v2: db_host, db_port moved to db: sub-object
+	co.AddMigration(func(version string, raw map[string]any) map[string]any {
+		if version != "1" {
+			return raw
+		}
+		raw["db"] = map[string]any{"host": raw["db_host"], "port": raw["db_port"]}
+		delete(raw, "db_host")
+		delete(raw, "db_port")
+		raw["config_version"] = "2"
+		return raw
+	})
+	// v2 -> v3: nothing changed but the version
+	co.AddMigration(func(version string, raw map[string]any) map[string]any {
+		if version == "2" {
+			raw["config_version"] = "3"
+		}
+		return raw
+	})
+}
+
+func TestMigrations_CurrentVersion(t *testing.T) {
+	t.Cleanup(co.ResetForTest)
+	co.AddMigration(func(version string, raw map[string]any) map[string]any {
+		panic("should not be called")
+	})
+
+	fileName := tmpFile(t, "json")
+	os.WriteFile(fileName, []byte(`{"config_version": "3", "db": {"port": 1}}`), 0600)
+
+	c := co.Configure[MigrateConf](&co.Options{
+		NoRecover:     true,
+		ConfigVersion: "3",
+		Args:          []string{"--conf", fileName},
+	})
+
+	assert.Equal(t, 1, c.DB.Port)
+}
@@ -0,0 +1,63 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !configurature_core
+
+package configurature_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestMigrations(t *testing.T) {
+	t.Cleanup(co.ResetForTest)
+	addTestMigrations()
+
+	fileName := tmpFile(t, "yaml")
+	os.WriteFile(fileName, []byte("config_version: 1\ndb_host: localhost\ndb_port: 5432\n"), 0600)
+
+	errOut := &bytes.Buffer{}
+	c := co.Configure[MigrateConf](&co.Options{
+		NoRecover:     true,
+		ConfigVersion: "3",
+		Args:          []string{"--conf", fileName},
+		ErrOutput:     errOut,
+	})
+
+	assert.Equal(t, "localhost", c.DB.Host)
+	assert.Equal(t, 5432, c.DB.Port)
+	assert.Equal(t, "config file "+fileName+" migrated from version \"1\" to \"3\"\n", errOut.String())
+}
+
+func TestMigrations_Unsupported(t *testing.T) {
+	t.Cleanup(co.ResetForTest)
+	addTestMigrations()
+
+	fileName := tmpFile(t, "yaml")
+	os.WriteFile(fileName, []byte("config_version: 0\n"), 0600)
+
+	assert.PanicsWithValue(t, `unsupported config file version "0" in `+fileName+`: expected "3"`, func() {
+		co.Configure[MigrateConf](&co.Options{
+			NoRecover:     true,
+			ConfigVersion: "3",
+			Args:          []string{"--conf", fileName},
+		})
+	})
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Value interface implementations for the MIMEType and
+ExtensionList types which are used to specify accepted content on a
+configurature struct
+*/
+package configurature
+
+import (
+	"fmt"
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+// Type representing a MIME type, e.g. "image/png" or "image/*", with optional
+// parameters. It is normalized to lower case.
+type MIMEType string
+
+func (m *MIMEType) String() string {
+	return (string)(*m)
+}
+
+func (m *MIMEType) Set(v string) error {
+	mt, params, err := mime.ParseMediaType(v)
+	if err != nil {
+		return fmt.Errorf("invalid MIME type \"%s\": %w", v, err)
+	}
+	typ, sub, ok := strings.Cut(mt, "/")
+	if !ok || typ == "" || sub == "" || (typ == "*" && sub != "*") {
+		return fmt.Errorf("invalid MIME type \"%s\": must be type/subtype", v)
+	}
+	*m = (MIMEType)(mime.FormatMediaType(mt, params))
+	return nil
+}
+
+func (m *MIMEType) Type() string {
+	return "mimeType"
+}
+
+// Matches returns whether the MIME type of content, e.g. a Content-Type
+// header, matches m, ignoring parameters. The type or subtype of m may be
+// "*".
+func (m MIMEType) Matches(content string) bool {
+	mt, _, err := mime.ParseMediaType(content)
+	if err != nil {
+		return false
+	}
+	want, _, _ := mime.ParseMediaType(string(m))
+	wantType, wantSub, _ := strings.Cut(want, "/")
+	typ, sub, _ := strings.Cut(mt, "/")
+	return (wantType == "*" || wantType == typ) && (wantSub == "*" || wantSub == sub)
+}
+
+// Type representing a list of comma separated file extensions, e.g.
+// "jpg,.PNG". Extensions are normalized to lower case with a leading dot.
+type ExtensionList []string
+
+func (e *ExtensionList) String() string {
+	return strings.Join(*e, ",")
+}
+
+func (e *ExtensionList) Set(v string) error {
+	list := ExtensionList{}
+	for _, ext := range strings.Split(v, ",") {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if ext == "." || strings.ContainsAny(ext[1:], "./\\ \t") {
+			return fmt.Errorf("invalid file extension \"%s\"", ext)
+		}
+		list = append(list, ext)
+	}
+	*e = list
+	return nil
+}
+
+func (e *ExtensionList) Type() string {
+	return "extensions"
+}
+
+// Contains returns whether the extension of the file name is in the list,
+// ignoring case
+func (e ExtensionList) Contains(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, x := range e {
+		if x == ext {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestMIMETypes(t *testing.T) {
+	type UploadConf struct {
+		Default    co.MIMEType   `default:"Text/Plain; Charset=UTF-8"`
+		Accept     []co.MIMEType `default:"image/*,application/pdf"`
+		Extensions co.ExtensionList
+	}
+	assert := assert.New(t)
+
+	c := co.Configure[UploadConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--extensions", "JPG, .png,gif"},
+	})
+
+	assert.Equal(co.MIMEType("text/plain; charset=UTF-8"), c.Default)
+	assert.True(c.Default.Matches("text/plain"))
+	assert.Equal([]co.MIMEType{"image/*", "application/pdf"}, c.Accept)
+	assert.True(c.Accept[0].Matches("image/png"))
+	assert.False(c.Accept[0].Matches("video/mp4"))
+	assert.True(c.Accept[1].Matches("application/pdf; name=a.pdf"))
+	assert.False(c.Accept[1].Matches("nonsense"))
+
+	assert.Equal(co.ExtensionList{".jpg", ".png", ".gif"}, c.Extensions)
+	assert.True(c.Extensions.Contains("photos/cat.JPG"))
+	assert.False(c.Extensions.Contains("notes.txt"))
+	assert.False(c.Extensions.Contains("jpg"))
+}
+
+func TestMIMETypes_Invalid(t *testing.T) {
+	assert := assert.New(t)
+	m := new(co.MIMEType)
+	e := new(co.ExtensionList)
+
+	assert.EqualError(m.Set("image"), `invalid MIME type "image": must be type/subtype`)
+	assert.EqualError(m.Set("*/png"), `invalid MIME type "*/png": must be type/subtype`)
+	assert.EqualError(m.Set("image png"), `invalid MIME type "image png": mime: expected slash after first token`)
+	assert.EqualError(e.Set("jpg,tar.gz"), `invalid file extension ".tar.gz"`)
+	assert.EqualError(e.Set("."), `invalid file extension "."`)
+}
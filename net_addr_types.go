@@ -0,0 +1,135 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Value interface implementations for network address
+types: *net.TCPAddr, *net.UDPAddr and ListenSpec
+*/
+package configurature
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+)
+
+// tcpAddrValue is the Value used for *net.TCPAddr struct fields
+type tcpAddrValue struct {
+	value string
+	addr  *net.TCPAddr
+}
+
+func (t *tcpAddrValue) String() string {
+	return t.value
+}
+
+func (t *tcpAddrValue) Set(v string) error {
+	addr, err := net.ResolveTCPAddr("tcp", v)
+	if err != nil {
+		return fmt.Errorf("invalid TCP address \"%s\": %w", v, err)
+	}
+	t.value = v
+	t.addr = addr
+	return nil
+}
+
+func (t *tcpAddrValue) Type() string {
+	return "tcpAddr"
+}
+
+func (t *tcpAddrValue) Interface() any {
+	if t.addr == nil {
+		return nil
+	}
+	return *t.addr
+}
+
+// udpAddrValue is the Value used for *net.UDPAddr struct fields
+type udpAddrValue struct {
+	value string
+	addr  *net.UDPAddr
+}
+
+func (u *udpAddrValue) String() string {
+	return u.value
+}
+
+func (u *udpAddrValue) Set(v string) error {
+	addr, err := net.ResolveUDPAddr("udp", v)
+	if err != nil {
+		return fmt.Errorf("invalid UDP address \"%s\": %w", v, err)
+	}
+	u.value = v
+	u.addr = addr
+	return nil
+}
+
+func (u *udpAddrValue) Type() string {
+	return "udpAddr"
+}
+
+func (u *udpAddrValue) Interface() any {
+	if u.addr == nil {
+		return nil
+	}
+	return *u.addr
+}
+
+// Type representing where to listen. Either "tcp://host:port" or
+// "unix:///path/to/socket". Use with net.Listen(l.Network(), l.Address()).
+type ListenSpec string
+
+func (l *ListenSpec) String() string {
+	return (string)(*l)
+}
+
+func (l *ListenSpec) Set(v string) error {
+	u, err := url.Parse(v)
+	if err != nil {
+		return fmt.Errorf("invalid listen spec \"%s\": %w", v, err)
+	}
+	switch u.Scheme {
+	case "tcp":
+		if err := new(HostPort).Set(u.Host); err != nil {
+			return fmt.Errorf("invalid listen spec \"%s\": %w", v, err)
+		}
+	case "unix":
+		if u.Host != "" || u.Path == "" {
+			return fmt.Errorf("invalid listen spec \"%s\": must be unix:///path", v)
+		}
+	default:
+		return fmt.Errorf("invalid listen spec \"%s\": must be tcp://host:port or unix:///path", v)
+	}
+	*l = (ListenSpec)(v)
+	return nil
+}
+
+func (l *ListenSpec) Type() string {
+	return "listenSpec"
+}
+
+// Network returns the network of the listen spec. E.g. "tcp" or "unix"
+func (l ListenSpec) Network() string {
+	u, _ := url.Parse(string(l))
+	return u.Scheme
+}
+
+// Address returns the "host:port" or socket path of the listen spec
+func (l ListenSpec) Address() string {
+	u, _ := url.Parse(string(l))
+	if u.Scheme == "unix" {
+		return u.Path
+	}
+	return u.Host
+}
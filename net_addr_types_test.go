@@ -0,0 +1,70 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestNetAddrs(t *testing.T) {
+	type AddrConf struct {
+		TCP    *net.TCPAddr    `default:"127.0.0.1:8080"`
+		UDP    *net.UDPAddr    `help:"metrics address"`
+		Listen co.ListenSpec   `default:"tcp://:8080"`
+		Extra  []co.ListenSpec `default:"unix:///run/app.sock,tcp://[::1]:9"`
+	}
+	assert := assert.New(t)
+
+	c := co.Configure[AddrConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--udp", "[::1]:8125", "--listen", "unix:///tmp/app.sock"},
+	})
+
+	assert.Equal("127.0.0.1:8080", c.TCP.String())
+	assert.Equal(8080, c.TCP.Port)
+	assert.Equal("[::1]:8125", c.UDP.String())
+	assert.Equal("unix", c.Listen.Network())
+	assert.Equal("/tmp/app.sock", c.Listen.Address())
+	assert.Equal([]co.ListenSpec{"unix:///run/app.sock", "tcp://[::1]:9"}, c.Extra)
+	assert.Equal("tcp", c.Extra[1].Network())
+	assert.Equal("[::1]:9", c.Extra[1].Address())
+}
+
+func TestListenSpec_Invalid(t *testing.T) {
+	assert := assert.New(t)
+	l := new(co.ListenSpec)
+
+	assert.EqualError(l.Set("localhost:80"), `invalid listen spec "localhost:80": must be tcp://host:port or unix:///path`)
+	assert.EqualError(l.Set("tcp://localhost"), `invalid listen spec "tcp://localhost": invalid host:port "localhost": address localhost: missing port in address`)
+	assert.EqualError(l.Set("unix://app.sock"), `invalid listen spec "unix://app.sock": must be unix:///path`)
+}
+
+func TestNetAddrs_Invalid(t *testing.T) {
+	type AddrConf struct {
+		TCP *net.TCPAddr `default:"nope"`
+	}
+
+	assert.PanicsWithValue(t, `Error setting default value for field tcp: invalid TCP address "nope": address nope: missing port in address`, func() {
+		co.Configure[AddrConf](&co.Options{
+			NoRecover: true,
+			Args:      []string{},
+		})
+	})
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the handling of pointers to sub-configs with the NilPtrs
+option
+*/
+package configurature
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/spf13/pflag"
+)
+
+// nilUnsetSubConfigs sets pointers to sub-configs back to nil if none of the
+// fields in them were specified. They are allocated when their fields are
+// visited.
+func (c *configurer) nilUnsetSubConfigs(fs *pflag.FlagSet) {
+	v := reflect.ValueOf(c.config).Elem()
+	ptrs := subConfigPtrs(c.opts.Types, c.opts.TagNames, v.Type(), []int{})
+	if len(ptrs) == 0 {
+		return
+	}
+
+	set := make([]bool, len(ptrs))
+	for _, sf := range structFieldsOf(c.opts.Types, c.opts.TagNames, v.Type()) {
+		tags := sf.field.Tag
+		name := fieldNameToConfigName(sf.field.Name, &tags, sf.ancestors)
+		if !c.specified(name, fs) {
+			continue
+		}
+		for i, p := range ptrs {
+			if len(sf.index) > len(p) && slices.Equal(sf.index[:len(p)], p) {
+				set[i] = true
+			}
+		}
+	}
+
+	// Pointers are in depth first order. Nil them in reverse so that the
+	// parents of nested pointers are still allocated.
+	for i := len(ptrs) - 1; i >= 0; i-- {
+		if !set[i] {
+			fv := v.FieldByIndex(ptrs[i])
+			fv.Set(reflect.Zero(fv.Type()))
+		}
+	}
+}
+
+// subConfigPtrs returns the index sequences of the fields of struct type t
+// that are pointers to sub-configs, parents before their children. This
+// mirrors collectStructFields().
+func subConfigPtrs(types *Types, tagNames map[string]string, t reflect.Type, index []int) [][]int {
+	ptrs := [][]int{}
+	for i := 0; i < t.NumField(); i++ {
+		f := configField(t, t.Field(i), tagNames)
+		if !f.IsExported() {
+			continue
+		}
+		if _, ok := f.Tag.Lookup("ignore"); ok {
+			continue
+		}
+		fieldIndex := slices.Concat(index, []int{i})
+		if f.Anonymous {
+			ptrs = append(ptrs, subConfigPtrs(types, tagNames, f.Type, fieldIndex)...)
+			continue
+		}
+		if isSubConfig(types, f.Type) {
+			if f.Type.Kind() == reflect.Ptr {
+				ptrs = append(ptrs, fieldIndex)
+			}
+			ptrs = append(ptrs, subConfigPtrs(types, tagNames, subConfigType(f.Type), fieldIndex)...)
+		}
+	}
+	return ptrs
+}
+
+// specified returns true if a value was given for the flag by any source
+func (c *configurer) specified(name string, fs *pflag.FlagSet) bool {
+	_, ok := c.sources[name]
+	return ok || fs.Lookup(name).Changed
+}
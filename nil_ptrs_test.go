@@ -0,0 +1,95 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !configurature_core
+
+package configurature_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type NilPtrsTLS struct {
+	Cert string
+	Key  string
+}
+
+type NilPtrsMetrics struct {
+	Port int `default:"9090"`
+	TLS  *NilPtrsTLS
+}
+
+type NilPtrsConf struct {
+	Conf    co.ConfigFile
+	Host    string `default:"localhost"`
+	Metrics *NilPtrsMetrics
+}
+
+func TestNilPtrs_SubConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	// Nothing in the sub-configs is specified
+	c := co.Configure[NilPtrsConf](&co.Options{
+		NoRecover: true,
+		NilPtrs:   true,
+		Args:      []string{},
+	})
+	assert.Nil(c.Metrics)
+	assert.Equal("localhost", c.Host)
+
+	// A field of the outer sub-config is specified
+	c = co.Configure[NilPtrsConf](&co.Options{
+		NoRecover: true,
+		NilPtrs:   true,
+		Args:      []string{"--metrics_port", "8080"},
+	})
+	assert.Equal(8080, c.Metrics.Port)
+	assert.Nil(c.Metrics.TLS)
+
+	// A field of the inner sub-config is specified in the environment
+	c = co.Configure[NilPtrsConf](&co.Options{
+		NoRecover: true,
+		NilPtrs:   true,
+		Args:      []string{},
+		EnvPrefix: "NP_",
+		Environ:   map[string]string{"NP_METRICS_TLS_CERT": "cert.pem"},
+	})
+	assert.Equal(9090, c.Metrics.Port)
+	assert.Equal("cert.pem", c.Metrics.TLS.Cert)
+	assert.Equal("", c.Metrics.TLS.Key)
+
+	// A config file
+	confFile := filepath.Join(t.TempDir(), "conf.yaml")
+	os.WriteFile(confFile, []byte("metrics:\n  tls:\n    key: key.pem\n"), 0600)
+	c = co.Configure[NilPtrsConf](&co.Options{
+		NoRecover: true,
+		NilPtrs:   true,
+		Args:      []string{"--conf", confFile},
+	})
+	assert.Equal("key.pem", c.Metrics.TLS.Key)
+
+	// Without NilPtrs, sub-configs are always allocated
+	c = co.Configure[NilPtrsConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+	})
+	assert.Equal(9090, c.Metrics.Port)
+	assert.NotNil(c.Metrics.TLS)
+}
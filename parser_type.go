@@ -0,0 +1,181 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the RegisterParser[T] and RegisterNamedParser[T] factory
+functions and the Value they register
+*/
+package configurature
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/spf13/pflag"
+)
+
+// RegisterParser registers T as a Configurature type whose values are parsed
+// by parse and printed by format, so that T does not need to implement Value.
+// typeName appears in usage help. If it is empty, the name of T is used. If T
+// is a pointer type, such as *regexp.Regexp, fields of type T and of the type
+// it points to are supported.
+func RegisterParser[T any](parse func(string) (T, error), format func(T) string, typeName string) {
+	registerParser(nil, parse, format, typeName)
+}
+
+// RegisterParserTo is RegisterParser for the types registry rather than the
+// customFlagMap used by every Configure call
+func RegisterParserTo[T any](types *Types, parse func(string) (T, error), format func(T) string, typeName string) {
+	registerParser(types, parse, format, typeName)
+}
+
+// registerParser registers a parser type in types, or in the customFlagMap
+// if types is nil
+func registerParser[T any](types *Types, parse func(string) (T, error), format func(T) string, typeName string) {
+	// Pointer fields are looked up by the type they point to
+	t := reflect.TypeFor[T]()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	registerType(types, t, parserFlagFn(parse, format, typeName), nil)
+}
+
+// parserFlagFn returns a function that adds a flag whose Value uses parse and
+// format to a FlagSet
+func parserFlagFn[T any](parse func(string) (T, error), format func(T) string, typeName string) func(string, string, string, string, *pflag.FlagSet) {
+	return func(name string, short string, def string, help string, fs *pflag.FlagSet) {
+		p := &parserValue[T]{
+			parse:    parse,
+			format:   format,
+			typeName: typeName,
+		}
+		if def != "" {
+			if err := p.Set(def); err != nil {
+				panic(fmt.Sprintf("Error setting default value for field %s: %s", name, err))
+			}
+		}
+		fs.VarP(p, name, short, help)
+	}
+}
+
+// namedParser is a parser registered with RegisterNamedParser
+type namedParser struct {
+	t  reflect.Type                                         // Type of the values parsed
+	fn func(string, string, string, string, *pflag.FlagSet) // Adds a flag using the parser to a FlagSet
+}
+
+// RegisterNamedParser registers a parser that is used for fields of type T
+// tagged with parser:"name" rather than for every field of type T. E.g. an
+// int field that is parsed as hex. name also appears in usage help.
+func RegisterNamedParser[T any](name string, parse func(string) (T, error), format func(T) string) {
+	registerNamedParser(nil, name, parse, format)
+}
+
+// RegisterNamedParserTo is RegisterNamedParser for the types registry rather
+// than the parsers used by every Configure call
+func RegisterNamedParserTo[T any](types *Types, name string, parse func(string) (T, error), format func(T) string) {
+	registerNamedParser(types, name, parse, format)
+}
+
+// registerNamedParser registers a named parser in types, or in namedParsers
+// if types is nil
+func registerNamedParser[T any](types *Types, name string, parse func(string) (T, error), format func(T) string) {
+	p := namedParser{reflect.TypeFor[T](), parserFlagFn(parse, format, name)}
+	if types == nil {
+		typesMu.Lock()
+		defer typesMu.Unlock()
+		namedParsers[name] = p
+		return
+	}
+	types.mu.Lock()
+	defer types.mu.Unlock()
+	types.namedParsers[name] = p
+}
+
+// getNamedParser returns the named parser from types, or else from
+// namedParsers
+func getNamedParser(types *Types, name string) (namedParser, bool) {
+	if types != nil {
+		types.mu.RLock()
+		p, ok := types.namedParsers[name]
+		types.mu.RUnlock()
+		if ok {
+			return p, ok
+		}
+	}
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+	p, ok := namedParsers[name]
+	return p, ok
+}
+
+// addParsedToFlagSet adds a flag for a field of type t tagged with
+// parser:"parserName" to fs
+func addParsedToFlagSet(types *Types, t reflect.Type, parserName string, fs *pflag.FlagSet, name string, short string, def string, help string) {
+	p, ok := getNamedParser(types, parserName)
+	if !ok {
+		panic(fmt.Sprintf("parser tag on flag %s: unknown parser %s", name, parserName))
+	}
+	if t != p.t && !(t.Kind() == reflect.Ptr && t.Elem() == p.t) {
+		panic(fmt.Sprintf("parser tag on flag %s: parser %s is for type %s", name, parserName, p.t))
+	}
+	p.fn(name, short, def, help, fs)
+}
+
+// parserValue is a Configurature type that uses the functions given to
+// RegisterParser and implements the Value interface
+type parserValue[T any] struct {
+	value    T
+	isSet    bool
+	parse    func(string) (T, error)
+	format   func(T) string
+	typeName string
+}
+
+func (p *parserValue[T]) String() string {
+	if !p.isSet {
+		return ""
+	}
+	return p.format(p.value)
+}
+
+func (p *parserValue[T]) Set(v string) error {
+	val, err := p.parse(v)
+	if err != nil {
+		return err
+	}
+	p.value = val
+	p.isSet = true
+	return nil
+}
+
+func (p *parserValue[T]) Type() string {
+	if p.typeName == "" {
+		// Get name from type of T
+		t := reflect.TypeFor[T]()
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if p.typeName = t.Name(); p.typeName == "" {
+			p.typeName = t.String()
+		}
+	}
+	return p.typeName
+}
+
+// Interface returns the parsed value. Pointer fields are left nil when no
+// value was parsed.
+func (p *parserValue[T]) Interface() any {
+	return p.value
+}
@@ -0,0 +1,177 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"net/netip"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func addParserTypes() {
+	co.RegisterParser(regexp.Compile, (*regexp.Regexp).String, "regexp")
+	co.RegisterParser(netip.ParseAddr, netip.Addr.String, "")
+}
+
+type ParserConf struct {
+	Match   *regexp.Regexp `help:"match pattern" default:"^a+$"`
+	Exclude *regexp.Regexp `help:"exclude pattern"`
+	Addr    netip.Addr     `help:"address" default:"::1"`
+}
+
+func TestRegisterParser(t *testing.T) {
+	t.Cleanup(co.ResetForTest)
+	addParserTypes()
+	assert := assert.New(t)
+
+	c := co.Configure[ParserConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--addr", "10.0.0.1"},
+	})
+	assert.True(c.Match.MatchString("aaa"))
+	assert.Nil(c.Exclude)
+	assert.Equal(netip.MustParseAddr("10.0.0.1"), c.Addr)
+
+	c = co.Configure[ParserConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--exclude", "b$"},
+	})
+	assert.Equal("b$", c.Exclude.String())
+	assert.Equal(netip.MustParseAddr("::1"), c.Addr)
+}
+
+func TestRegisterParser_PointerTypeValueField(t *testing.T) {
+	t.Cleanup(co.ResetForTest)
+	addParserTypes()
+	assert := assert.New(t)
+
+	type Conf struct {
+		Match   regexp.Regexp `default:"^a+$"`
+		Exclude regexp.Regexp
+	}
+	c := co.Configure[Conf](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+	})
+	assert.True(c.Match.MatchString("aaa"))
+	assert.Equal("", c.Exclude.String())
+}
+
+func TestRegisterParser_Invalid(t *testing.T) {
+	t.Cleanup(co.ResetForTest)
+	addParserTypes()
+
+	type Conf struct {
+		Addr netip.Addr `default:"nope"`
+	}
+	assert.PanicsWithValue(t, `Error setting default value for field addr: ParseAddr("nope"): unable to parse IP`, func() {
+		co.Configure[Conf](&co.Options{NoRecover: true, Args: []string{}})
+	})
+}
+
+func TestRegisterParser_Usage(t *testing.T) {
+	if os.Getenv("TEST_PASSTHROUGH") == "1" {
+		addParserTypes()
+		co.Configure[ParserConf](&co.Options{
+			Args: []string{"-h"},
+		})
+		panic("Should have exited")
+	}
+
+	stdout, _ := runExternal(t)
+	assert.Contains(t, stdout, `--match regexp`)
+	assert.Contains(t, stdout, `(default ^a+$)`)
+	assert.Contains(t, stdout, `--addr Addr`)
+}
+
+func TestRegisterParserTo(t *testing.T) {
+	types := co.NewTypes()
+	co.RegisterParserTo(types, regexp.Compile, (*regexp.Regexp).String, "regexp")
+
+	type Conf struct {
+		Match *regexp.Regexp
+	}
+	c := co.Configure[Conf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--match", "x"},
+		Types:     types,
+	})
+	assert.Equal(t, "x", c.Match.String())
+}
+
+func TestRegisterNamedParser(t *testing.T) {
+	t.Cleanup(co.ResetForTest)
+	co.RegisterNamedParser("hex", func(v string) (int, error) {
+		i, err := strconv.ParseInt(v, 16, 0)
+		return int(i), err
+	}, func(i int) string {
+		return strconv.FormatInt(int64(i), 16)
+	})
+	co.RegisterNamedParser("semicolons", func(v string) ([]string, error) {
+		return strings.Split(v, ";"), nil
+	}, func(v []string) string {
+		return strings.Join(v, ";")
+	})
+
+	type Conf struct {
+		Mask    int      `parser:"hex" default:"ff"`
+		Count   int      `default:"10"`
+		Opt     *int     `parser:"hex"`
+		Names   []string `default:"a,b"`
+		Queries []string `parser:"semicolons" default:"x=1,y=2;z=3"`
+	}
+	assert := assert.New(t)
+
+	c := co.Configure[Conf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--opt", "1f"},
+	})
+	assert.Equal(255, c.Mask)
+	assert.Equal(10, c.Count)
+	assert.Equal(31, *c.Opt)
+	assert.Equal([]string{"a", "b"}, c.Names)
+	assert.Equal([]string{"x=1,y=2", "z=3"}, c.Queries)
+}
+
+func TestRegisterNamedParser_Invalid(t *testing.T) {
+	t.Cleanup(co.ResetForTest)
+	co.RegisterNamedParser("hex", func(v string) (int, error) {
+		i, err := strconv.ParseInt(v, 16, 0)
+		return int(i), err
+	}, func(i int) string {
+		return strconv.FormatInt(int64(i), 16)
+	})
+
+	type UnknownConf struct {
+		Mask int `parser:"octal"`
+	}
+	assert.PanicsWithValue(t, "parser tag on flag mask: unknown parser octal", func() {
+		co.Configure[UnknownConf](&co.Options{NoRecover: true, Args: []string{}})
+	})
+
+	type TypeConf struct {
+		Mask string `parser:"hex"`
+	}
+	assert.PanicsWithValue(t, "parser tag on flag mask: parser hex is for type int", func() {
+		co.Configure[TypeConf](&co.Options{NoRecover: true, Args: []string{}})
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Value interface implementations for the ExistingFile,
+ExistingDir, and WritableDir path types
+*/
+package configurature
+
+import (
+	"fmt"
+	"os"
+)
+
+// Type representing the path to a file that must exist
+type ExistingFile string
+
+func (f *ExistingFile) String() string {
+	return (string)(*f)
+}
+
+func (f *ExistingFile) Set(v string) error {
+	info, err := os.Stat(v)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", v)
+	}
+	*f = (ExistingFile)(v)
+	return nil
+}
+
+func (f *ExistingFile) Type() string {
+	return "existingFile"
+}
+
+// Type representing the path to a directory that must exist
+type ExistingDir string
+
+func (d *ExistingDir) String() string {
+	return (string)(*d)
+}
+
+func (d *ExistingDir) Set(v string) error {
+	info, err := os.Stat(v)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", v)
+	}
+	*d = (ExistingDir)(v)
+	return nil
+}
+
+func (d *ExistingDir) Type() string {
+	return "existingDir"
+}
+
+// Type representing the path to a directory that must exist and be writable
+// by the current process
+type WritableDir string
+
+func (d *WritableDir) String() string {
+	return (string)(*d)
+}
+
+func (d *WritableDir) Set(v string) error {
+	if err := new(ExistingDir).Set(v); err != nil {
+		return err
+	}
+	// Creating a file is the only portable way to check that the directory
+	// is writable
+	tmp, err := os.CreateTemp(v, ".configurature-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", v, err)
+	}
+	tmp.Close()
+	os.Remove(tmp.Name())
+
+	*d = (WritableDir)(v)
+	return nil
+}
+
+func (d *WritableDir) Type() string {
+	return "writableDir"
+}
@@ -0,0 +1,67 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"os"
+	fp "path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type PathConf struct {
+	File    co.ExistingFile   `help:"a file"`
+	Files   []co.ExistingFile `help:"files"`
+	Dir     co.ExistingDir    `help:"a dir"`
+	WorkDir co.WritableDir    `help:"a writable dir"`
+}
+
+func TestPathTypes(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	file := fp.Join(dir, "file.txt")
+	os.WriteFile(file, []byte("x"), 0600)
+
+	c := co.Configure[PathConf](&co.Options{
+		NoRecover: true,
+		Args: []string{"--file", file, "--files", file + "," + file,
+			"--dir", dir, "--work_dir", dir},
+	})
+
+	assert.Equal(co.ExistingFile(file), c.File)
+	assert.Equal([]co.ExistingFile{co.ExistingFile(file), co.ExistingFile(file)}, c.Files)
+	assert.Equal(co.ExistingDir(dir), c.Dir)
+	assert.Equal(co.WritableDir(dir), c.WorkDir)
+
+	// WritableDir check does not leave files behind
+	entries, _ := os.ReadDir(dir)
+	assert.Len(entries, 1)
+}
+
+func TestPathTypes_Invalid(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	file := fp.Join(dir, "file.txt")
+	os.WriteFile(file, []byte("x"), 0600)
+	missing := fp.Join(dir, "missing")
+
+	assert.EqualError(new(co.ExistingFile).Set(dir), dir+" is a directory")
+	assert.EqualError(new(co.ExistingFile).Set(missing), "stat "+missing+": no such file or directory")
+	assert.EqualError(new(co.ExistingDir).Set(file), file+" is not a directory")
+	assert.EqualError(new(co.WritableDir).Set(missing), "stat "+missing+": no such file or directory")
+}
@@ -0,0 +1,74 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Value interface implementation for the Port type which
+is used to specify a network port number on a configurature struct
+*/
+package configurature
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Type representing a network port number (1-65535)
+type Port uint16
+
+func (p *Port) String() string {
+	return strconv.FormatUint(uint64(*p), 10)
+}
+
+func (p *Port) Set(v string) error {
+	n, err := parsePort(v, 1)
+	if err != nil {
+		return err
+	}
+	*p = (Port)(n)
+	return nil
+}
+
+func (p *Port) Type() string {
+	return "port"
+}
+
+// Type representing a port number to listen on (0-65535). 0 requests a
+// random port from the OS.
+type ListenPort uint16
+
+func (p *ListenPort) String() string {
+	return strconv.FormatUint(uint64(*p), 10)
+}
+
+func (p *ListenPort) Set(v string) error {
+	n, err := parsePort(v, 0)
+	if err != nil {
+		return err
+	}
+	*p = (ListenPort)(n)
+	return nil
+}
+
+func (p *ListenPort) Type() string {
+	return "port"
+}
+
+// parsePort parses the port number v, which must be at least min
+func parsePort(v string, min uint64) (uint16, error) {
+	n, err := strconv.ParseUint(v, 10, 16)
+	if err != nil || n < min {
+		return 0, fmt.Errorf("invalid port \"%s\": must be a number between %d and 65535", v, min)
+	}
+	return uint16(n), nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type PortConf struct {
+	Port   co.Port       `help:"listen port" default:"8080"`
+	Random co.ListenPort `help:"random port"`
+	Ports  []co.Port     `help:"ports" default:"80,443"`
+}
+
+func TestPort(t *testing.T) {
+	assert := assert.New(t)
+
+	c := co.Configure[PortConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--port", "65535", "--ports", "1,2,3"},
+	})
+
+	assert.Equal(co.Port(65535), c.Port)
+	assert.Equal(co.ListenPort(0), c.Random)
+	assert.Equal([]co.Port{1, 2, 3}, c.Ports)
+}
+
+func TestPort_Defaults(t *testing.T) {
+	assert := assert.New(t)
+
+	c := co.Configure[PortConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+	})
+
+	assert.Equal(co.Port(8080), c.Port)
+	assert.Equal([]co.Port{80, 443}, c.Ports)
+}
+
+func TestPort_Invalid(t *testing.T) {
+	for _, v := range []string{"65536", "-1", "http", "0"} {
+		p := new(co.Port)
+		assert.EqualError(t, p.Set(v), `invalid port "`+v+`": must be a number between 1 and 65535`)
+	}
+}
+
+func TestListenPort(t *testing.T) {
+	assert := assert.New(t)
+
+	c := co.Configure[PortConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--random", "0"},
+	})
+	assert.Equal(co.ListenPort(0), c.Random)
+
+	p := new(co.ListenPort)
+	assert.NoError(p.Set("65535"))
+	assert.Equal("65535", p.String())
+	assert.EqualError(p.Set("65536"), `invalid port "65536": must be a number between 0 and 65535`)
+
+	code, _, errOut := configureExit(t, func(opts *co.Options) { co.Configure[PortConf](opts) }, co.Options{
+		Args: []string{"--port", "0"},
+	})
+	assert.Equal(2, code)
+	assert.Contains(errOut, `invalid argument "0" for "--port" flag: invalid port "0": must be a number between 1 and 65535`)
+}
+
+func TestPort_Usage(t *testing.T) {
+	if os.Getenv("TEST_PASSTHROUGH") == "1" {
+		co.Configure[PortConf](&co.Options{
+			Args: []string{"-h"},
+		})
+		panic("Should have exited")
+	}
+
+	stdout, stderr := runExternal(t)
+	assert.Equal(t, "", stderr)
+	assert.True(t, strings.Contains(stdout, `--ports []port   ports (default 80,443)`), stdout)
+}
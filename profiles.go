@@ -0,0 +1,109 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains config file profile helpers
+*/
+package configurature
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	// Config file key holding profiles
+	profilesKey = "profiles"
+
+	// Name of the flag selecting a profile
+	profileFlag = "profile"
+)
+
+// profile returns the name of the config file profile to use. When the
+// ProfileFlag option is set, the --profile flag takes precedence over the
+// environment, which takes precedence over Options.Profile.
+func (c *configurer) profile(fs *pflag.FlagSet) string {
+	if !c.opts.ProfileFlag {
+		return c.opts.Profile
+	}
+	if fl := fs.Lookup(profileFlag); fl.Changed {
+		return fl.Value.String()
+	}
+	if envVal := c.opts.getenv(
+		fmt.Sprintf("%s%s", c.opts.EnvPrefix, toScreamingSnake(profileFlag)),
+	); envVal != "" {
+		return envVal
+	}
+	return c.opts.Profile
+}
+
+// applyProfile removes the profiles section from the raw contents of a config
+// file and merges the selected profile, if any, over the rest of it. A file
+// without a profiles section is returned as is.
+func (c *configurer) applyProfile(fileName string, fs *pflag.FlagSet, raw map[string]any) map[string]any {
+	profiles, ok := raw[profilesKey]
+	delete(raw, profilesKey)
+
+	name := c.profile(fs)
+	if name == "" || !ok {
+		return raw
+	}
+
+	profilesMap, ok := stringMap(profiles)
+	if !ok {
+		panic(fmt.Sprintf("profiles in config file %s is not an object", fileName))
+	}
+	profile, ok := profilesMap[name]
+	if !ok {
+		panic(fmt.Sprintf("profile %s not found in config file %s", name, fileName))
+	}
+	profileMap, ok := stringMap(profile)
+	if !ok {
+		panic(fmt.Sprintf("profile %s in config file %s is not an object", name, fileName))
+	}
+
+	mergeMaps(raw, profileMap)
+	return raw
+}
+
+// mergeMaps recursively merges src into dst. Values in src replace those in
+// dst unless both are objects.
+func mergeMaps(dst map[string]any, src map[string]any) {
+	for k, v := range src {
+		srcMap, srcIsMap := stringMap(v)
+		dstMap, dstIsMap := stringMap(dst[k])
+		if srcIsMap && dstIsMap {
+			mergeMaps(dstMap, srcMap)
+			dst[k] = dstMap
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// stringMap returns v as a map[string]any if it is an object
+func stringMap(v any) (map[string]any, bool) {
+	switch m := v.(type) {
+	case map[string]any:
+		return m, true
+	case map[any]any:
+		sm := make(map[string]any, len(m))
+		for k, vv := range m {
+			sm[fmt.Sprintf("%v", k)] = vv
+		}
+		return sm, true
+	}
+	return nil, false
+}
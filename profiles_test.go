@@ -0,0 +1,128 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !configurature_core
+
+package configurature_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type ProfileDB struct {
+	Host string `default:"localhost"`
+	Port int    `default:"5432"`
+}
+
+type ProfileConfig struct {
+	Conf  co.ConfigFile
+	Level string `default:"info"`
+	Names []string
+	DB    ProfileDB
+}
+
+const profileConfigFile = `level: warn
+names: [a, b]
+db:
+  host: db.local
+profiles:
+  dev:
+    level: debug
+    db:
+      host: localhost
+  prod:
+    names: [c]
+    db:
+      port: 6432
+`
+
+func writeProfileConfig(t *testing.T) string {
+	fileName := t.TempDir() + "/conf.yaml"
+	os.WriteFile(fileName, []byte(profileConfigFile), 0644)
+	return fileName
+}
+
+func TestProfile(t *testing.T) {
+	assert := assert.New(t)
+	fileName := writeProfileConfig(t)
+
+	// No profile selected
+	c := co.Configure[ProfileConfig](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--conf", fileName},
+	})
+	assert.Equal("warn", c.Level)
+	assert.Equal([]string{"a", "b"}, c.Names)
+	assert.Equal("db.local", c.DB.Host)
+	assert.Equal(5432, c.DB.Port)
+
+	c = co.Configure[ProfileConfig](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--conf", fileName},
+		Profile:   "dev",
+	})
+	assert.Equal("debug", c.Level)
+	assert.Equal([]string{"a", "b"}, c.Names)
+	assert.Equal("localhost", c.DB.Host)
+
+	c = co.Configure[ProfileConfig](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--conf", fileName},
+		Profile:   "prod",
+	})
+	assert.Equal("warn", c.Level)
+	assert.Equal([]string{"c"}, c.Names)
+	assert.Equal("db.local", c.DB.Host)
+	assert.Equal(6432, c.DB.Port)
+}
+
+func TestProfile_Flag(t *testing.T) {
+	assert := assert.New(t)
+	fileName := writeProfileConfig(t)
+
+	c := co.Configure[ProfileConfig](&co.Options{
+		NoRecover:   true,
+		Args:        []string{"--conf", fileName, "--profile", "prod"},
+		Profile:     "dev",
+		ProfileFlag: true,
+	})
+	assert.Equal(6432, c.DB.Port)
+	assert.Equal("warn", c.Level)
+
+	t.Setenv("PROF_PROFILE", "dev")
+	c = co.Configure[ProfileConfig](&co.Options{
+		NoRecover:   true,
+		Args:        []string{"--conf", fileName},
+		EnvPrefix:   "PROF_",
+		ProfileFlag: true,
+	})
+	assert.Equal("debug", c.Level)
+}
+
+func TestProfile_NotFound(t *testing.T) {
+	fileName := writeProfileConfig(t)
+
+	assert.PanicsWithValue(t, "profile qa not found in config file "+fileName, func() {
+		co.Configure[ProfileConfig](&co.Options{
+			NoRecover: true,
+			Args:      []string{"--conf", fileName},
+			Profile:   "qa",
+		})
+	})
+}
@@ -0,0 +1,172 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains interactive prompting for required values that weren't
+provided
+*/
+package configurature
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// ErrNoTerminal is returned by a Prompter when it can't prompt, e.g. because
+// stdin is not a terminal. Required values are then reported as missing.
+var ErrNoTerminal = errors.New("not a terminal")
+
+// Prompter reads values of required fields that weren't provided
+type Prompter interface {
+	// Prompt asks for the value described by label. Input of secret values
+	// should be hidden.
+	Prompt(label string, secret bool) (string, error)
+}
+
+// Number of times a field is prompted for before its invalid value is an
+// error
+const promptAttempts = 3
+
+// Configuration value source of prompted values
+const sourcePrompt = "prompt"
+
+// TerminalPrompter returns a Prompter that prompts on stderr and reads from
+// stdin if it is a terminal. Secret input is hidden using the stty command.
+// Configure's default Prompter prompts on Options.ErrOutput instead.
+func TerminalPrompter() Prompter {
+	return terminalPrompter{out: os.Stderr}
+}
+
+// terminalPrompter prompts on the terminal
+type terminalPrompter struct {
+	out io.Writer // Where prompts are printed
+}
+
+// Prompt prints label and reads a line from stdin
+func (p terminalPrompter) Prompt(label string, secret bool) (string, error) {
+	if !isTerminal(os.Stdin) {
+		return "", ErrNoTerminal
+	}
+	fmt.Fprintf(p.out, "%s: ", label)
+	if !secret {
+		return readLine(os.Stdin)
+	}
+	defer fmt.Fprintln(p.out)
+	return readHidden(os.Stdin)
+}
+
+// readLine reads a line from r without reading past it
+func readLine(r io.Reader) (string, error) {
+	line := []byte{}
+	b := make([]byte, 1)
+	for {
+		n, err := r.Read(b)
+		if n > 0 {
+			if b[0] == '\n' {
+				break
+			}
+			line = append(line, b[0])
+		}
+		if err == io.EOF && len(line) > 0 {
+			break
+		} else if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSuffix(string(line), "\r"), nil
+}
+
+// prompter returns the Prompter to use
+func (c *configurer) prompter() Prompter {
+	if c.opts.Prompter == nil {
+		return terminalPrompter{out: c.opts.errOutput()}
+	}
+	return c.opts.Prompter
+}
+
+// promptRequired prompts for the values of required fields that weren't
+// provided by any source. Nothing is prompted for when help, a template or
+// --init was requested.
+func (c *configurer) promptRequired(s any, fs *pflag.FlagSet) {
+	for name := range internalFlags {
+		if fl := fs.Lookup(name); fl != nil && fl.Changed {
+			return
+		}
+	}
+	prompter := c.prompter()
+
+	c.visitFields(s, func(f reflect.StructField, tags *reflect.StructTag, v reflect.Value, ancestors []string) (stop bool) {
+		fName := fieldNameToConfigName(f.Name, tags, ancestors)
+		if _, ok := c.sources[fName]; ok || !c.isRequired(tags) {
+			return stop
+		}
+		fl := fs.Lookup(fName)
+		label := fName
+		if fl.Usage != "" && fl.Usage != fName {
+			label = fmt.Sprintf("%s (%s)", fName, fl.Usage)
+		}
+
+		ok, err := c.promptFlag(prompter, fl, label, nil)
+		if errors.Is(err, ErrNoTerminal) {
+			// Leave the remaining fields to be reported by validate()
+			return true
+		} else if err != nil {
+			panic(err.Error())
+		}
+		if ok {
+			c.sources[fName] = sourcePrompt
+		}
+		return stop
+	}, []string{})
+}
+
+// promptFlag prompts for the value of fl until a valid value is entered,
+// promptAttempts times at most. check, if not nil, validates the value before
+// it is set. It returns false if nothing was entered.
+func (c *configurer) promptFlag(prompter Prompter, fl *pflag.Flag, label string, check func(string) error) (bool, error) {
+	for attempt := 1; ; attempt++ {
+		val, err := prompter.Prompt(label, isSecret(fl))
+		if errors.Is(err, ErrNoTerminal) {
+			return false, err
+		} else if err != nil {
+			return false, fmt.Errorf("error reading value for %s: %v", fl.Name, err)
+		}
+		if val == "" {
+			return false, nil
+		}
+		if check != nil {
+			err = check(val)
+		}
+		if err == nil {
+			err = fl.Value.Set(val)
+		}
+		if err != nil {
+			msg := maskSecret(fl, fmt.Sprintf("invalid value %q for %s: %v", val, fl.Name, err), val)
+			if attempt == promptAttempts {
+				return false, errors.New(msg)
+			}
+			fmt.Fprintln(c.opts.errOutput(), msg)
+			continue
+		}
+		// Satisfies validate()
+		fl.Changed = true
+		return true, nil
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+// testPrompter returns answers in order and records what was prompted for
+type testPrompter struct {
+	answers []string
+	labels  []string
+	secret  []bool
+	err     error
+}
+
+func (p *testPrompter) Prompt(label string, secret bool) (string, error) {
+	if p.err != nil {
+		return "", p.err
+	}
+	p.labels = append(p.labels, label)
+	p.secret = append(p.secret, secret)
+	if len(p.answers) == 0 {
+		return "", nil
+	}
+	answer := p.answers[0]
+	p.answers = p.answers[1:]
+	return answer, nil
+}
+
+type PromptConf struct {
+	Host     string  `required:"" help:"server host"`
+	Port     co.Port `required:""`
+	Password string  `required:"" secret:""`
+	Debug    bool
+}
+
+func TestPrompt(t *testing.T) {
+	assert := assert.New(t)
+
+	p := &testPrompter{answers: []string{"example.com", "8080", "hunter2"}}
+	c := co.Configure[PromptConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+		Prompt:    true,
+		Prompter:  p,
+	})
+	assert.Equal("example.com", c.Host)
+	assert.Equal(co.Port(8080), c.Port)
+	assert.Equal("hunter2", c.Password)
+	assert.Equal([]string{"host (server host)", "port", "password"}, p.labels)
+	assert.Equal([]bool{false, false, true}, p.secret)
+}
+
+func TestPrompt_Provided(t *testing.T) {
+	assert := assert.New(t)
+
+	p := &testPrompter{answers: []string{"hunter2"}}
+	c := co.Configure[PromptConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--host", "example.com", "--port", "80"},
+		Prompt:    true,
+		Prompter:  p,
+	})
+	assert.Equal("example.com", c.Host)
+	assert.Equal(co.Port(80), c.Port)
+	assert.Equal("hunter2", c.Password)
+	assert.Equal([]string{"password"}, p.labels)
+}
+
+func TestPrompt_InvalidValue(t *testing.T) {
+	assert := assert.New(t)
+
+	errOut := &strings.Builder{}
+	p := &testPrompter{answers: []string{"h", "nope", "443", "pw"}}
+	c := co.Configure[PromptConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+		Prompt:    true,
+		Prompter:  p,
+		ErrOutput: errOut,
+	})
+	assert.Equal(co.Port(443), c.Port)
+	assert.Equal([]string{"host (server host)", "port", "port", "password"}, p.labels)
+	assert.Contains(errOut.String(), `invalid port "nope"`)
+
+	p = &testPrompter{answers: []string{"h", "a", "b", "c"}}
+	assert.PanicsWithValue(`invalid value "c" for port: invalid port "c": must be a number between 1 and 65535`, func() {
+		co.Configure[PromptConf](&co.Options{
+			NoRecover: true,
+			Args:      []string{},
+			Prompt:    true,
+			Prompter:  p,
+			ErrOutput: errOut,
+		})
+	})
+}
+
+func TestPrompt_NoTerminal(t *testing.T) {
+	// Test stdin is not a terminal, so TerminalPrompter() can't prompt
+	for _, p := range []co.Prompter{&testPrompter{err: co.ErrNoTerminal}, nil} {
+		assert.PanicsWithValue(t, "host is required, port is required, password is required", func() {
+			co.Configure[PromptConf](&co.Options{
+				NoRecover: true,
+				Args:      []string{},
+				Prompt:    true,
+				Prompter:  p,
+			})
+		})
+	}
+}
+
+func TestPrompt_Help(t *testing.T) {
+	p := &testPrompter{}
+	assert.Panics(t, func() {
+		co.Configure[PromptConf](&co.Options{
+			Args:     []string{"--help"},
+			Prompt:   true,
+			Prompter: p,
+			Output:   &strings.Builder{},
+			Exit:     func(int) {},
+		})
+	})
+	assert.Empty(t, p.labels)
+}
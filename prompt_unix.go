@@ -0,0 +1,49 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unix
+
+/*
+This file contains terminal detection and hidden input for Unix platforms
+*/
+package configurature
+
+import (
+	"os"
+	"os/exec"
+)
+
+// isTerminal returns true if f is a terminal. Unlike checking for a character
+// device, this excludes /dev/null.
+func isTerminal(f *os.File) bool {
+	cmd := exec.Command("stty", "-g")
+	cmd.Stdin = f
+	return cmd.Run() == nil
+}
+
+// readHidden reads a line from the terminal f without echoing it
+func readHidden(f *os.File) (string, error) {
+	if err := stty(f, "-echo"); err != nil {
+		return "", err
+	}
+	defer stty(f, "echo")
+	return readLine(f)
+}
+
+// stty runs the stty command with arg on the terminal f
+func stty(f *os.File, arg string) error {
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = f
+	return cmd.Run()
+}
@@ -0,0 +1,155 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Value interface implementations for the Rate and
+Bandwidth types which are used to specify throttling options on a
+configurature struct
+*/
+package configurature
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Type representing a number of events per period of time. E.g. "100/s",
+// "5/m", "1000/h" or "3/10s".
+type Rate struct {
+	Count float64
+	Per   time.Duration
+}
+
+// Periods of rates given as a unit rather than a duration
+var ratePeriods = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+}
+
+func (r *Rate) String() string {
+	if r.Per == 0 {
+		return ""
+	}
+	per := r.Per.String()
+	for unit, d := range ratePeriods {
+		if r.Per == d {
+			per = unit
+		}
+	}
+	return strconv.FormatFloat(r.Count, 'f', -1, 64) + "/" + per
+}
+
+func (r *Rate) Set(v string) error {
+	count, per, ok := strings.Cut(v, "/")
+	if !ok {
+		return fmt.Errorf("invalid rate \"%s\": must be <count>/<s|m|h|duration>", v)
+	}
+	c, err := strconv.ParseFloat(count, 64)
+	if err != nil || c < 0 {
+		return fmt.Errorf("invalid rate \"%s\": count must be a non-negative number", v)
+	}
+	d, ok := ratePeriods[per]
+	if !ok {
+		if d, err = time.ParseDuration(per); err != nil || d <= 0 {
+			return fmt.Errorf("invalid rate \"%s\": must be <count>/<s|m|h|duration>", v)
+		}
+	}
+	*r = Rate{Count: c, Per: d}
+	return nil
+}
+
+func (r *Rate) Type() string {
+	return "rate"
+}
+
+// PerSecond returns the number of events per second
+func (r Rate) PerSecond() float64 {
+	if r.Per == 0 {
+		return 0
+	}
+	return r.Count / r.Per.Seconds()
+}
+
+// Interval returns the time between events, or 0 if the count is 0
+func (r Rate) Interval() time.Duration {
+	if r.Count == 0 {
+		return 0
+	}
+	return time.Duration(float64(r.Per) / r.Count)
+}
+
+// Type representing a bandwidth in bits per second given in bits, e.g.
+// "10Mbps", or bytes, e.g. "5MBps", per second with an SI prefix of k, M, G
+// or T.
+type Bandwidth int64
+
+// Bandwidth units and their multiples of bits per second
+var bandwidthUnits = []struct {
+	unit string
+	bits float64
+}{
+	{"Tbps", 1e12}, {"Gbps", 1e9}, {"Mbps", 1e6}, {"kbps", 1e3}, {"bps", 1},
+	{"TBps", 8e12}, {"GBps", 8e9}, {"MBps", 8e6}, {"kBps", 8e3}, {"Bps", 8},
+}
+
+func (b *Bandwidth) String() string {
+	if *b == 0 {
+		return "0bps"
+	}
+	// The largest bits per second unit that represents b exactly
+	for _, u := range bandwidthUnits[:5] {
+		if int64(*b)%int64(u.bits) == 0 {
+			return strconv.FormatInt(int64(*b)/int64(u.bits), 10) + u.unit
+		}
+	}
+	return strconv.FormatInt(int64(*b), 10) + "bps"
+}
+
+func (b *Bandwidth) Set(v string) error {
+	for _, u := range bandwidthUnits {
+		num, ok := strings.CutSuffix(v, u.unit)
+		if !ok && u.unit[0] == 'k' {
+			// Accept an upper case K too
+			num, ok = strings.CutSuffix(v, "K"+u.unit[1:])
+		}
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+		if err != nil || n < 0 {
+			break
+		}
+		*b = Bandwidth(math.Round(n * u.bits))
+		return nil
+	}
+	return fmt.Errorf("invalid bandwidth \"%s\": must be a number followed by bps, kbps, Mbps, Gbps, Tbps, Bps, kBps, MBps, GBps or TBps", v)
+}
+
+func (b *Bandwidth) Type() string {
+	return "bandwidth"
+}
+
+// BitsPerSecond returns the bandwidth in bits per second
+func (b Bandwidth) BitsPerSecond() int64 {
+	return int64(b)
+}
+
+// BytesPerSecond returns the bandwidth in bytes per second
+func (b Bandwidth) BytesPerSecond() int64 {
+	return int64(b) / 8
+}
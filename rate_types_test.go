@@ -0,0 +1,82 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestRate(t *testing.T) {
+	type RateConf struct {
+		Requests co.Rate `default:"100/s"`
+		Logins   co.Rate `default:"5/m"`
+		Bursts   co.Rate
+	}
+	assert := assert.New(t)
+
+	c := co.Configure[RateConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--bursts", "3/10s"},
+	})
+
+	assert.Equal(co.Rate{Count: 100, Per: time.Second}, c.Requests)
+	assert.Equal(100.0, c.Requests.PerSecond())
+	assert.Equal(10*time.Millisecond, c.Requests.Interval())
+	assert.Equal(12*time.Second, c.Logins.Interval())
+	assert.Equal("5/m", c.Logins.String())
+	assert.Equal(0.3, c.Bursts.PerSecond())
+	assert.Equal("3/10s", c.Bursts.String())
+}
+
+func TestRate_Invalid(t *testing.T) {
+	assert := assert.New(t)
+	r := new(co.Rate)
+
+	assert.EqualError(r.Set("100"), `invalid rate "100": must be <count>/<s|m|h|duration>`)
+	assert.EqualError(r.Set("x/s"), `invalid rate "x/s": count must be a non-negative number`)
+	assert.EqualError(r.Set("5/d"), `invalid rate "5/d": must be <count>/<s|m|h|duration>`)
+}
+
+func TestBandwidth(t *testing.T) {
+	type BWConf struct {
+		Upload   co.Bandwidth `default:"10Mbps"`
+		Download co.Bandwidth
+		Disk     co.Bandwidth
+	}
+	assert := assert.New(t)
+
+	c := co.Configure[BWConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--download", "1.5Gbps", "--disk", "200MBps"},
+	})
+
+	assert.Equal(int64(10_000_000), c.Upload.BitsPerSecond())
+	assert.Equal(int64(1_250_000), c.Upload.BytesPerSecond())
+	assert.Equal(co.Bandwidth(1_500_000_000), c.Download)
+	assert.Equal("1500Mbps", c.Download.String())
+	assert.Equal(int64(200_000_000), c.Disk.BytesPerSecond())
+	assert.Equal("1600Mbps", c.Disk.String())
+
+	b := new(co.Bandwidth)
+	assert.Nil(b.Set("64Kbps"))
+	assert.Equal("64kbps", b.String())
+	assert.EqualError(b.Set("10MB"), `invalid bandwidth "10MB": must be a number followed by bps, kbps, Mbps, Gbps, Tbps, Bps, kBps, MBps, GBps or TBps`)
+	assert.Error(b.Set("fastbps"))
+}
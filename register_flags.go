@@ -0,0 +1,84 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains RegisterFlags, which adds a config struct's flags to a
+FlagSet owned by the application so that it can parse the command line itself
+*/
+package configurature
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// RegisterFlags adds the flags of config struct T to fs, which the caller
+// parses, e.g. a test binary or server framework that owns the command line.
+// After fs has been parsed, populate loads values from the other sources,
+// validates them and returns the config. Errors are returned rather than
+// exiting the program. Args, the internal flags, such as --help_json,
+// prompting and WatchSources are not supported.
+func RegisterFlags[T any](fs *pflag.FlagSet, opts *Options) (populate func() (*T, error)) {
+	opts = optionsWithDefaults(opts)
+	snapshotRegistries()
+	c := &configurer{
+		ctx:    context.Background(),
+		config: new(T),
+		opts:   opts,
+	}
+
+	f := pflag.NewFlagSet("config", pflag.ContinueOnError)
+	setters := c.loadFlags(c.config, f)
+	if !opts.ShowCredentials {
+		redactCredentials(f)
+	}
+	f.VisitAll(func(fl *pflag.Flag) {
+		if fs.Lookup(fl.Name) != nil {
+			panic(fmt.Sprintf("flag --%s is already defined", fl.Name))
+		}
+		if fl.Shorthand != "" && fs.ShorthandLookup(fl.Shorthand) != nil {
+			panic(fmt.Sprintf("short flag -%s of --%s is already defined", fl.Shorthand, fl.Name))
+		}
+	})
+	fs.AddFlagSet(f)
+
+	return func() (config *T, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%v", r)
+			}
+		}()
+		start := time.Now()
+
+		// The flags are shared with fs, so values given on its command line
+		// take precedence
+		c.applySources(f)
+		for _, fn := range setters {
+			fn()
+		}
+		if opts.FileDecoder != nil {
+			c.decodeConfigFiles()
+		}
+		c.validate(c.config, f)
+		storeConfig[T](c, f, start)
+
+		if opts.SecretRefresh > 0 {
+			refreshSecrets[T](c)
+		}
+		return c.config.(*T), nil
+	}
+}
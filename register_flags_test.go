@@ -0,0 +1,72 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type registeredConf struct {
+	Host string `default:"localhost" short:"H"`
+	Port int    `default:"8080"`
+	Name string `required:""`
+}
+
+func TestRegisterFlags(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("REG_PORT", "9090")
+
+	fs := pflag.NewFlagSet("app", pflag.ContinueOnError)
+	verbose := fs.BoolP("verbose", "v", false, "verbose output")
+	populate := co.RegisterFlags[registeredConf](fs, &co.Options{EnvPrefix: "REG_"})
+
+	assert.NoError(fs.Parse([]string{"-v", "-H", "db.local", "--name", "svc"}))
+	assert.True(*verbose)
+
+	c, err := populate()
+	assert.NoError(err)
+	assert.Equal(&registeredConf{Host: "db.local", Port: 9090, Name: "svc"}, c)
+
+	got, err := co.Get[registeredConf]()
+	assert.NoError(err)
+	assert.Equal(c, got)
+}
+
+func TestRegisterFlags_Errors(t *testing.T) {
+	assert := assert.New(t)
+
+	fs := pflag.NewFlagSet("app", pflag.ContinueOnError)
+	populate := co.RegisterFlags[registeredConf](fs, nil)
+	assert.NoError(fs.Parse([]string{}))
+	_, err := populate()
+	assert.EqualError(err, "name is required")
+
+	fs = pflag.NewFlagSet("app", pflag.ContinueOnError)
+	fs.String("port", "", "")
+	assert.PanicsWithValue("flag --port is already defined", func() {
+		co.RegisterFlags[registeredConf](fs, nil)
+	})
+
+	fs = pflag.NewFlagSet("app", pflag.ContinueOnError)
+	fs.BoolP("human", "H", false, "")
+	assert.PanicsWithValue("short flag -H of --host is already defined", func() {
+		co.RegisterFlags[registeredConf](fs, nil)
+	})
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the ResetForTest function and its helpers
+*/
+package configurature
+
+import (
+	"maps"
+	"reflect"
+	"sync"
+
+	"github.com/spf13/pflag"
+)
+
+var (
+	// Copies of the registries taken by snapshotRegistries(). Used by
+	// ResetForTest()
+	snapshotOnce            sync.Once
+	initialCustomFlagMap    map[reflect.Type]func(string, string, string, string, *pflag.FlagSet)
+	initialMapValueTypeKeys map[string][]string
+	initialNamedParsers     map[string]namedParser
+	initialDescriptions     map[reflect.Type]map[string]string
+	initialFieldMetas       map[reflect.Type]map[string]FieldMeta
+)
+
+// snapshotRegistries saves copies of the type, parser, description and field
+// metadata registries the first time it is called, so that ResetForTest() can
+// restore them. It is called by ResetForTest() and before configuring, so
+// registrations made before then, e.g. in init() functions, are kept.
+func snapshotRegistries() {
+	snapshotOnce.Do(func() {
+		typesMu.RLock()
+		initialCustomFlagMap = maps.Clone(customFlagMap)
+		initialMapValueTypeKeys = maps.Clone(mapValueTypeKeys)
+		initialNamedParsers = maps.Clone(namedParsers)
+		typesMu.RUnlock()
+
+		descriptionsMu.Lock()
+		initialDescriptions = cloneNested(descriptions)
+		descriptionsMu.Unlock()
+
+		fieldMetasMu.Lock()
+		initialFieldMetas = cloneNested(fieldMetas)
+		fieldMetasMu.Unlock()
+	})
+}
+
+// cloneNested returns a copy of m and of the maps it holds
+func cloneNested[V any](m map[reflect.Type]map[string]V) map[reflect.Type]map[string]V {
+	c := make(map[reflect.Type]map[string]V, len(m))
+	for t, v := range m {
+		c[t] = maps.Clone(v)
+	}
+	return c
+}
+
+// ResetForTest clears all global state held by this package: the last loaded
+// configuration, named configurations, the Get[T]() type cache, config file
+// migrations, cached Source values, Subscribe[T]() subscriptions, cached
+// struct fields, source watchers and secret refreshers, and any custom types, parsers, descriptions and field
+// metadata registered after the first call to ResetForTest() or to a
+// function that loads configuration, such as Configure(). Registrations made
+// before then, e.g. in init() functions, are kept.
+//
+// To scope AddType registrations to a single test, register a cleanup
+// before adding types:
+//
+//	t.Cleanup(configurature.ResetForTest)
+//	configurature.AddType[MyType]()
+func ResetForTest() {
+	snapshotRegistries()
+	stopBackground()
+
+	configsMu.Lock()
+	lastConfigLoaded = nil
+	lastSnapshot = nil
+	namedConfigs = make(map[string]any)
+	namedSnapshots = make(map[string]*snapshot)
+	getConfigTypeCache = make(map[reflect.Type]any)
+	configsMu.Unlock()
+
+	migrationsMu.Lock()
+	migrations = nil
+	migrationsMu.Unlock()
+
+	sourceCacheMu.Lock()
+	sourceCache = make(map[string]map[string]any)
+	sourceCacheMu.Unlock()
+
+	subscriptionsMu.Lock()
+	subscriptions = make(map[reflect.Type]any)
+	subscriptionsMu.Unlock()
+
+	descriptionsMu.Lock()
+	descriptions = cloneNested(initialDescriptions)
+	descriptionsMu.Unlock()
+
+	fieldMetasMu.Lock()
+	fieldMetas = cloneNested(initialFieldMetas)
+	fieldMetasMu.Unlock()
+
+	clearStructFieldsCache()
+
+	typesMu.Lock()
+	defer typesMu.Unlock()
+	customFlagMap = maps.Clone(initialCustomFlagMap)
+	mapValueTypeKeys = maps.Clone(initialMapValueTypeKeys)
+	namedParsers = maps.Clone(initialNamedParsers)
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestResetForTest(t *testing.T) {
+	assert := assert.New(t)
+
+	co.Configure[TestNestedConfig](&co.Options{Name: "reset"})
+	co.ResetForTest()
+
+	c, err := co.Get[TestNestedConfig]()
+	assert.ErrorIs(err, co.ErrConfigNotLoaded)
+	assert.Nil(c)
+
+	c, err = co.GetNamed[TestNestedConfig]("reset")
+	assert.ErrorIs(err, co.ErrConfigNotLoaded)
+	assert.Nil(c)
+}
+
+func TestResetForTest_CustomTypes(t *testing.T) {
+	type Shade string
+	type SConf struct {
+		Shade Shade `help:"shade" default:"dark"`
+	}
+
+	func() {
+		co.AddMapValueType("",
+			[]string{"dark", "light"},
+			[]Shade{"#000000", "#ffffff"},
+		)
+		defer co.ResetForTest()
+
+		conf := co.Configure[SConf](&co.Options{NoRecover: true, Args: []string{}})
+		assert.Equal(t, Shade("#000000"), conf.Shade)
+	}()
+
+	assert.PanicsWithValue(t, "addToFlagSet() unsupported type: configurature_test.Shade", func() {
+		co.Configure[SConf](&co.Options{NoRecover: true, Args: []string{}})
+	})
+}
+
+// InitShade and ResetDescConf are registered in init() like a package
+// using configurature would
+type InitShade string
+
+type ResetDescConf struct {
+	Shade InitShade `default:"dark"`
+	Name  string
+}
+
+func init() {
+	co.AddMapValueType("",
+		[]string{"dark", "light"},
+		[]InitShade{"#000000", "#ffffff"},
+	)
+	co.AddDescriptions[ResetDescConf](map[string]string{"Shade": "Shade of the theme"})
+}
+
+func TestResetForTest_KeepsInitRegistrations(t *testing.T) {
+	assert := assert.New(t)
+
+	// Registrations are kept up to the first call to ResetForTest() or
+	// Configure()
+	co.ResetForTest()
+	co.AddDescriptions[ResetDescConf](map[string]string{"Name": "Name of the theme"})
+	co.ResetForTest()
+
+	conf := co.Configure[ResetDescConf](&co.Options{NoRecover: true, Args: []string{}})
+	assert.Equal(InitShade("#000000"), conf.Shade)
+
+	fields := co.Fields[ResetDescConf](nil)
+	assert.Equal("Shade of the theme", fields[0].Description)
+	assert.Equal("name", fields[1].Description)
+}
@@ -0,0 +1,75 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains helpers that keep the values of fields tagged secret:""
+out of errors, usage and printed output
+*/
+package configurature
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Printed in place of secret values
+const redactedValue = "xxxxx"
+
+// markSecret annotates the flag of a field tagged secret:"" and hides its
+// default value, if it has one, in usage
+func markSecret(fs *pflag.FlagSet, name string, hasDefault bool) {
+	fs.SetAnnotation(name, annotationSecret, []string{"true"})
+	if hasDefault {
+		fs.Lookup(name).DefValue = redactedValue
+	}
+}
+
+// isSecret returns whether fl is the flag of a field tagged secret:""
+func isSecret(fl *pflag.Flag) bool {
+	_, ok := fl.Annotations[annotationSecret]
+	return ok
+}
+
+// redactedFlagValue returns the value of fl with secrets and passwords
+// redacted
+func redactedFlagValue(fl *pflag.Flag) string {
+	val := fl.Value.String()
+	if r, ok := fl.Value.(redacter); ok {
+		return r.Redacted()
+	} else if isSecret(fl) && val != "" {
+		return redactedValue
+	}
+	return val
+}
+
+// maskSecret replaces value in msg with redactedValue if fl is secret
+func maskSecret(fl *pflag.Flag, msg string, value string) string {
+	if !isSecret(fl) || value == "" {
+		return msg
+	}
+	msg = strings.ReplaceAll(msg, strconv.Quote(value), strconv.Quote(redactedValue))
+	return strings.ReplaceAll(msg, value, redactedValue)
+}
+
+// setFlagMasked sets the value of the flag fl in fs like fs.Set, but keeps
+// the values of secret flags out of the error returned
+func setFlagMasked(fs *pflag.FlagSet, fl *pflag.Flag, value string) error {
+	if err := fs.Set(fl.Name, value); err != nil {
+		return errors.New(maskSecret(fl, err.Error(), value))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the check for secret values given on the command line,
+where they can be seen by other users in ps output and in shell history
+*/
+package configurature
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// SecretArgCheck is what to do when the value of a field tagged secret:"" is
+// given on the command line
+type SecretArgCheck string
+
+// Secret argument checks
+const (
+	SecretArgWarn SecretArgCheck = "warn" // Print a warning
+	SecretArgFail SecretArgCheck = "fail" // Fail to load the configuration
+)
+
+// checkSecretArgs warns or fails, as set by Options.CheckSecretArgs, if any
+// secret values were given on the command line
+func (c *configurer) checkSecretArgs(fs *pflag.FlagSet) {
+	fs.Visit(func(fl *pflag.Flag) {
+		if !isSecret(fl) {
+			return
+		}
+		msg := fmt.Sprintf("secret --%s was given on the command line, where it can be seen in ps "+
+			"output and shell history; set it with %s instead", fl.Name, c.secretAlternative(fl))
+		switch c.opts.CheckSecretArgs {
+		case SecretArgWarn:
+			fmt.Fprintf(c.opts.errOutput(), "warning: %s\n", msg)
+		case SecretArgFail:
+			panic(msg)
+		default:
+			panic(fmt.Sprintf("unsupported secret argument check: %s", c.opts.CheckSecretArgs))
+		}
+	})
+}
+
+// secretAlternative describes the ways fl can be set other than on the
+// command line
+func (c *configurer) secretAlternative(fl *pflag.Flag) string {
+	if c.opts.EnvPrefix != "" && !c.opts.DisableEnv {
+		return fmt.Sprintf("the %s environment variable or a config file",
+			c.opts.envVarName(fl.Name, fl.Annotations[annotationAncestors]))
+	}
+	return "an environment variable or a config file"
+}
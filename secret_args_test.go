@@ -0,0 +1,71 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type secretArgsConf struct {
+	User     string `default:"app"`
+	Password string `secret:""`
+}
+
+func TestCheckSecretArgs_Warn(t *testing.T) {
+	assert := assert.New(t)
+	errOut := &bytes.Buffer{}
+
+	c := co.Configure[secretArgsConf](&co.Options{
+		NoRecover:       true,
+		EnvPrefix:       "MYAPP_",
+		CheckSecretArgs: co.SecretArgWarn,
+		ErrOutput:       errOut,
+		Args:            []string{"--user", "admin", "--password", "hunter2"},
+	})
+
+	assert.Equal("hunter2", c.Password)
+	assert.Equal("warning: secret --password was given on the command line, where it can be seen in "+
+		"ps output and shell history; set it with the MYAPP_PASSWORD environment variable or a "+
+		"config file instead\n", errOut.String())
+}
+
+func TestCheckSecretArgs_Fail(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.PanicsWithValue("secret --password was given on the command line, where it can be seen in "+
+		"ps output and shell history; set it with an environment variable or a config file instead", func() {
+		co.Configure[secretArgsConf](&co.Options{
+			NoRecover:       true,
+			CheckSecretArgs: co.SecretArgFail,
+			Args:            []string{"--password", "hunter2"},
+		})
+	})
+
+	// Other sources are fine
+	t.Setenv("MYAPP_PASSWORD", "hunter2")
+	assert.NotPanics(func() {
+		co.Configure[secretArgsConf](&co.Options{
+			NoRecover:       true,
+			EnvPrefix:       "MYAPP_",
+			CheckSecretArgs: co.SecretArgFail,
+			Args:            []string{"--user", "admin"},
+		})
+	})
+}
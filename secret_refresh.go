@@ -0,0 +1,101 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the refreshing of LazySecret fields, so that secrets can be
+rotated without restarting
+*/
+package configurature
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// RefreshSecrets fetches the LazySecret fields of config, a pointer to a
+// config struct, again if they have been fetched. It returns whether any of
+// the secrets changed.
+func RefreshSecrets(ctx context.Context, config any) (bool, error) {
+	changed := false
+	var errs []error
+	var visit func(v reflect.Value)
+	visit = func(v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Ptr:
+			if !v.IsNil() {
+				visit(v.Elem())
+			}
+		case reflect.Struct:
+			if s, ok := v.Interface().(LazySecret); ok {
+				c, err := s.Refresh(ctx)
+				changed = changed || c
+				if err != nil {
+					errs = append(errs, err)
+				}
+				return
+			}
+			for i := 0; i < v.NumField(); i++ {
+				if v.Type().Field(i).IsExported() {
+					visit(v.Field(i))
+				}
+			}
+		}
+	}
+	visit(reflect.ValueOf(config))
+	return changed, errors.Join(errs...)
+}
+
+// refreshSecrets calls RefreshSecrets on the latest config of type T every
+// Options.SecretRefresh until the configurer's context is done or
+// ResetForTest() is called. Subscribers and OnChange are notified when
+// secrets change.
+func refreshSecrets[T any](c *configurer) {
+	ctx := c.backgroundContext()
+
+	go func() {
+		ticker := time.NewTicker(c.opts.SecretRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			// The latest config is gone after ResetForTest()
+			l := subscriptionFor[T]().latest.Load()
+			if l == nil {
+				continue
+			}
+			config := l.config
+			changed, err := RefreshSecrets(ctx, config)
+			if err != nil {
+				fmt.Fprintf(c.opts.errOutput(), "error refreshing secrets: %v\n", err)
+			}
+			if !changed {
+				continue
+			}
+			publish(config, c.opts.Snapshots)
+			if c.opts.OnChange != nil {
+				// The stored config isn't handed out with snapshots
+				if c.opts.Snapshots {
+					config = Clone(config)
+				}
+				c.opts.OnChange(config)
+			}
+		}
+	}()
+}
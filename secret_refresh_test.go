@@ -0,0 +1,176 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type rotatingConf struct {
+	DB struct {
+		Password co.LazySecret `default:"vault:db"`
+	}
+	APIKey co.LazySecret `default:"vault:api"`
+}
+
+func TestRefreshSecrets(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	vault := &testResolver{secrets: map[string]string{"db": "one", "api": "key"}}
+
+	c := co.Configure[rotatingConf](&co.Options{
+		NoRecover:       true,
+		Args:            []string{},
+		SecretResolvers: map[string]co.SecretResolver{"vault": vault},
+	})
+	s, _ := c.DB.Password.Get(ctx)
+	assert.Equal("one", s)
+
+	// Only fetched secrets are refreshed
+	changed, err := co.RefreshSecrets(ctx, c)
+	assert.NoError(err)
+	assert.False(changed)
+	assert.Equal(2, vault.calls)
+
+	vault.secrets["db"] = "two"
+	changed, err = co.RefreshSecrets(ctx, c)
+	assert.NoError(err)
+	assert.True(changed)
+	s, _ = c.DB.Password.Get(ctx)
+	assert.Equal("two", s)
+
+	// The cached secret is kept if it can't be fetched
+	delete(vault.secrets, "db")
+	changed, err = c.DB.Password.Refresh(ctx)
+	assert.False(changed)
+	assert.EqualError(err, "error resolving secret db: secret not found")
+	s, _ = c.DB.Password.Get(ctx)
+	assert.Equal("two", s)
+}
+
+func TestRefreshSecrets_Periodic(t *testing.T) {
+	assert := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	vault := &testResolver{secrets: map[string]string{"db": "one", "api": "key"}}
+	changes := make(chan *rotatingConf, 1)
+
+	c := co.ConfigureContext[rotatingConf](ctx, &co.Options{
+		NoRecover:       true,
+		Args:            []string{},
+		SecretResolvers: map[string]co.SecretResolver{"vault": vault},
+		SecretRefresh:   10 * time.Millisecond,
+		OnChange: func(config any) {
+			select {
+			case changes <- config.(*rotatingConf):
+			default:
+			}
+		},
+	})
+	s, _ := c.DB.Password.Get(ctx)
+	assert.Equal("one", s)
+
+	vault.mu.Lock()
+	vault.secrets["db"] = "two"
+	vault.mu.Unlock()
+
+	select {
+	case config := <-changes:
+		s, _ = config.DB.Password.Get(ctx)
+		assert.Equal("two", s)
+	case <-time.After(time.Second):
+		t.Fatal("OnChange was not called after the secret was rotated")
+	}
+}
+
+func TestRefreshSecrets_Reloads(t *testing.T) {
+	type Conf struct {
+		Host   string
+		APIKey co.LazySecret `default:"vault:api"`
+	}
+	t.Cleanup(co.ResetForTest)
+	assert := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	vault := &testResolver{secrets: map[string]string{"api": "key"}}
+	s := newWatchSource(map[string]any{"host": "h1"})
+	changes := make(chan *Conf, 1)
+
+	co.ConfigureContext[Conf](ctx, &co.Options{
+		NoRecover:       true,
+		Args:            []string{},
+		Sources:         []co.Source{s},
+		WatchSources:    true,
+		SecretResolvers: map[string]co.SecretResolver{"vault": vault},
+		SecretRefresh:   50 * time.Millisecond,
+		OnChange: func(config any) {
+			changes <- config.(*Conf)
+		},
+	})
+
+	// Reloads don't start more refreshers
+	for _, host := range []string{"h2", "h3"} {
+		s.updates <- map[string]any{"host": host}
+		select {
+		case nc := <-changes:
+			assert.Equal(host, nc.Host)
+		case <-time.After(5 * time.Second):
+			t.Fatal("configuration was not reloaded")
+		}
+	}
+	_, err := co.Latest[Conf]().APIKey.Get(ctx)
+	assert.NoError(err)
+
+	vault.mu.Lock()
+	vault.calls = 0
+	vault.mu.Unlock()
+	time.Sleep(500 * time.Millisecond)
+	vault.mu.Lock()
+	defer vault.mu.Unlock()
+	assert.LessOrEqual(vault.calls, 11)
+	assert.GreaterOrEqual(vault.calls, 5)
+}
+
+func TestRefreshSecrets_StoppedByResetForTest(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	co.Configure[rotatingConf](&co.Options{
+		NoRecover:       true,
+		Args:            []string{},
+		SecretResolvers: map[string]co.SecretResolver{"vault": &testResolver{secrets: map[string]string{}}},
+		SecretRefresh:   10 * time.Millisecond,
+	})
+	co.ResetForTest()
+
+	// The refresher of the first config would refresh this one's secrets
+	vault := &testResolver{secrets: map[string]string{"db": "one", "api": "key"}}
+	c := co.Configure[rotatingConf](&co.Options{
+		NoRecover:       true,
+		Args:            []string{},
+		SecretResolvers: map[string]co.SecretResolver{"vault": vault},
+	})
+	c.DB.Password.Get(ctx)
+
+	time.Sleep(100 * time.Millisecond)
+	vault.mu.Lock()
+	defer vault.mu.Unlock()
+	assert.Equal(1, vault.calls)
+}
@@ -0,0 +1,88 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+	flag "github.com/spf13/pflag"
+)
+
+type SecretConf struct {
+	Conf   co.ConfigFile
+	Token  string  `secret:"" default:"t0ps3cret"`
+	Pin    co.Port `secret:""`
+	Public string  `default:"visible"`
+}
+
+func TestSecret_FlagError(t *testing.T) {
+	if os.Getenv("TEST_PASSTHROUGH") == "1" {
+		co.Configure[SecretConf](&co.Options{
+			Args:  []string{"--pin", "hunter2"},
+			Usage: func(_ *flag.FlagSet) {},
+		})
+		os.Exit(0)
+	}
+
+	stdout, stderr := runExternal(t)
+	assert.Contains(t, stderr, `invalid argument "xxxxx" for "--pin" flag: invalid port "xxxxx"`)
+	assert.NotContains(t, stdout+stderr, "hunter2")
+	assert.NotContains(t, stdout+stderr, "t0ps3cret")
+}
+
+func TestSecret_Usage(t *testing.T) {
+	if os.Getenv("TEST_PASSTHROUGH") == "1" {
+		co.Configure[SecretConf](&co.Options{
+			Args: []string{"-h"},
+		})
+		panic("Should have exited")
+	}
+
+	stdout, _ := runExternal(t)
+	assert.Contains(t, stdout, `(default "xxxxx")`)
+	assert.Contains(t, stdout, "(default \"visible\")")
+	assert.NotContains(t, stdout, "t0ps3cret")
+}
+
+func TestSecret_PrintChanged(t *testing.T) {
+	if os.Getenv("TEST_PASSTHROUGH") == "1" {
+		co.Configure[SecretConf](&co.Options{
+			Args: []string{"--print_changed", "--pin", "1234"},
+		})
+		panic("Should have exited")
+	}
+
+	stdout, _ := runExternal(t)
+	assert.Equal(t, "pin=xxxxx (flag)\n", stdout)
+}
+
+func TestSecret_EnvTemplate(t *testing.T) {
+	if os.Getenv("TEST_PASSTHROUGH") == "1" {
+		co.Configure[SecretConf](&co.Options{
+			Args:      []string{"--print_env_template"},
+			EnvPrefix: "APP_",
+		})
+		panic("Should have exited")
+	}
+
+	stdout, _ := runExternal(t)
+	assert.Contains(t, stdout, `APP_TOKEN="xxxxx"`)
+	assert.Contains(t, stdout, `APP_PIN="xxxxx"`)
+	assert.Contains(t, stdout, `APP_PUBLIC="visible"`)
+}
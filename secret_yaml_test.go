@@ -0,0 +1,48 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !configurature_core
+
+package configurature_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestSecret_SourceErrors(t *testing.T) {
+	confFile := t.TempDir() + "/conf.yaml"
+	os.WriteFile(confFile, []byte("pin: hunter2\n"), 0644)
+
+	assert.PanicsWithValue(t, `unable to set value for pin: invalid port "xxxxx": must be a number between 1 and 65535`, func() {
+		co.Configure[SecretConf](&co.Options{
+			NoRecover: true,
+			Args:      []string{"--conf", confFile},
+		})
+	})
+
+	t.Setenv("SECRET_PIN", "hunter2")
+	assert.PanicsWithValue(t, `invalid value "xxxxx" for environment variable SECRET_PIN (port): `+
+		`invalid port "xxxxx": must be a number between 1 and 65535`, func() {
+		co.Configure[SecretConf](&co.Options{
+			NoRecover: true,
+			Args:      []string{},
+			EnvPrefix: "SECRET_",
+		})
+	})
+}
@@ -27,8 +27,9 @@ import (
 	"strings"
 )
 
-// addSliceType adds a custom slice type
-func addSliceType[T any]() {
+// addSliceType adds a custom slice type to types, or to the customFlagMap if
+// types is nil
+func addSliceType[T any](types *Types) {
 
 	// Create a new var of type *structFieldType and make sure it implements the
 	// required Value interface
@@ -37,7 +38,7 @@ func addSliceType[T any]() {
 		panic(fmt.Sprintf("%T must implement Value", ptrType))
 	}
 
-	addToCustomFlagMap[sliceFieldOfType[T], T]()
+	registerType(types, reflect.TypeFor[T](), customFlagFn[sliceFieldOfType[T]](), nil)
 }
 
 // sliceFieldOfType is a wrapper around a slice of custom field types. It implements the Value
@@ -46,6 +47,7 @@ func addSliceType[T any]() {
 type sliceFieldOfType[T any] struct {
 	typeName string
 	values   T
+	changed  bool // Set() has been called since the default was set
 }
 
 // Return a string representation of the slice (csv format)
@@ -62,7 +64,7 @@ func (f *sliceFieldOfType[T]) String() string {
 	w := csv.NewWriter(buf)
 	out := make([]string, vals.Len())
 	for idx := range vals.Len() {
-		out[idx] = fmt.Sprintf("%s", vals.Index(idx))
+		out[idx] = fmt.Sprintf("%s", vals.Index(idx).Addr().Interface())
 	}
 	w.Write(out)
 	w.Flush()
@@ -78,7 +80,9 @@ func (f *sliceFieldOfType[T]) Type() string {
 	return f.typeName
 }
 
-// Set the slice values from a csv string
+// Set the slice values from a csv string. The first call replaces the
+// default values and later calls append to them so that the flag can be
+// repeated.
 func (f *sliceFieldOfType[T]) Set(v string) error {
 	stringReader := strings.NewReader(v)
 	csvReader := csv.NewReader(stringReader)
@@ -92,14 +96,13 @@ func (f *sliceFieldOfType[T]) Set(v string) error {
 		panic("T must be a slice")
 	}
 
-	// Initialize the values slice
-	newSlice := reflect.MakeSlice(reflect.TypeFor[T](), len(vals), len(vals)) //
-	reflect.ValueOf(&(f.values)).Elem().Set(newSlice)
+	// Initialize the new values slice
+	newSlice := reflect.MakeSlice(reflect.TypeFor[T](), len(vals), len(vals))
 
 	for idx, v := range vals {
 
 		// Ref to the slice element
-		fv := reflect.ValueOf(f.values).Index(idx)
+		fv := newSlice.Index(idx)
 
 		// Create a new type of the slice element and call Set() on it
 		nv := reflect.New(fv.Type())
@@ -116,9 +119,20 @@ func (f *sliceFieldOfType[T]) Set(v string) error {
 		fv.Set(nv.Elem())
 
 	}
+
+	if f.changed {
+		newSlice = reflect.AppendSlice(reflect.ValueOf(f.values), newSlice)
+	}
+	reflect.ValueOf(&(f.values)).Elem().Set(newSlice)
+	f.changed = true
 	return nil
 }
 
+// markDefault records that the current values are the default values
+func (f *sliceFieldOfType[T]) markDefault() {
+	f.changed = false
+}
+
 // Return the slice values
 func (f *sliceFieldOfType[T]) Interface() any {
 	return f.values
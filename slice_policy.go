@@ -0,0 +1,113 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains helpers applying whitespace trimming and empty element
+policies to slice values from the environment and config files
+*/
+package configurature
+
+import (
+	"encoding/csv"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// annotateSlicePolicy records the trim and empty tags of a field on its flag
+func annotateSlicePolicy(fs *pflag.FlagSet, name string, tags *reflect.StructTag) {
+	if trim, ok := tags.Lookup("trim"); ok {
+		if trim != "" && trim != "true" && trim != "false" {
+			panic(fmt.Sprintf("trim tag of flag %s: must be \"true\" or \"false\"", name))
+		}
+		fs.SetAnnotation(name, annotationTrim, []string{fmt.Sprint(trim != "false")})
+	}
+	if empty, ok := tags.Lookup("empty"); ok {
+		if empty != "drop" && empty != "keep" {
+			panic(fmt.Sprintf("empty tag of flag %s: must be \"drop\" or \"keep\"", name))
+		}
+		fs.SetAnnotation(name, annotationEmpty, []string{empty})
+	}
+}
+
+// isSliceValue returns true if v holds a slice of values
+func isSliceValue(v pflag.Value) bool {
+	v = unwrapValue(v)
+	if _, ok := v.(pflag.SliceValue); ok {
+		return true
+	}
+	return strings.HasPrefix(v.Type(), "[]")
+}
+
+// slicePolicy returns whether elements of a slice flag are trimmed and
+// whether empty elements are dropped
+func slicePolicy(fl *pflag.Flag, opts *Options) (trim bool, drop bool) {
+	trim = opts.TrimSliceElements
+	if a, ok := fl.Annotations[annotationTrim]; ok {
+		trim = a[0] == "true"
+	}
+	drop = opts.DropEmptyElements
+	if a, ok := fl.Annotations[annotationEmpty]; ok {
+		drop = a[0] == "drop"
+	}
+	return trim, drop
+}
+
+// sliceElements applies the trim and empty element policies of a slice flag
+// to elements
+func sliceElements(fl *pflag.Flag, opts *Options, elements []string) []string {
+	trim, drop := slicePolicy(fl, opts)
+
+	out := make([]string, 0, len(elements))
+	for _, e := range elements {
+		if trim {
+			e = strings.TrimSpace(e)
+		}
+		if drop && e == "" {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// sliceValue applies the trim and empty element policies of a slice flag to
+// a string of its elements. Values of other flags are returned as is.
+func sliceValue(fl *pflag.Flag, opts *Options, v string) string {
+	if trim, drop := slicePolicy(fl, opts); (!trim && !drop) || !isSliceValue(fl.Value) {
+		return v
+	}
+
+	// Elements of fields with a delim tag are separated by it
+	if d, ok := fl.Value.(*delimitedValue); ok {
+		return strings.Join(sliceElements(fl, opts, strings.Split(v, d.delim)), d.delim)
+	}
+
+	elements, err := csv.NewReader(strings.NewReader(v)).Read()
+	if err != nil {
+		// Leave it to the flag to report the error
+		return v
+	}
+	elements = sliceElements(fl, opts, elements)
+	if len(elements) == 0 {
+		return ""
+	}
+	buf := &strings.Builder{}
+	w := csv.NewWriter(buf)
+	w.Write(elements)
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type SlicePolicyConfig struct {
+	Conf   co.ConfigFile
+	Names  []string
+	Kept   []string  `trim:"false" empty:"keep"`
+	Tagged []string  `trim:"" empty:"drop"`
+	Ports  []co.Port `trim:"true"`
+	Hosts  []string  `delim:";" trim:"" empty:"drop"`
+}
+
+func TestSlicePolicy_Env(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("SP_NAMES", " a, b ,,c")
+	t.Setenv("SP_KEPT", " a, b ,,c")
+	t.Setenv("SP_TAGGED", " a, b ,,c")
+	t.Setenv("SP_PORTS", "80, 443")
+	t.Setenv("SP_HOSTS", " h1 ;; h2")
+
+	// Elements are kept as they are by default
+	c := co.Configure[SlicePolicyConfig](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+		EnvPrefix: "SP_",
+	})
+	assert.Equal([]string{" a", " b ", "", "c"}, c.Names)
+	assert.Equal([]string{"a", "b", "c"}, c.Tagged)
+	assert.Equal([]co.Port{80, 443}, c.Ports)
+	assert.Equal([]string{"h1", "h2"}, c.Hosts)
+
+	c = co.Configure[SlicePolicyConfig](&co.Options{
+		NoRecover:         true,
+		Args:              []string{},
+		EnvPrefix:         "SP_",
+		TrimSliceElements: true,
+		DropEmptyElements: true,
+	})
+	assert.Equal([]string{"a", "b", "c"}, c.Names)
+	assert.Equal([]string{" a", " b ", "", "c"}, c.Kept)
+}
+
+func TestSlicePolicy_BadTag(t *testing.T) {
+	type Conf struct {
+		Names []string `empty:"remove"`
+	}
+	assert.PanicsWithValue(t, "empty tag of flag names: must be \"drop\" or \"keep\"", func() {
+		co.Configure[Conf](&co.Options{NoRecover: true, Args: []string{}})
+	})
+}
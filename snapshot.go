@@ -0,0 +1,56 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the helpers that hand out copies of configurations loaded
+with Options.Snapshots set
+*/
+package configurature
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+// snapshot holds a configuration loaded with Options.Snapshots set. Copies of
+// it are handed out so that callers can't modify shared configuration.
+type snapshot struct {
+	config any
+	sum    [sha256.Size]byte // Checksum of config in debug builds
+}
+
+// newSnapshot returns a snapshot of config
+func newSnapshot(config any) *snapshot {
+	s := &snapshot{config: config}
+	if debugBuild {
+		s.sum = checksum(config)
+	}
+	return s
+}
+
+// check panics in debug builds if the configuration has been modified since
+// it was loaded
+func (s *snapshot) check() {
+	if debugBuild && checksum(s.config) != s.sum {
+		panic(fmt.Sprintf("configuration %T was modified after it was loaded", s.config))
+	}
+}
+
+// snapshotOf returns a copy of config if s is not nil, or else config
+func snapshotOf[T any](config *T, s *snapshot) *T {
+	if s == nil || config == nil {
+		return config
+	}
+	return Clone(config)
+}
@@ -0,0 +1,67 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build configurature_debug
+
+package configurature_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestSnapshots_ReturnedConfigNotChecked(t *testing.T) {
+	t.Cleanup(co.ResetForTest)
+
+	c := co.Configure[SnapshotConf](&co.Options{
+		NoRecover: true,
+		Snapshots: true,
+		Args:      []string{},
+	})
+	_, err := co.Get[SnapshotSub]()
+	assert.Nil(t, err)
+
+	// The config returned by Configure is not the stored one
+	c.Sub.Hosts[1] = "changed"
+
+	assert.NotPanics(t, func() {
+		co.Get[SnapshotSub]()
+	})
+	assert.Equal(t, "b", co.Latest[SnapshotConf]().Sub.Hosts[1])
+}
+
+func TestSnapshots_SelfReferencingValue(t *testing.T) {
+	type Conf struct {
+		Greeting co.GoTemplate `default:"hello {{.}}"`
+	}
+	t.Cleanup(co.ResetForTest)
+
+	c := co.Configure[Conf](&co.Options{
+		NoRecover: true,
+		Snapshots: true,
+		Args:      []string{},
+	})
+	assert.NotPanics(t, func() {
+		co.Latest[Conf]()
+	})
+
+	assert.NoError(t, c.Greeting.Set("bye {{.}}"))
+	assert.NotPanics(t, func() {
+		co.Latest[Conf]()
+	})
+	assert.Equal(t, "hello {{.}}", co.Latest[Conf]().Greeting.String())
+}
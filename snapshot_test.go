@@ -0,0 +1,101 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type SnapshotSub struct {
+	Hosts []string          `default:"a,b"`
+	Tags  map[string]string `default:"env=prod"`
+	Port  *int              `default:"80"`
+}
+
+type SnapshotConf struct {
+	Name string `default:"app"`
+	Sub  SnapshotSub
+}
+
+func TestSnapshots(t *testing.T) {
+	t.Cleanup(co.ResetForTest)
+	assert := assert.New(t)
+
+	c := co.Configure[SnapshotConf](&co.Options{
+		NoRecover: true,
+		Snapshots: true,
+		Name:      "snap",
+		Args:      []string{},
+	})
+
+	got, err := co.Get[SnapshotConf]()
+	assert.Nil(err)
+	assert.Equal(c, got)
+	assert.NotSame(c, got)
+
+	got.Name = "changed"
+	got.Sub.Hosts[0] = "changed"
+	got.Sub.Tags["env"] = "changed"
+	*got.Sub.Port = 1
+
+	sub, err := co.Get[SnapshotSub]()
+	assert.Nil(err)
+	assert.Equal([]string{"a", "b"}, sub.Hosts)
+	assert.Equal(map[string]string{"env": "prod"}, sub.Tags)
+	assert.Equal(80, *sub.Port)
+
+	named, err := co.GetNamed[SnapshotConf]("snap")
+	assert.Nil(err)
+	assert.Equal("app", named.Name)
+	assert.NotSame(c, named)
+
+	latest := co.Latest[SnapshotConf]()
+	assert.Equal(c, latest)
+	assert.NotSame(c, latest)
+	assert.NotSame(latest, co.Latest[SnapshotConf]())
+
+	// Modifying the config returned by Configure doesn't modify the stored one
+	c.Name = "changed"
+	c.Sub.Hosts[1] = "changed"
+	*c.Sub.Port = 2
+
+	got, err = co.Get[SnapshotConf]()
+	assert.Nil(err)
+	assert.Equal("app", got.Name)
+	assert.Equal([]string{"a", "b"}, got.Sub.Hosts)
+	assert.Equal(80, *got.Sub.Port)
+	named, _ = co.GetNamed[SnapshotConf]("snap")
+	assert.Equal("app", named.Name)
+	assert.Equal("app", co.Latest[SnapshotConf]().Name)
+}
+
+func TestSnapshots_Disabled(t *testing.T) {
+	t.Cleanup(co.ResetForTest)
+	assert := assert.New(t)
+
+	c := co.Configure[SnapshotConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+	})
+
+	got, err := co.Get[SnapshotConf]()
+	assert.Nil(err)
+	assert.Same(c, got)
+	assert.Same(c, co.Latest[SnapshotConf]())
+}
@@ -0,0 +1,200 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Source interface and helpers for loading sources
+*/
+package configurature
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	fp "path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spf13/pflag"
+)
+
+// Source is a configuration source, such as a remote key/value store or HTTP
+// endpoint. Its values are laid out like a config file.
+type Source interface {
+	// Name identifies the source in messages and --print_changed output
+	Name() string
+
+	// Load returns the source's values
+	Load(ctx context.Context) (map[string]any, error)
+}
+
+// RetryPolicy controls retrying Sources that fail to load. Delays between
+// attempts grow exponentially. The zero value does not retry.
+type RetryPolicy struct {
+	Attempts     int           // Total number of attempts. Defaults to 1
+	InitialDelay time.Duration // Delay before the second attempt. Defaults to 100ms
+	MaxDelay     time.Duration // Maximum delay between attempts. Defaults to 10s
+	Multiplier   float64       // Factor the delay grows by after each attempt. Defaults to 2
+}
+
+// withDefaults returns a copy of the policy with unset fields set to their
+// defaults
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.Attempts < 1 {
+		p.Attempts = 1
+	}
+	if p.InitialDelay <= 0 {
+		p.InitialDelay = 100 * time.Millisecond
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = 10 * time.Second
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = 2
+	}
+	return p
+}
+
+var (
+	// Values last loaded from each source by name. Used for SourceFallback
+	sourceCache = make(map[string]map[string]any)
+
+	// Protects sourceCache
+	sourceCacheMu sync.Mutex
+)
+
+// loadSources adds setters for values found in the configured sources to
+// values
+func (c *configurer) loadSources(fs *pflag.FlagSet, values sourceSetters) {
+	for _, s := range c.opts.Sources {
+		c.checkContext()
+		gMap := c.loadSource(s)
+		c.setFlagsFromGenericMap(&gMap, []string{}, fs, "", s.Name(), values)
+	}
+}
+
+// loadSource loads a source, retrying according to the SourceRetry policy and
+// falling back to its cached values if SourceFallback is set
+func (c *configurer) loadSource(s Source) map[string]any {
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	policy := c.opts.SourceRetry.withDefaults()
+
+	delay := policy.InitialDelay
+	var err error
+	for attempt := 1; ; attempt++ {
+		var gMap map[string]any
+		if gMap, err = s.Load(ctx); err == nil {
+			sourceCacheMu.Lock()
+			sourceCache[s.Name()] = maps.Clone(gMap)
+			sourceCacheMu.Unlock()
+			if c.opts.SourceCacheDir != "" {
+				c.writeSourceCacheFile(s.Name(), gMap)
+			}
+			return gMap
+		}
+		if c.opts.Metrics != nil {
+			c.opts.Metrics.SourceFailed(s.Name(), err)
+		}
+		if attempt >= policy.Attempts || !sleepContext(ctx, delay) {
+			break
+		}
+		delay = min(time.Duration(float64(delay)*policy.Multiplier), policy.MaxDelay)
+	}
+
+	if c.opts.SourceFallback {
+		sourceCacheMu.Lock()
+		gMap, ok := sourceCache[s.Name()]
+		sourceCacheMu.Unlock()
+		if !ok && c.opts.SourceCacheDir != "" {
+			gMap, ok = c.readSourceCacheFile(s.Name())
+		}
+		if ok {
+			fmt.Fprintf(c.opts.errOutput(), "error loading source %s, using cached values: %v\n", s.Name(), err)
+			return maps.Clone(gMap)
+		}
+	}
+	panic(fmt.Sprintf("error loading source %s: %v", s.Name(), err))
+}
+
+// sourceCacheFile returns the name of the file in SourceCacheDir holding the
+// values of the named source
+func (c *configurer) sourceCacheFile(name string) string {
+	safeName := strings.Map(func(r rune) rune {
+		if r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '.') {
+			return r
+		}
+		return '_'
+	}, name)
+	return fp.Join(c.opts.SourceCacheDir, safeName+".json")
+}
+
+// writeSourceCacheFile saves the values loaded from the named source to its
+// cache file. Errors are printed rather than failing configuration.
+func (c *configurer) writeSourceCacheFile(name string, gMap map[string]any) {
+	data, err := json.Marshal(gMap)
+	if err == nil {
+		err = os.MkdirAll(c.opts.SourceCacheDir, 0700)
+	}
+	if err == nil {
+		// Write to a temporary file and rename it so that the cache file is
+		// never partially written
+		fileName := c.sourceCacheFile(name)
+		if err = os.WriteFile(fileName+".tmp", data, 0600); err == nil {
+			err = os.Rename(fileName+".tmp", fileName)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(c.opts.errOutput(), "error caching values of source %s: %v\n", name, err)
+	}
+}
+
+// readSourceCacheFile returns the values of the named source saved in its
+// cache file
+func (c *configurer) readSourceCacheFile(name string) (map[string]any, bool) {
+	data, err := os.ReadFile(c.sourceCacheFile(name))
+	if err != nil {
+		return nil, false
+	}
+
+	// Keep numbers as they were written rather than converting them to
+	// float64
+	gMap := make(map[string]any)
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&gMap); err != nil {
+		fmt.Fprintf(c.opts.errOutput(), "error reading cached values of source %s: %v\n", name, err)
+		return nil, false
+	}
+	return gMap, true
+}
+
+// sleepContext waits for d to pass. It returns false if ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
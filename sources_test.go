@@ -0,0 +1,182 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+// testSource fails to load until it has been loaded failures times
+type testSource struct {
+	name     string
+	values   map[string]any
+	failures int
+	loads    int
+}
+
+func (s *testSource) Name() string { return s.name }
+
+func (s *testSource) Load(ctx context.Context) (map[string]any, error) {
+	s.loads++
+	if s.loads <= s.failures {
+		return nil, errors.New("unavailable")
+	}
+	return s.values, nil
+}
+
+type SourceConfig struct {
+	Conf  co.ConfigFile
+	Host  string `default:"localhost"`
+	Port  int
+	Names []string
+	DB    struct {
+		User string
+	}
+}
+
+func TestSources(t *testing.T) {
+	t.Setenv("SRC_PORT", "9")
+	s1 := &testSource{name: "one", values: map[string]any{
+		"host": "h1", "port": 1, "names": []any{"a", "b"}, "db": map[string]any{"user": "u"},
+	}}
+	s2 := &testSource{name: "two", values: map[string]any{"host": "h2"}}
+
+	c := co.Configure[SourceConfig](&co.Options{
+		Args:      []string{},
+		NoRecover: true,
+		EnvPrefix: "SRC_",
+		Sources:   []co.Source{s1, s2},
+	})
+
+	assert := assert.New(t)
+	assert.Equal("h2", c.Host)
+	assert.Equal(9, c.Port)
+	assert.Equal([]string{"a", "b"}, c.Names)
+	assert.Equal("u", c.DB.User)
+}
+
+func TestSources_Retry(t *testing.T) {
+	assert := assert.New(t)
+	s := &testSource{name: "retry", values: map[string]any{"host": "h"}, failures: 2}
+
+	c := co.Configure[SourceConfig](&co.Options{
+		Args:        []string{},
+		NoRecover:   true,
+		Sources:     []co.Source{s},
+		SourceRetry: co.RetryPolicy{Attempts: 3, InitialDelay: time.Millisecond},
+	})
+	assert.Equal("h", c.Host)
+	assert.Equal(3, s.loads)
+
+	s = &testSource{name: "retry", values: map[string]any{"host": "h"}, failures: 3}
+	assert.PanicsWithValue("error loading source retry: unavailable", func() {
+		co.Configure[SourceConfig](&co.Options{
+			Args:        []string{},
+			NoRecover:   true,
+			Sources:     []co.Source{s},
+			SourceRetry: co.RetryPolicy{Attempts: 3, InitialDelay: time.Millisecond},
+		})
+	})
+	assert.Equal(3, s.loads)
+}
+
+func TestSources_RetryContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	s := &testSource{name: "slow", failures: 100}
+
+	assert.Panics(t, func() {
+		co.ConfigureContext[SourceConfig](ctx, &co.Options{
+			Args:        []string{},
+			NoRecover:   true,
+			Sources:     []co.Source{s},
+			SourceRetry: co.RetryPolicy{Attempts: 100, InitialDelay: 5 * time.Millisecond},
+		})
+	})
+	assert.Less(t, s.loads, 100)
+}
+
+func TestSources_Fallback(t *testing.T) {
+	t.Cleanup(co.ResetForTest)
+	assert := assert.New(t)
+
+	s := &testSource{name: "cached", values: map[string]any{"host": "h"}}
+	co.Configure[SourceConfig](&co.Options{
+		Args:      []string{},
+		NoRecover: true,
+		Sources:   []co.Source{s},
+	})
+
+	s = &testSource{name: "cached", failures: 1}
+	c := co.Configure[SourceConfig](&co.Options{
+		Args:           []string{},
+		NoRecover:      true,
+		Sources:        []co.Source{s},
+		SourceFallback: true,
+	})
+	assert.Equal("h", c.Host)
+
+	// Nothing cached for this source
+	s = &testSource{name: "uncached", failures: 1}
+	assert.PanicsWithValue("error loading source uncached: unavailable", func() {
+		co.Configure[SourceConfig](&co.Options{
+			Args:           []string{},
+			NoRecover:      true,
+			Sources:        []co.Source{s},
+			SourceFallback: true,
+		})
+	})
+}
+
+func TestSources_CacheDir(t *testing.T) {
+	t.Cleanup(co.ResetForTest)
+	assert := assert.New(t)
+	dir := t.TempDir() + "/cache"
+
+	s := &testSource{name: "etcd://db/config", values: map[string]any{
+		"port": 1000000, "names": []any{"a", "b"}, "db": map[string]any{"user": "u"},
+	}}
+	co.Configure[SourceConfig](&co.Options{
+		Args:           []string{},
+		NoRecover:      true,
+		Sources:        []co.Source{s},
+		SourceCacheDir: dir,
+	})
+	_, err := os.Stat(dir + "/etcd___db_config.json")
+	assert.Nil(err)
+
+	// Simulate a restart
+	co.ResetForTest()
+
+	s = &testSource{name: "etcd://db/config", failures: 1}
+	c := co.Configure[SourceConfig](&co.Options{
+		Args:           []string{},
+		NoRecover:      true,
+		Sources:        []co.Source{s},
+		SourceFallback: true,
+		SourceCacheDir: dir,
+	})
+	assert.Equal(1000000, c.Port)
+	assert.Equal([]string{"a", "b"}, c.Names)
+	assert.Equal("u", c.DB.User)
+}
@@ -0,0 +1,84 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the defaults and override tags, which set the default
+values of the fields of a sub-config so that the same struct type can be used more than once
+with different defaults
+*/
+package configurature
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// withSubConfigDefaults returns the fields of the sub-config field name, whose
+// config name path is ancestors, with the default values of its direct fields
+// replaced by those in its defaults tag, and then those of any of its nested
+// fields replaced by those in its override tag. E.g.
+//
+//	Replica DBConfig `defaults:"host=replica.local,port=5433"`
+//	Audit   DBConfig `override:"port=5434,tls.ca=/etc/audit/ca.pem"`
+func withSubConfigDefaults(name string, tags reflect.StructTag, fields []structField, ancestors []string) []structField {
+	for _, tag := range []string{"defaults", "override"} {
+		value, ok := tags.Lookup(tag)
+		if !ok {
+			continue
+		}
+		items, err := parseTagItems(value)
+		if err != nil {
+			panic(fmt.Sprintf("invalid %s tag on field %s: %v", tag, name, err))
+		}
+
+		for _, item := range items {
+			path := []string{item[0]}
+			if tag == "override" {
+				path = strings.Split(item[0], ".")
+			}
+			parents := slices.Concat(ancestors, path[:len(path)-1])
+			i := slices.IndexFunc(fields, func(sf structField) bool {
+				return slices.Equal(sf.ancestors, parents) && configKey(sf.field) == path[len(path)-1]
+			})
+			if i < 0 {
+				panic(fmt.Sprintf("invalid %s tag on field %s: unknown field %s", tag, name, item[0]))
+			}
+			fields[i].field.Tag = withDefault(fields[i].field.Tag, item[1])
+		}
+	}
+	return fields
+}
+
+// configKey returns the name of a field relative to its struct, as used in
+// config files and defaults tags
+func configKey(field reflect.StructField) string {
+	if nm, ok := field.Tag.Lookup("name"); ok && nm != "" {
+		return toSnake(nm)
+	}
+	return toSnake(field.Name)
+}
+
+// withDefault returns tags with its default value replaced by def
+func withDefault(tags reflect.StructTag, def string) reflect.StructTag {
+	replaced := ""
+	for _, kv := range parseStructTag(tags) {
+		if kv[0] != "default" && kv[0] != "defaultFile" {
+			replaced += kv[0] + ":" + strconv.Quote(kv[1]) + " "
+		}
+	}
+	return reflect.StructTag(replaced + "default:" + strconv.Quote(def))
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type dbBlock struct {
+	Host     string `default:"localhost"`
+	Port     int    `default:"5432"`
+	ReadOnly bool   `name:"ro"`
+	Tags     []string
+}
+
+func TestSubConfigDefaults(t *testing.T) {
+	type Conf struct {
+		Primary dbBlock
+		Replica dbBlock  `defaults:"host=replica.local,port=5433,ro=true,tags='a,b'"`
+		Backup  *dbBlock `name:"bak" defaults:"host=backup.local"`
+	}
+	assert := assert.New(t)
+
+	c := co.Configure[Conf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--replica_port", "6543"},
+	})
+
+	assert.Equal(dbBlock{Host: "localhost", Port: 5432, Tags: []string{}}, c.Primary)
+	assert.Equal(dbBlock{Host: "replica.local", Port: 6543, ReadOnly: true, Tags: []string{"a", "b"}}, c.Replica)
+	assert.Equal(&dbBlock{Host: "backup.local", Port: 5432, Tags: []string{}}, c.Backup)
+}
+
+func TestSubConfigDefaults_UnknownField(t *testing.T) {
+	type Conf struct {
+		Replica dbBlock `defaults:"hostname=replica.local"`
+	}
+
+	assert.PanicsWithValue(t, "invalid defaults tag on field Replica: unknown field hostname", func() {
+		co.Configure[Conf](&co.Options{NoRecover: true, Args: []string{}})
+	})
+}
+
+func TestSubConfigOverride(t *testing.T) {
+	type Cluster struct {
+		Name string  `default:"main"`
+		DB   dbBlock `defaults:"port=6000"`
+	}
+	type Conf struct {
+		Main  Cluster
+		Audit Cluster `override:"name=audit,db.port=6001,db.host=audit.local"`
+		Both  dbBlock `defaults:"port=1" override:"port=2"`
+	}
+	assert := assert.New(t)
+
+	c := co.Configure[Conf](&co.Options{NoRecover: true, Args: []string{}})
+
+	assert.Equal("main", c.Main.Name)
+	assert.Equal(dbBlock{Host: "localhost", Port: 6000, Tags: []string{}}, c.Main.DB)
+	assert.Equal("audit", c.Audit.Name)
+	assert.Equal(dbBlock{Host: "audit.local", Port: 6001, Tags: []string{}}, c.Audit.DB)
+	assert.Equal(2, c.Both.Port)
+}
+
+func TestSubConfigOverride_UnknownField(t *testing.T) {
+	type Cluster struct {
+		DB dbBlock
+	}
+	type Conf struct {
+		Audit Cluster `override:"db.hostname=audit.local"`
+	}
+
+	assert.PanicsWithValue(t, "invalid override tag on field Audit: unknown field db.hostname", func() {
+		co.Configure[Conf](&co.Options{NoRecover: true, Args: []string{}})
+	})
+}
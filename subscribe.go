@@ -0,0 +1,125 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Subscribe and Latest functions and their helpers
+*/
+package configurature
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// subscription holds the latest config of type T and the channels subscribed
+// to its reloads
+type subscription[T any] struct {
+	latest atomic.Pointer[latestConfig[T]]
+
+	// Protects subscribers
+	mu          sync.Mutex
+	subscribers []chan *T
+}
+
+// latestConfig is the latest config of type T and its snapshot, which is nil
+// unless it was loaded with Options.Snapshots set
+type latestConfig[T any] struct {
+	config *T
+	snap   *snapshot
+}
+
+var (
+	// *subscription[T] by config type
+	subscriptions = make(map[reflect.Type]any)
+
+	// Protects subscriptions
+	subscriptionsMu sync.Mutex
+)
+
+// subscriptionFor returns the subscription for config type T
+func subscriptionFor[T any]() *subscription[T] {
+	subscriptionsMu.Lock()
+	defer subscriptionsMu.Unlock()
+
+	t := reflect.TypeFor[T]()
+	if s, ok := subscriptions[t]; ok {
+		return s.(*subscription[T])
+	}
+	s := &subscription[T]{}
+	subscriptions[t] = s
+	return s
+}
+
+// Latest returns the most recently loaded configuration of type T, including
+// configurations reloaded because a watched Source changed, or nil if none has
+// been loaded. The returned config must not be modified unless it was loaded
+// with Options.Snapshots set, in which case it is a copy.
+func Latest[T any]() *T {
+	l := subscriptionFor[T]().latest.Load()
+	if l == nil {
+		return nil
+	}
+	if l.snap != nil {
+		l.snap.check()
+	}
+	return snapshotOf(l.config, l.snap)
+}
+
+// Subscribe returns a channel on which configurations of type T are sent
+// whenever they are reloaded because a watched Source changed. Each config is
+// a new snapshot that must not be modified unless it was loaded with
+// Options.Snapshots set, in which case each channel receives its own copy. If
+// a receiver falls behind, only the newest config is kept.
+func Subscribe[T any]() <-chan *T {
+	s := subscriptionFor[T]()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := make(chan *T, 1)
+	s.subscribers = append(s.subscribers, ch)
+	return ch
+}
+
+// setLatest sets the config returned by Latest[T](). Copies of it are
+// returned if snapshots is true.
+func setLatest[T any](config *T, snapshots bool) *latestConfig[T] {
+	l := &latestConfig[T]{config: config}
+	if snapshots {
+		l.snap = newSnapshot(config)
+	}
+	subscriptionFor[T]().latest.Store(l)
+	return l
+}
+
+// publish sets the config returned by Latest[T]() and sends it to
+// subscribers. A copy of config is stored if snapshots is true.
+func publish[T any](config *T, snapshots bool) {
+	if snapshots {
+		config = Clone(config)
+	}
+	l := setLatest(config, snapshots)
+	s := subscriptionFor[T]()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ch := range s.subscribers {
+		// Replace an unreceived config with the newer one
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- snapshotOf(l.config, l.snap)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestLatest(t *testing.T) {
+	t.Cleanup(co.ResetForTest)
+	assert := assert.New(t)
+
+	assert.Nil(co.Latest[WatchConfig]())
+	c := co.Configure[WatchConfig](&co.Options{Args: []string{"--host", "h"}})
+	assert.Same(c, co.Latest[WatchConfig]())
+}
+
+func TestSubscribe(t *testing.T) {
+	t.Cleanup(co.ResetForTest)
+	assert := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sub := co.Subscribe[WatchConfig]()
+	s := newWatchSource(map[string]any{"host": "h1"})
+	c := co.ConfigureContext[WatchConfig](ctx, &co.Options{
+		Args:         []string{},
+		NoRecover:    true,
+		Sources:      []co.Source{s},
+		WatchSources: true,
+	})
+	assert.Same(c, co.Latest[WatchConfig]())
+
+	s.updates <- map[string]any{"host": "h2"}
+	select {
+	case nc := <-sub:
+		assert.Equal("h2", nc.Host)
+		assert.Equal("h1", c.Host)
+		assert.Same(nc, co.Latest[WatchConfig]())
+	case <-time.After(5 * time.Second):
+		t.Fatal("configuration was not published")
+	}
+
+	// Only the newest config is kept for slow receivers
+	s.updates <- map[string]any{"host": "h3"}
+	s.updates <- map[string]any{"host": "h4"}
+	assert.Eventually(func() bool {
+		return co.Latest[WatchConfig]().Host == "h4"
+	}, 5*time.Second, time.Millisecond)
+	assert.Equal("h4", (<-sub).Host)
+	assert.Empty(sub)
+}
@@ -0,0 +1,90 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the renaming of struct tags for Options.TagNames
+*/
+package configurature
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// withTagNames returns field with its tags renamed by tagNames, which maps
+// the names of configurature's tags to the names used instead. Tags with the
+// names that were replaced are dropped so that they can be used by other
+// libraries.
+func withTagNames(field reflect.StructField, tagNames map[string]string) reflect.StructField {
+	if len(tagNames) == 0 {
+		return field
+	}
+	renamed := make(map[string]string, len(tagNames))
+	for name, newName := range tagNames {
+		renamed[newName] = name
+	}
+
+	tags := []string{}
+	for _, kv := range parseStructTag(field.Tag) {
+		key := kv[0]
+		if name, ok := renamed[key]; ok {
+			key = name
+		} else if _, ok := tagNames[key]; ok {
+			continue
+		}
+		tags = append(tags, key+":"+strconv.Quote(kv[1]))
+	}
+	field.Tag = reflect.StructTag(strings.Join(tags, " "))
+	return field
+}
+
+// parseStructTag returns the key and value pairs of tag in order. Like
+// reflect.StructTag.Lookup, it stops at the first malformed pair.
+func parseStructTag(tag reflect.StructTag) [][2]string {
+	pairs := [][2]string{}
+	for tag != "" {
+		tag = reflect.StructTag(strings.TrimLeft(string(tag), " "))
+
+		// The key is up to the colon
+		i := 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		key := string(tag[:i])
+		tag = tag[i+1:]
+
+		// The value is the quoted string after it
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		value, err := strconv.Unquote(string(tag[:i+1]))
+		if err != nil {
+			break
+		}
+		tag = tag[i+1:]
+		pairs = append(pairs, [2]string{key, value})
+	}
+	return pairs
+}
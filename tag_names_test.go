@@ -0,0 +1,61 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestTagNames(t *testing.T) {
+	type Sub struct {
+		Port int `def:"80" desc:"port"`
+	}
+	type Conf struct {
+		Host  string `def:"localhost" desc:"server host" help:"used by another library"`
+		Level string `def:"info" enum:"debug,info"`
+		Inner Sub    `nm:"server"`
+		Skip  string `skip:""`
+	}
+	assert := assert.New(t)
+	opts := func(args ...string) *co.Options {
+		return &co.Options{
+			NoRecover: true,
+			Args:      args,
+			TagNames:  map[string]string{"help": "desc", "default": "def", "name": "nm", "ignore": "skip"},
+		}
+	}
+
+	c := co.Configure[Conf](opts("--server_port", "8080"))
+	assert.Equal("localhost", c.Host)
+	assert.Equal("info", c.Level)
+	assert.Equal(8080, c.Inner.Port)
+
+	fields := co.Fields[Conf](opts())
+	assert.Len(fields, 3)
+	assert.Equal("server host", fields[0].Description)
+	assert.Equal("localhost", fields[0].Default)
+	assert.Equal("server_port", fields[2].Name)
+	assert.Equal("port", fields[2].Description)
+
+	// Without TagNames, the tags are read as usual
+	fields = co.Fields[Conf](nil)
+	assert.Len(fields, 4)
+	assert.Equal("used by another library", fields[0].Description)
+	assert.False(fields[0].HasDefault)
+}
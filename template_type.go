@@ -0,0 +1,70 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Value interface implementation for the GoTemplate type
+which is used to specify text/template content on a configurature struct
+*/
+package configurature
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// Name of the templates parsed by GoTemplate
+const goTemplateName = "config"
+
+// Type representing text/template content, e.g. "Hello {{.Name}}", which is
+// parsed when it is set so that syntax errors are reported with their line.
+// Only the built-in template functions may be used.
+type GoTemplate struct {
+	value string
+	tmpl  *template.Template
+}
+
+func (g *GoTemplate) String() string {
+	return g.value
+}
+
+func (g *GoTemplate) Set(v string) error {
+	t, err := template.New(goTemplateName).Parse(v)
+	if err != nil {
+		// Parse errors are "template: <name>:<line>: <message>"
+		msg := strings.TrimPrefix(err.Error(), "template: "+goTemplateName+":")
+		return fmt.Errorf("invalid template at line %s", msg)
+	}
+	*g = GoTemplate{value: v, tmpl: t}
+	return nil
+}
+
+func (g *GoTemplate) Type() string {
+	return "template"
+}
+
+// Template returns the parsed template, or nil if none was set
+func (g GoTemplate) Template() *template.Template {
+	return g.tmpl
+}
+
+// Execute applies the template to data and writes the output to w. Nothing
+// is written if no template was set.
+func (g GoTemplate) Execute(w io.Writer, data any) error {
+	if g.tmpl == nil {
+		return nil
+	}
+	return g.tmpl.Execute(w, data)
+}
@@ -0,0 +1,57 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestGoTemplate(t *testing.T) {
+	type TmplConf struct {
+		Greeting co.GoTemplate `default:"Hello {{.Name}}"`
+		Footer   co.GoTemplate
+	}
+	assert := assert.New(t)
+
+	c := co.Configure[TmplConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+	})
+
+	out := &strings.Builder{}
+	assert.Nil(c.Greeting.Execute(out, map[string]string{"Name": "world"}))
+	assert.Equal("Hello world", out.String())
+	assert.Equal("Hello {{.Name}}", c.Greeting.String())
+	assert.NotNil(c.Greeting.Template())
+
+	out.Reset()
+	assert.Nil(c.Footer.Execute(out, nil))
+	assert.Equal("", out.String())
+	assert.Nil(c.Footer.Template())
+}
+
+func TestGoTemplate_Invalid(t *testing.T) {
+	assert := assert.New(t)
+	g := new(co.GoTemplate)
+
+	assert.EqualError(g.Set("Hello\n{{.Name}"), `invalid template at line 2: bad character U+007D '}'`)
+	assert.EqualError(g.Set("{{upper .Name}}"), `invalid template at line 1: function "upper" not defined`)
+	assert.EqualError(g.Set("{{if .A}}"), `invalid template at line 1: unexpected EOF`)
+}
@@ -16,19 +16,30 @@ package configurature
 
 import (
 	"fmt"
+	"io"
 	"reflect"
 	"strings"
 
-	"github.com/iancoleman/strcase"
 	"github.com/spf13/pflag"
-	"gopkg.in/yaml.v3"
 )
 
 // Internal flags that should not be printed
 var internalFlags = map[string]bool{
 	"help":                true,
+	"help_json":           true,
+	"print_changed":       true,
 	"print_env_template":  true,
 	"print_yaml_template": true,
+	"init":                true,
+	"validate_config":     true,
+	"explain":             true,
+	"completion":          true,
+}
+
+// redacter is implemented by types that hide sensitive information, such as
+// credentials, when printed in templates
+type redacter interface {
+	Redacted() string
 }
 
 // printEnvTemplate prints the usage information for environment variables
@@ -37,14 +48,63 @@ var internalFlags = map[string]bool{
 // Parameters:
 // - fs: the flag set containing the flag values
 func (c *configurer) printEnvTemplate(fs *pflag.FlagSet) {
-	fmt.Printf("# Generated with\n# %s\n\n", c.opts.Args)
+	if !c.opts.EnvTemplateBare {
+		fmt.Fprintf(c.opts.output(), "# Generated with\n# %s\n\n", c.opts.Args)
+	}
+	export := ""
+	if c.opts.EnvTemplateExport {
+		export = "export "
+	}
 	fs.VisitAll(func(f *pflag.Flag) {
 		if _, ok := internalFlags[f.Name]; ok || f.Hidden {
 			return
 		}
-		fmt.Printf("# %s\n", f.Usage)
-		fmt.Printf("%s%s", c.opts.EnvPrefix, strcase.ToScreamingSnake(f.Name))
-		fmt.Printf("=\"%s\"\n\n", strings.Replace(f.Value.String(), "\"", "\\\"", -1))
+		if !c.opts.EnvTemplateBare {
+			fmt.Fprintf(c.opts.output(), "# %s\n", f.Usage)
+		}
+		fmt.Fprintf(c.opts.output(), "%s%s", export, c.opts.envVarName(f.Name, f.Annotations[annotationAncestors]))
+		val := f.Value.String()
+		if r, ok := f.Value.(redacter); ok {
+			val = r.Redacted()
+		} else if isSecret(f) && val != "" {
+			val = redactedValue
+		}
+		if c.opts.EnvTemplateExport {
+			// Single quote values so the template is safe to source
+			fmt.Fprintf(c.opts.output(), "=%s\n", shellQuote(val))
+		} else {
+			fmt.Fprintf(c.opts.output(), "=\"%s\"\n", strings.Replace(val, "\"", "\\\"", -1))
+		}
+		if !c.opts.EnvTemplateBare {
+			fmt.Fprintln(c.opts.output())
+		}
+	})
+}
+
+// printChanged prints the options whose values differ from their defaults
+// along with the source of each value
+//
+// Parameters:
+// - fs: the flag set containing the flag values
+func (c *configurer) printChanged(fs *pflag.FlagSet) {
+	fs.VisitAll(func(f *pflag.Flag) {
+		if _, ok := internalFlags[f.Name]; ok {
+			return
+		}
+		val := f.Value.String()
+		if isSecret(f) {
+			// The default value of secret flags is hidden so compare sources
+			if _, ok := c.sources[f.Name]; !ok {
+				return
+			}
+			val = redactedValue
+		} else if val == f.DefValue {
+			return
+		}
+		if r, ok := f.Value.(redacter); ok {
+			val = r.Redacted()
+		}
+		fmt.Fprintf(c.opts.output(), "%s=%s (%s)\n", f.Name, val, c.sources[f.Name])
 	})
 }
 
@@ -54,8 +114,15 @@ func (c *configurer) printEnvTemplate(fs *pflag.FlagSet) {
 // Parameters:
 // - fs: the flag set containing the flag values
 func (c *configurer) printYamlTemplate(fs *pflag.FlagSet) {
+	c.writeYamlTemplate(c.opts.output(), fs, false)
+}
+
+// writeYamlTemplate writes a YAML config file with the current values of the
+// config struct's fields to w. Secret fields are left out if omitSecrets is
+// true.
+func (c *configurer) writeYamlTemplate(w io.Writer, fs *pflag.FlagSet, omitSecrets bool) {
 
-	fmt.Printf("# Generated with\n# %s\n\n", c.opts.Args)
+	fmt.Fprintf(w, "# Generated with\n# %s\n\n", c.opts.Args)
 
 	ancestorsSeen := map[string]bool{}
 	c.visitFields(c.config, func(f reflect.StructField, tags *reflect.StructTag, v reflect.Value, ancestors []string) (stop bool) {
@@ -66,7 +133,7 @@ func (c *configurer) printYamlTemplate(fs *pflag.FlagSet) {
 		fName := fieldNameToConfigName(f.Name, tags, ancestors)
 		fl := fs.Lookup(fName)
 
-		if _, ok := internalFlags[fl.Name]; ok || fl.Hidden {
+		if _, ok := internalFlags[fl.Name]; ok || fl.Hidden || (omitSecrets && isSecret(fl)) {
 			return
 		}
 
@@ -76,21 +143,28 @@ func (c *configurer) printYamlTemplate(fs *pflag.FlagSet) {
 			parent := ancestors[len(ancestors)-1]
 			if ok := ancestorsSeen[parent]; !ok {
 				ancestorsSeen[parent] = true
-				fmt.Printf("%s%s:\n\n", strings.Repeat("  ", len(ancestors)-1), parent)
+				fmt.Fprintf(w, "%s%s:\n\n", strings.Repeat("  ", len(ancestors)-1), c.fileKeyFromConfigName(parent))
 			}
 		}
 
-		ymlVal := strings.Builder{}
-		encoder := yaml.NewEncoder(&ymlVal)
-		encoder.Encode(map[string]any{
-			stripAncestors(fName, ancestors): v.Elem().Interface(),
+		val := v.Elem().Interface()
+		if r, ok := val.(redacter); ok {
+			val = r.Redacted()
+		} else if isSecret(fl) && fl.Value.String() != "" {
+			val = redactedValue
+		}
+
+		ymlVal, err := encodeYAML(map[string]any{
+			c.fileKeyFromConfigName(stripAncestors(fName, ancestors)): val,
 		})
-		encoder.Close()
+		if err != nil {
+			panic(fmt.Sprintf("error printing YAML template: %v", err))
+		}
 
-		fmt.Printf("%s# %s\n", indent, fl.Usage)
+		fmt.Fprintf(w, "%s# %s\n", indent, fl.Usage)
 		// Indent yaml string to current level
-		ymlValStr := indent + strings.Replace(ymlVal.String(), "\n", "\n"+indent, strings.Count(ymlVal.String(), "\n")-1)
-		fmt.Println(ymlValStr)
+		ymlValStr := indent + strings.Replace(ymlVal, "\n", "\n"+indent, strings.Count(ymlVal, "\n")-1)
+		fmt.Fprintln(w, ymlValStr)
 
 		return stop
 	}, []string{})
@@ -90,70 +90,26 @@ FOO_STRS="[1,2,3]"
 
 }
 
-func TestPrintYamlTemplate(t *testing.T) {
+func TestPrintEnvTemplate_ExportBare(t *testing.T) {
+	type EnvConf struct {
+		Str  string `help:"a string" default:"yes\"no"`
+		Int1 int    `help:"an int" default:"1"`
+		Cost string `help:"a cost" default:"$HOME costs \\$5 it's \x60true\x60"`
+	}
+
 	if os.Getenv("TEST_PASSTHROUGH") == "1" {
-		co.Configure[YamlConf](&co.Options{
-			Args:              []string{"--print_yaml_template", "--st_ptr", "some-string"},
-			NoRecover:         true,
-			ShowInternalFlags: true,
+		co.Configure[EnvConf](&co.Options{
+			Args:              []string{"--print_env_template"},
+			EnvPrefix:         "FOO_",
+			EnvTemplateExport: true,
+			EnvTemplateBare:   true,
 		})
 		os.Exit(0)
 	}
 
-	assert := assert.New(t)
 	stdout, stderr := runExternal(t)
-
-	assert.Equal("", stderr)
-	assert.Equal(`# Generated with
-# [--print_yaml_template --st_ptr some-string]
-
-# a string
-str: yes"no
-
-# a string slice
-strs:
-    - "1"
-    - "2"
-    - "3"
-
-# a string pointer
-st_ptr: some-string
-
-# an int
-int_1: 1
-
-# an int
-int_2: 0
-
-sub:
-
-  # IP address on which to listen
-  ip: 127.0.0.1
-
-  lower:
-
-    # Names and ages map
-    ages:
-        a: 1
-        b: 2
-        c: 3
-
-# IP addresses
-ips:
-    - 127.0.0.1
-
-`, stdout)
-
-	tmpFl, _ := os.CreateTemp("", "test-*.yaml")
-	defer os.Remove(tmpFl.Name())
-	tmpFl.Write([]byte(stdout))
-	tmpFl.Close()
-
-	conf := co.Configure[YamlConf](&co.Options{
-		Args:      []string{"--conf", tmpFl.Name()},
-		NoRecover: true,
-	})
-
-	assert.Equal("some-string", *conf.StPtr)
-	assert.Equal([]net.IP{net.ParseIP("127.0.0.1")}, *conf.Sub2.IPs)
+	assert.Equal(t, "", stderr)
+	assert.Equal(t, "export FOO_COST='$HOME costs \\$5 it'\\''s `true`'\n"+
+		"export FOO_INT_1='1'\n"+
+		"export FOO_STR='yes\"no'\n", stdout)
 }
@@ -0,0 +1,158 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Value interface implementation for the generic
+Threshold type which is used to specify warning and critical levels, such as
+alerting thresholds, on a configurature struct
+*/
+package configurature
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Severity of a value compared to a Threshold
+type Severity int
+
+const (
+	SeverityOK Severity = iota
+	SeverityWarn
+	SeverityCrit
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarn:
+		return "warn"
+	case SeverityCrit:
+		return "crit"
+	default:
+		return "ok"
+	}
+}
+
+// Type representing warning and critical thresholds, e.g. "warn=80,crit=95".
+// If crit is lower than warn, values at or below the thresholds are
+// considered breaches, e.g. "warn=20,crit=10" for free disk space.
+// Threshold[int], Threshold[float64] and Threshold[time.Duration] are
+// registered by default. Other instantiations may be added with AddType.
+type Threshold[T cmp.Ordered] struct {
+	Warn T
+	Crit T
+}
+
+func (t *Threshold[T]) String() string {
+	return fmt.Sprintf("warn=%v,crit=%v", t.Warn, t.Crit)
+}
+
+func (t *Threshold[T]) Set(v string) error {
+	var th Threshold[T]
+	seen := map[string]bool{}
+	for _, part := range strings.Split(v, ",") {
+		key, val, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return fmt.Errorf("invalid threshold \"%s\": %q is not key=value", v, part)
+		}
+		var dst *T
+		switch key {
+		case "warn":
+			dst = &th.Warn
+		case "crit":
+			dst = &th.Crit
+		default:
+			return fmt.Errorf("invalid threshold \"%s\": unknown key %q", v, key)
+		}
+		parsed, err := parseOrdered[T](strings.TrimSpace(val))
+		if err != nil {
+			return fmt.Errorf("invalid threshold \"%s\": invalid %s value %q", v, key, val)
+		}
+		*dst = parsed
+		seen[key] = true
+	}
+	if !seen["warn"] || !seen["crit"] {
+		return fmt.Errorf("invalid threshold \"%s\": warn and crit are required", v)
+	}
+	*t = th
+	return nil
+}
+
+func (t *Threshold[T]) Type() string {
+	return "threshold"
+}
+
+// Level returns the severity of value
+func (t Threshold[T]) Level(value T) Severity {
+	switch {
+	case t.breached(value, t.Crit):
+		return SeverityCrit
+	case t.breached(value, t.Warn):
+		return SeverityWarn
+	default:
+		return SeverityOK
+	}
+}
+
+// Warning returns whether value has reached the warn threshold, including
+// values that have also reached the crit threshold
+func (t Threshold[T]) Warning(value T) bool {
+	return t.Level(value) >= SeverityWarn
+}
+
+// Critical returns whether value has reached the crit threshold
+func (t Threshold[T]) Critical(value T) bool {
+	return t.Level(value) == SeverityCrit
+}
+
+// breached returns whether value has reached limit in the direction of the
+// thresholds
+func (t Threshold[T]) breached(value, limit T) bool {
+	if t.Crit < t.Warn {
+		return value <= limit
+	}
+	return value >= limit
+}
+
+// parseOrdered parses a string into an ordered type
+func parseOrdered[T cmp.Ordered](s string) (T, error) {
+	var v T
+	rv := reflect.ValueOf(&v).Elem()
+	if rv.Type() == reflect.TypeFor[time.Duration]() {
+		d, err := time.ParseDuration(s)
+		rv.SetInt(int64(d))
+		return v, err
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, rv.Type().Bits())
+		rv.SetInt(i)
+		return v, err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(s, 10, rv.Type().Bits())
+		rv.SetUint(u)
+		return v, err
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, rv.Type().Bits())
+		rv.SetFloat(f)
+		return v, err
+	default:
+		rv.SetString(s)
+		return v, nil
+	}
+}
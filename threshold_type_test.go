@@ -0,0 +1,68 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestThreshold(t *testing.T) {
+	type AlertConf struct {
+		CPU      co.Threshold[float64]       `default:"warn=80,crit=95"`
+		DiskFree co.Threshold[int]           `default:"warn=20,crit=10"`
+		Latency  co.Threshold[time.Duration] `default:"warn=250ms,crit=1s"`
+		Queue    co.Threshold[uint16]
+	}
+	co.AddType[co.Threshold[uint16]]()
+	assert := assert.New(t)
+
+	c := co.Configure[AlertConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--cpu", "warn=70.5, crit=90"},
+	})
+
+	assert.Equal(co.Threshold[float64]{Warn: 70.5, Crit: 90}, c.CPU)
+	assert.Equal(co.SeverityOK, c.CPU.Level(50))
+	assert.Equal(co.SeverityWarn, c.CPU.Level(70.5))
+	assert.Equal(co.SeverityCrit, c.CPU.Level(99))
+	assert.True(c.CPU.Warning(99))
+	assert.False(c.CPU.Critical(89.9))
+
+	assert.Equal(co.SeverityOK, c.DiskFree.Level(50))
+	assert.Equal(co.SeverityWarn, c.DiskFree.Level(15))
+	assert.Equal(co.SeverityCrit, c.DiskFree.Level(10))
+	assert.Equal("crit", c.DiskFree.Level(0).String())
+
+	assert.Equal(co.Threshold[time.Duration]{Warn: 250 * time.Millisecond, Crit: time.Second}, c.Latency)
+	assert.True(c.Latency.Warning(300 * time.Millisecond))
+	assert.Equal("warn=250ms,crit=1s", c.Latency.String())
+
+	assert.Equal(co.Threshold[uint16]{}, c.Queue)
+}
+
+func TestThreshold_Invalid(t *testing.T) {
+	assert := assert.New(t)
+	th := new(co.Threshold[int])
+
+	assert.EqualError(th.Set("warn=80"), `invalid threshold "warn=80": warn and crit are required`)
+	assert.EqualError(th.Set("warn=80,high=90"), `invalid threshold "warn=80,high=90": unknown key "high"`)
+	assert.EqualError(th.Set("warn=80,crit"), `invalid threshold "warn=80,crit": "crit" is not key=value`)
+	assert.EqualError(th.Set("warn=8.5,crit=9"), `invalid threshold "warn=8.5,crit=9": invalid warn value "8.5"`)
+}
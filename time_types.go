@@ -0,0 +1,128 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the Value interface implementations used for time.Time and
+[]time.Time struct fields, which pflag does not support
+*/
+package configurature
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Layouts accepted for time.Time values
+var timeLayouts = []string{time.RFC3339Nano, time.DateOnly}
+
+// parseTime parses v using the first matching layout in timeLayouts
+func parseTime(v string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time \"%s\": must be RFC 3339 or YYYY-MM-DD", v)
+}
+
+// formatTime formats t so that parseTime can parse it
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// timeValue is the Value used for time.Time struct fields
+type timeValue struct {
+	value time.Time
+}
+
+func (t *timeValue) String() string {
+	return formatTime(t.value)
+}
+
+func (t *timeValue) Set(v string) error {
+	tm, err := parseTime(v)
+	if err != nil {
+		return err
+	}
+	t.value = tm
+	return nil
+}
+
+func (t *timeValue) Type() string {
+	return "time"
+}
+
+func (t *timeValue) Interface() any {
+	return t.value
+}
+
+// timeSliceValue is the Value used for []time.Time struct fields. Like other
+// slices, the first Set() replaces the default and later calls append.
+type timeSliceValue struct {
+	values  []time.Time
+	changed bool
+}
+
+func (t *timeSliceValue) String() string {
+	if t.values == nil {
+		return ""
+	}
+	out := make([]string, len(t.values))
+	for idx, v := range t.values {
+		out[idx] = formatTime(v)
+	}
+	buf := bytes.NewBuffer(nil)
+	w := csv.NewWriter(buf)
+	w.Write(out)
+	w.Flush()
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+func (t *timeSliceValue) Set(v string) error {
+	vals, err := csv.NewReader(strings.NewReader(v)).Read()
+	if err != nil {
+		return err
+	}
+	newSlice := make([]time.Time, len(vals))
+	for idx, val := range vals {
+		if newSlice[idx], err = parseTime(val); err != nil {
+			return err
+		}
+	}
+	if t.changed {
+		newSlice = append(t.values, newSlice...)
+	}
+	t.values = newSlice
+	t.changed = true
+	return nil
+}
+
+func (t *timeSliceValue) Type() string {
+	return "[]time"
+}
+
+// markDefault records that the current values are the default values
+func (t *timeSliceValue) markDefault() {
+	t.changed = false
+}
+
+func (t *timeSliceValue) Interface() any {
+	return t.values
+}
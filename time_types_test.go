@@ -0,0 +1,66 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type TimeConf struct {
+	Conf     co.ConfigFile
+	Start    time.Time       `default:"2024-01-01"`
+	End      *time.Time      `help:"end of the window"`
+	Retries  []time.Duration `default:"1s,5s"`
+	Windows  []time.Time     `default:"2024-06-01T02:00:00Z"`
+	Holidays []time.Time     `help:"holidays"`
+}
+
+func TestTimes(t *testing.T) {
+	assert := assert.New(t)
+
+	c := co.Configure[TimeConf](&co.Options{
+		NoRecover: true,
+		Args: []string{"--end", "2024-01-31T23:59:59.5-05:00",
+			"--holidays", "2024-12-25", "--holidays", "2024-12-26,2025-01-01"},
+	})
+
+	assert.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), c.Start)
+	assert.True(time.Date(2024, 2, 1, 4, 59, 59, 5e8, time.UTC).Equal(*c.End))
+	assert.Equal([]time.Duration{time.Second, 5 * time.Second}, c.Retries)
+	assert.Equal([]time.Time{time.Date(2024, 6, 1, 2, 0, 0, 0, time.UTC)}, c.Windows)
+	assert.Equal([]time.Time{
+		time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 12, 26, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, c.Holidays)
+}
+
+func TestTimes_Invalid(t *testing.T) {
+	type Conf struct {
+		Windows []time.Time `default:"2024-01-01,tomorrow"`
+	}
+
+	assert.PanicsWithValue(t, `Error setting default value for field windows: invalid time "tomorrow": must be RFC 3339 or YYYY-MM-DD`, func() {
+		co.Configure[Conf](&co.Options{
+			NoRecover: true,
+			Args:      []string{},
+		})
+	})
+}
@@ -0,0 +1,46 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !configurature_core
+
+package configurature_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+func TestTimes_ConfigFile(t *testing.T) {
+	assert := assert.New(t)
+
+	confFile := t.TempDir() + "/conf.yaml"
+	os.WriteFile(confFile, []byte("start: 2023-03-04T05:06:07Z\n"+
+		"retries: [100ms, 2m]\nwindows:\n  - 2024-07-01\n  - 2024-08-01T03:00:00Z\n"), 0644)
+
+	c := co.Configure[TimeConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--conf", confFile},
+	})
+	assert.Equal(time.Date(2023, 3, 4, 5, 6, 7, 0, time.UTC), c.Start)
+	assert.Equal([]time.Duration{100 * time.Millisecond, 2 * time.Minute}, c.Retries)
+	assert.Equal([]time.Time{
+		time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 8, 1, 3, 0, 0, 0, time.UTC),
+	}, c.Windows)
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the TLSConfig sub-config and its helpers
+*/
+package configurature
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// TLS protocol version. Set using "1.0", "1.1", "1.2" or "1.3"
+type TLSVersion uint16
+
+// TLS client certificate policy. Set using "none", "request", "require",
+// "verify_if_given" or "require_and_verify"
+type TLSClientAuth tls.ClientAuthType
+
+// TLSConfig is a reusable sub-config for TLS settings. Include it in a config
+// struct and use its Config() method to get a *tls.Config. E.g.
+//
+//	type Config struct {
+//		TLS co.TLSConfig
+//	}
+type TLSConfig struct {
+	CertFile   ExistingFile  `help:"TLS certificate file"`
+	KeyFile    ExistingFile  `help:"TLS private key file"`
+	CAFile     ExistingFile  `help:"TLS certificate authority file used to verify peers"`
+	ServerName string        `help:"Server name used to verify the peer's certificate"`
+	MinVersion TLSVersion    `help:"Minimum TLS version" default:"1.2"`
+	ClientAuth TLSClientAuth `help:"TLS client certificate policy" default:"none"`
+}
+
+// Config returns a *tls.Config based on the TLSConfig settings. It returns an
+// error if only one of CertFile and KeyFile is set or if any of the files can
+// not be loaded.
+func (t *TLSConfig) Config() (*tls.Config, error) {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return nil, errors.New("TLS cert file and key file must be specified together")
+	}
+
+	config := &tls.Config{
+		ServerName: t.ServerName,
+		MinVersion: uint16(t.MinVersion),
+		ClientAuth: tls.ClientAuthType(t.ClientAuth),
+	}
+
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(string(t.CertFile), string(t.KeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("error loading TLS key pair: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if t.CAFile != "" {
+		ca, err := os.ReadFile(string(t.CAFile))
+		if err != nil {
+			return nil, fmt.Errorf("error reading TLS CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in TLS CA file %s", t.CAFile)
+		}
+		// Used by clients to verify servers and by servers to verify clients
+		config.RootCAs = pool
+		config.ClientCAs = pool
+	}
+
+	return config, nil
+}
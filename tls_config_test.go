@@ -0,0 +1,107 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"os"
+	fp "path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type TLSConf struct {
+	TLS co.TLSConfig
+}
+
+// writeTestCert writes a self-signed certificate and its key to dir
+func writeTestCert(t *testing.T, dir string) (string, string) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.Nil(t, err)
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+
+	certFile := fp.Join(dir, "cert.pem")
+	keyFile := fp.Join(dir, "key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	return certFile, keyFile
+}
+
+func TestTLSConfig(t *testing.T) {
+	assert := assert.New(t)
+	certFile, keyFile := writeTestCert(t, t.TempDir())
+
+	c := co.Configure[TLSConf](&co.Options{
+		NoRecover: true,
+		Args: []string{"--tls_cert_file", certFile, "--tls_key_file", keyFile,
+			"--tls_ca_file", certFile, "--tls_min_version", "1.3",
+			"--tls_client_auth", "require_and_verify", "--tls_server_name", "example.com"},
+	})
+
+	config, err := c.TLS.Config()
+	assert.Nil(err)
+	assert.Len(config.Certificates, 1)
+	assert.NotNil(config.RootCAs)
+	assert.NotNil(config.ClientCAs)
+	assert.Equal(uint16(tls.VersionTLS13), config.MinVersion)
+	assert.Equal(tls.RequireAndVerifyClientCert, config.ClientAuth)
+	assert.Equal("example.com", config.ServerName)
+}
+
+func TestTLSConfig_Defaults(t *testing.T) {
+	assert := assert.New(t)
+
+	c := co.Configure[TLSConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+	})
+
+	config, err := c.TLS.Config()
+	assert.Nil(err)
+	assert.Empty(config.Certificates)
+	assert.Nil(config.RootCAs)
+	assert.Equal(uint16(tls.VersionTLS12), config.MinVersion)
+	assert.Equal(tls.NoClientCert, config.ClientAuth)
+}
+
+func TestTLSConfig_CertWithoutKey(t *testing.T) {
+	certFile, _ := writeTestCert(t, t.TempDir())
+
+	c := co.Configure[TLSConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--tls_cert_file", certFile},
+	})
+
+	_, err := c.TLS.Config()
+	assert.EqualError(t, err, "TLS cert file and key file must be specified together")
+}
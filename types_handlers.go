@@ -19,24 +19,59 @@ configuration parsing and handling
 package configurature
 
 import (
+	"crypto/tls"
 	"fmt"
 	"log/slog"
+	"net"
 	"reflect"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/pflag"
 )
 
 var (
 	// pfgFlagMap maps the reflect.Type and the pflag.FlagSet method name to add
-	// the flag to a pflag.FlagSet
+	// the flag to a pflag.FlagSet. It is only written to in init() so reads
+	// need no locking.
 	pfgFlagMap = make(map[reflect.Type]string)
 
 	// Custom types for this package and the function that can add an instance of
 	// the custom type to the FlagSet
 	customFlagMap = make(map[reflect.Type]func(string, string, string, string, *pflag.FlagSet))
+
+	// Parsers selected by the parser tag
+	namedParsers = make(map[string]namedParser)
+
+	// Protects customFlagMap, mapValueTypeKeys and namedParsers so that types can be added
+	// from multiple packages' init() functions and parallel tests
+	typesMu sync.RWMutex
 )
 
+// Types is a registry of custom types that is used by the Configure calls
+// whose Options.Types it is set to, rather than by every Configure call. Add
+// types to it with AddTypeTo, AddMapValueTypeTo, RegisterParserTo and
+// RegisterNamedParserTo. Types added with AddType, AddMapValueType,
+// RegisterParser and RegisterNamedParser are also available unless replaced
+// in the registry.
+type Types struct {
+	mu               sync.RWMutex
+	customFlagMap    map[reflect.Type]func(string, string, string, string, *pflag.FlagSet)
+	mapValueTypeKeys map[string][]string
+	namedParsers     map[string]namedParser
+	fieldsCache      fieldsCache // Config fields of struct types found using this registry
+}
+
+// NewTypes returns an empty custom type registry
+func NewTypes() *Types {
+	return &Types{
+		customFlagMap:    make(map[reflect.Type]func(string, string, string, string, *pflag.FlagSet)),
+		mapValueTypeKeys: make(map[string][]string),
+		namedParsers:     make(map[string]namedParser),
+	}
+}
+
 // Value interface for config types
 type Value interface {
 	Set(string) error // Set the internal value based on the string. If invalid, return error
@@ -59,18 +94,76 @@ func init() {
 		// Use the return type of the method as the map key
 		pfgFlagMap[t.Method(i).Type.Out(0).Elem()] = name
 	}
+	// Some types have more than one method. Don't depend on method order for
+	// which one is used. configurature-gen makes the same choice.
+	pfgFlagMap[reflect.TypeFor[int]()] = "IntP"
+	pfgFlagMap[reflect.TypeFor[[]byte]()] = "BytesHexP"
 
 	// Add Configurature custom types
 	AddMapValueType("",
 		[]string{"debug", "info", "warn", "error"},
 		[]slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError},
 	)
+	AddMapValueType("",
+		[]string{"1.0", "1.1", "1.2", "1.3"},
+		[]TLSVersion{tls.VersionTLS10, tls.VersionTLS11, tls.VersionTLS12, tls.VersionTLS13},
+	)
+	AddMapValueType("",
+		[]string{"none", "request", "require", "verify_if_given", "require_and_verify"},
+		[]TLSClientAuth{
+			TLSClientAuth(tls.NoClientCert),
+			TLSClientAuth(tls.RequestClientCert),
+			TLSClientAuth(tls.RequireAnyClientCert),
+			TLSClientAuth(tls.VerifyClientCertIfGiven),
+			TLSClientAuth(tls.RequireAndVerifyClientCert),
+		},
+	)
 	AddType[ConfigFile]()
-
+	AddType[Port]()
+	AddType[[]Port]()
+	AddType[ListenPort]()
+	AddType[[]ListenPort]()
+	AddType[HostPort]()
+	AddType[[]HostPort]()
+	AddType[ExistingFile]()
+	AddType[[]ExistingFile]()
+	AddType[ExistingDir]()
+	AddType[[]ExistingDir]()
+	AddType[WritableDir]()
+	AddType[DSN]()
+	AddType[Bytes]()
+	AddType[CIDRList]()
+	AddType[EndpointList]()
+	AddType[KeyMaterial]()
+	AddType[Rate]()
+	AddType[Bandwidth]()
+	AddType[Interval]()
+	AddType[GoTemplate]()
+	AddType[Glob]()
+	AddType[[]Glob]()
+	AddType[MIMEType]()
+	AddType[[]MIMEType]()
+	AddType[ExtensionList]()
+	AddType[Decimal]()
+	AddType[Money]()
+	AddType[Threshold[int]]()
+	AddType[Threshold[float64]]()
+	AddType[Threshold[time.Duration]]()
+	AddType[LazySecret]()
+	AddType[ListenSpec]()
+	AddType[[]ListenSpec]()
+	addToCustomFlagMap[tcpAddrValue, net.TCPAddr]()
+	addToCustomFlagMap[udpAddrValue, net.UDPAddr]()
+	addToCustomFlagMap[locationValue, time.Location]()
+	addToCustomFlagMap[timeValue, time.Time]()
+	addToCustomFlagMap[timeSliceValue, []time.Time]()
 }
 
 // GetSupportedTypes returns all supported struct field types
 func getSupportedTypes() []string {
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+
 	supported := make([]string, 0, len(pfgFlagMap)+len(customFlagMap))
 	for t := range pfgFlagMap {
 		supported = append(supported, t.String())
@@ -87,9 +180,23 @@ func getSupportedTypes() []string {
 // Parameters:
 // - structFieldType: The type of struct field
 func AddType[structFieldType any]() {
-	// If the type is a slice, add a custom slice type
-	if reflect.TypeFor[structFieldType]().Kind() == reflect.Slice {
-		addSliceType[structFieldType]()
+	addType[structFieldType](nil)
+}
+
+// AddTypeTo adds a custom type to the types registry rather than to the
+// customFlagMap used by every Configure call
+func AddTypeTo[structFieldType any](types *Types) {
+	addType[structFieldType](types)
+}
+
+// addType adds a custom type to types, or to the customFlagMap if types is
+// nil
+func addType[structFieldType any](types *Types) {
+	// If the type is a slice that is not itself a Value, add a custom slice
+	// type
+	if reflect.TypeFor[structFieldType]().Kind() == reflect.Slice &&
+		!reflect.TypeFor[*structFieldType]().Implements(reflect.TypeFor[Value]()) {
+		addSliceType[structFieldType](types)
 		return
 	}
 
@@ -100,15 +207,20 @@ func AddType[structFieldType any]() {
 		panic(fmt.Sprintf("%T must implement Value", ptrType))
 	}
 
-	addToCustomFlagMap[structFieldType, structFieldType]()
+	registerType(types, reflect.TypeFor[structFieldType](), customFlagFn[structFieldType](), nil)
 
 }
 
 // Add the value to the customFlagMap with a method that will add a flag
 // of that type to the FlagSet
 func addToCustomFlagMap[structFieldType any, valueType any]() {
-	rt := reflect.TypeFor[valueType]()
-	customFlagMap[rt] = func(name string, short string, def string, help string, fs *pflag.FlagSet) {
+	registerType(nil, reflect.TypeFor[valueType](), customFlagFn[structFieldType](), nil)
+}
+
+// customFlagFn returns a function that adds a flag whose Value is a new
+// structFieldType to a FlagSet
+func customFlagFn[structFieldType any]() func(string, string, string, string, *pflag.FlagSet) {
+	return func(name string, short string, def string, help string, fs *pflag.FlagSet) {
 		l := new(structFieldType)
 		if def != "" {
 			// Use Set() to set the default value of the Value
@@ -118,6 +230,9 @@ func addToCustomFlagMap[structFieldType any, valueType any]() {
 			if !r[0].IsNil() {
 				panic(fmt.Sprintf("Error setting default value for field %s: %s", name, r[0]))
 			}
+			if d, ok := any(l).(defaultMarker); ok {
+				d.markDefault()
+			}
 		}
 		// Add the Value to the flagset using VarP
 		reflect.ValueOf(fs).MethodByName("VarP").Call(
@@ -129,19 +244,75 @@ func addToCustomFlagMap[structFieldType any, valueType any]() {
 			},
 		)
 	}
+}
+
+// registerType adds the function that adds a flag of type t to a FlagSet to
+// types, or to the customFlagMap if types is nil. keys are the keys of map
+// value types.
+func registerType(types *Types, t reflect.Type, fn func(string, string, string, string, *pflag.FlagSet), keys []string) {
+	// Cached fields of this type may have been collected as sub-configs
+	defer clearStructFieldsCache()
+
+	if types == nil {
+		typesMu.Lock()
+		defer typesMu.Unlock()
+		customFlagMap[t] = fn
+		if keys != nil {
+			mapValueTypeKeys[t.String()] = keys
+		}
+		return
+	}
+	types.mu.Lock()
+	defer types.mu.Unlock()
+	types.customFlagMap[t] = fn
+	if keys != nil {
+		types.mapValueTypeKeys[t.String()] = keys
+	}
+}
 
+// interfacer is implemented by Values that return the value to set on the
+// struct field
+type interfacer interface {
+	Interface() any
+}
+
+// defaultMarker is implemented by Values that treat the first Set() after
+// their default value differently, such as slices which append values of
+// repeated flags
+type defaultMarker interface {
+	markDefault()
+}
+
+// getCustomFlagFn returns the function from types, or else from the
+// customFlagMap, that adds a flag of type t to a FlagSet
+func getCustomFlagFn(types *Types, t reflect.Type) (func(string, string, string, string, *pflag.FlagSet), bool) {
+	if types != nil {
+		types.mu.RLock()
+		fn, ok := types.customFlagMap[t]
+		types.mu.RUnlock()
+		if ok {
+			return fn, ok
+		}
+	}
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+	fn, ok := customFlagMap[t]
+	return fn, ok
 }
 
 // addToFlagSet adds a flag to the provided FlagSet based on the given type.
 //
 // Parameters:
+// - types: the custom type registry of the configuration, which may be nil
 // - t: the reflect.Type of the flag
+// - fn: the registered function adding a flag of the type, or nil
+// - enumProvided: whether the field has an enum tag
 // - fs: the pointer to the pflag.FlagSet to add the flag to
 // - name: the name of the flag
 // - short: the short name of the flag
 // - def: the default value of the flag
 // - help: the description of the flag
-func addToFlagSet(t reflect.Type, enumProvided bool, fs *pflag.FlagSet, name string, short string, def string, help string) {
+func addToFlagSet(types *Types, t reflect.Type, fn func(string, string, string, string, *pflag.FlagSet), enumProvided bool, fs *pflag.FlagSet, name string, short string, def string, help string) {
 
 	isPtr := t.Elem().Kind() == reflect.Ptr
 	if isPtr {
@@ -149,13 +320,13 @@ func addToFlagSet(t reflect.Type, enumProvided bool, fs *pflag.FlagSet, name str
 	}
 
 	// Check in the customFlagMap
-	if fn, ok := customFlagMap[t.Elem()]; ok {
+	if fn != nil {
 		// It's a Configurature the function in customFlagMap takes a string
 		// for a default value
 
 		// If this is a map value type, add its values to the description
 		if !enumProvided {
-			if vals := getMapValueTypeValues(t.Elem().String()); vals != nil {
+			if vals := getMapValueTypeValues(types, t.Elem().String()); vals != nil {
 				help += " (" + strings.Join(*vals, "|") + ")"
 			}
 		}
@@ -182,7 +353,7 @@ func addToFlagSet(t reflect.Type, enumProvided bool, fs *pflag.FlagSet, name str
 				reflect.ValueOf(name),
 			})
 			setFlagValue(name, def, defFs)
-			setNativeValue(defVal, name, defFs)
+			setNativeValue(types, defVal, name, defFs)
 		}
 
 		// Call the flag method on the actual flagset
@@ -198,8 +369,8 @@ func addToFlagSet(t reflect.Type, enumProvided bool, fs *pflag.FlagSet, name str
 
 // Set the value to the native type which is returned by the getter on the
 // flagset
-func setNativeValue(rv reflect.Value, name string, fs *pflag.FlagSet) {
-	fv := fs.Lookup(name).Value
+func setNativeValue(types *Types, rv reflect.Value, name string, fs *pflag.FlagSet) {
+	fv := unwrapValue(fs.Lookup(name).Value)
 
 	isPtr := rv.Elem().Kind() == reflect.Ptr
 	// Init pointer if nil
@@ -215,12 +386,28 @@ func setNativeValue(rv reflect.Value, name string, fs *pflag.FlagSet) {
 		dest = dest.Elem()
 	}
 
-	// For Custom types
-	if _, ok := customFlagMap[pfType]; ok {
+	// For Custom types and fields with a parser tag
+	_, isCustom := getCustomFlagFn(types, pfType)
+	if _, ok := fv.(interfacer); isCustom || ok {
 		// If the field has an Interface method, call it and set the value
 		if m := reflect.ValueOf(fv).MethodByName("Interface"); m.IsValid() {
 			cv := m.Call(nil)
-			if !cv[0].IsNil() {
+			if cv[0].IsNil() {
+				return
+			}
+			// Pointer fields may be set to the pointer returned by Interface
+			// itself. E.g. *time.Location
+			v := cv[0].Elem()
+			if isPtr && v.Type() == rv.Elem().Type() {
+				rv.Elem().Set(v)
+			} else if v.Kind() == reflect.Ptr && v.Type().Elem() == dest.Type() {
+				// Values of parsers registered for pointer types, e.g.
+				// *regexp.Regexp, are dereferenced for fields of the type
+				// they point to
+				if !v.IsNil() {
+					dest.Set(v.Elem())
+				}
+			} else {
 				dest.Set(cv[0].Elem())
 			}
 			return
@@ -241,12 +428,20 @@ func setNativeValue(rv reflect.Value, name string, fs *pflag.FlagSet) {
 	// as a method on the flagset, use that method name instead.
 	// This is for complex types such as GetIPSlice. This is brittle,
 	// but better than maintaining a static map
+	// The Get methods parse the String() of the flag's Value, so wrapped
+	// Values are read using a FlagSet holding the unwrapped Value
+	getFs := fs
+	if fv != fs.Lookup(name).Value {
+		getFs = pflag.NewFlagSet(name, pflag.ContinueOnError)
+		getFs.AddFlag(&pflag.Flag{Name: name, Value: fv})
+	}
+
 	var m reflect.Value
 	if method, ok := pfgFlagMap[pfType]; !ok {
 		panic("setNativeValue() unsupported type: " + rv.Type().Elem().String())
 	} else {
 		methodName := "Get" + strings.TrimSuffix(method, "P")
-		if m = reflect.ValueOf(fs).MethodByName(methodName); !m.IsValid() {
+		if m = reflect.ValueOf(getFs).MethodByName(methodName); !m.IsValid() {
 			panic("setNativeValue()could not find Get method for type: " + rv.Type().Elem().String())
 		}
 	}
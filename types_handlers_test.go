@@ -106,37 +106,12 @@ func TestCustomType_Error(t *testing.T) {
 	fileName := tmpFile(t, "mod")
 	stdout, stderr := runExternal(t)
 	assert := assert.New(t)
-	assert.Equal(`invalid argument "`+fileName+`" for "--image" flag: file type `+
-		`".mod" not supported`+"\n", stdout)
+	assert.Equal("", stdout)
 	assert.Equal(`invalid argument "`+fileName+`" for "--image" flag: file type `+
 		`".mod" not supported`+"\n", stderr)
 
 }
 
-func TestCustomType_ErrorConfigFile(t *testing.T) {
-	addImageFileTypes()
-	if os.Getenv("TEST_PASSTHROUGH") == "1" {
-		tmp, _ := os.CreateTemp("", "cfgr-test-*.yml")
-		defer os.Remove(tmp.Name())
-		fileName := tmpFile(t, "mod")
-		tmp.Write([]byte("image: " + fileName + "\n"))
-		tmp.Close()
-
-		co.Configure[MyConfig](&co.Options{
-			Args:  []string{"--conf", tmp.Name()},
-			Usage: func(_ *flag.FlagSet) {},
-		})
-		os.Exit(1)
-	}
-
-	stdout, stderr := runExternal(t)
-	assert := assert.New(t)
-	assert.Equal("", stdout)
-	assert.Equal(`error parsing configuration: unable to set value for image: `+
-		`file type ".mod" not supported`+"\n", stderr)
-
-}
-
 func TestCustomSliceType_Flag(t *testing.T) {
 	addImageFileTypes()
 
@@ -160,33 +135,13 @@ func TestCustomSliceType_Flag(t *testing.T) {
 	assert.Equal(expected, conf.Images)
 }
 
-func TestCustomSliceType_Error(t *testing.T) {
+func TestCustomSliceType_Repeated(t *testing.T) {
 	addImageFileTypes()
-	if os.Getenv("TEST_PASSTHROUGH") == "1" {
 
-		co.Configure[MyConfig](&co.Options{
-			Args:  []string{"--images", os.Getenv("TEST_TEMP_FILE_NAME")},
-			Usage: func(_ *flag.FlagSet) {},
-		})
-		os.Exit(0)
-	}
-
-	fileName := tmpFile(t, "mod")
-	stdout, stderr := runExternal(t)
-	assert := assert.New(t)
-	assert.Equal(`invalid argument "`+fileName+`" for "--images" flag: file type `+
-		`".mod" not supported`+"\n", stdout)
-	assert.Equal(`invalid argument "`+fileName+`" for "--images" flag: file type `+
-		`".mod" not supported`+"\n", stderr)
-
-}
-
-func TestCustomSliceType_ConfigFile(t *testing.T) {
-	addImageFileTypes()
 	files := make([]string, 3)
 	expected := make([]ImageFile, 3)
 	for idx := range 3 {
-		tmp, _ := os.CreateTemp("", "cfgr-test-config-file*.png")
+		tmp, _ := os.CreateTemp("", "cfgr-test-*.png")
 		tmp.Close()
 		defer os.Remove(tmp.Name())
 
@@ -194,40 +149,53 @@ func TestCustomSliceType_ConfigFile(t *testing.T) {
 		expected[idx] = ImageFile(tmp.Name())
 	}
 
-	tmp, _ := os.CreateTemp("", "cfgr-test-*.yaml")
-	tmp.Write([]byte("images:\n  - " + strings.Join(files, "\n  - ") + "\n"))
-	tmp.Close()
-	defer os.Remove(tmp.Name())
-
 	conf := co.Configure[MyConfig](&co.Options{
-		Args:  []string{"--conf", tmp.Name()},
+		Args:  []string{"--images", files[0], "--images", files[1] + "," + files[2]},
 		Usage: func(_ *flag.FlagSet) {},
 	})
 
-	assert.Equal(t, expected, conf.Images)
+	assert := assert.New(t)
+	assert.Equal(expected, conf.Images)
+}
+
+func TestCustomSliceType_RepeatedDefault(t *testing.T) {
+	assert := assert.New(t)
+
+	// Repeated flags replace the default and append to each other
+	c := co.Configure[PortConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--ports", "1", "--ports", "2,3"},
+	})
+	assert.Equal([]co.Port{1, 2, 3}, c.Ports)
+
+	// pflag slice types behave the same way
+	type Conf struct {
+		Tags []string `default:"x"`
+	}
+	tc := co.Configure[Conf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--tags", "a", "--tags", "b"},
+	})
+	assert.Equal([]string{"a", "b"}, tc.Tags)
 }
 
-func TestCustomSliceType_ErrorConfigFile(t *testing.T) {
+func TestCustomSliceType_Error(t *testing.T) {
 	addImageFileTypes()
 	if os.Getenv("TEST_PASSTHROUGH") == "1" {
-		tmp, _ := os.CreateTemp("", "cfgr-test-*.yml")
-		defer os.Remove(tmp.Name())
-		fileName := tmpFile(t, "mod")
-		tmp.Write([]byte("image: " + fileName + "\n"))
-		tmp.Close()
 
 		co.Configure[MyConfig](&co.Options{
-			Args:  []string{"--conf", tmp.Name()},
+			Args:  []string{"--images", os.Getenv("TEST_TEMP_FILE_NAME")},
 			Usage: func(_ *flag.FlagSet) {},
 		})
-		panic("Expected exit")
+		os.Exit(0)
 	}
 
+	fileName := tmpFile(t, "mod")
 	stdout, stderr := runExternal(t)
 	assert := assert.New(t)
 	assert.Equal("", stdout)
-	assert.Equal(`error parsing configuration: unable to set value for image: `+
-		`file type ".mod" not supported`+"\n", stderr)
+	assert.Equal(`invalid argument "`+fileName+`" for "--images" flag: file type `+
+		`".mod" not supported`+"\n", stderr)
 
 }
 
@@ -291,9 +259,9 @@ func TestMapValueType_BadValue(t *testing.T) {
 	assert := assert.New(t)
 	stdout, stderr := runExternal(t)
 
+	assert.Equal("", stdout)
 	assert.Equal(`invalid argument "yellow" for "--background" flag: `+
-		`invalid Color: "yellow"`+"\n", stdout)
-	assert.Equal(stdout, stderr)
+		`invalid Color: "yellow"`+"\n", stderr)
 
 }
 
@@ -322,3 +290,117 @@ func TestMapValueType_Usage(t *testing.T) {
 	assert.Equal("", stderr)
 	assert.True(strings.Contains(stdout, `--background Color   background color (red|blue|green) (default red)`), stdout)
 }
+
+func TestAddType_Parallel(t *testing.T) {
+	type Tone string
+	type TConf struct {
+		Tone Tone `default:"low"`
+	}
+
+	done := make(chan bool)
+	for range 10 {
+		go func() {
+			co.AddMapValueType("",
+				[]string{"low", "high"},
+				[]Tone{"l", "h"},
+			)
+			co.AddType[ImageFile]()
+			co.Configure[TConf](&co.Options{NoRecover: true, Args: []string{}})
+			co.Get[TConf]()
+			done <- true
+		}()
+	}
+	for range 10 {
+		<-done
+	}
+}
+
+// Point is a struct type that is a Value rather than a nested config when
+// registered
+type Point struct {
+	X, Y int
+}
+
+func (p *Point) String() string {
+	return fmt.Sprintf("%d,%d", p.X, p.Y)
+}
+
+func (p *Point) Set(v string) error {
+	if _, err := fmt.Sscanf(v, "%d,%d", &p.X, &p.Y); err != nil {
+		return fmt.Errorf("invalid point \"%s\"", v)
+	}
+	return nil
+}
+
+func (p *Point) Type() string {
+	return "point"
+}
+
+func TestTypes(t *testing.T) {
+	type Shade string
+	type SConf struct {
+		Shade  Shade `default:"dark"`
+		Origin Point `default:"1,2"`
+	}
+	assert := assert.New(t)
+
+	types := co.NewTypes()
+	co.AddMapValueTypeTo(types, "", []string{"light", "dark"}, []Shade{"#eee", "#111"})
+	co.AddTypeTo[Point](types)
+
+	conf := co.Configure[SConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--origin", "3,4"},
+		Types:     types,
+	})
+	assert.Equal(Shade("#111"), conf.Shade)
+	assert.Equal(Point{3, 4}, conf.Origin)
+
+	// Types in the registry are not used by other Configure calls
+	assert.PanicsWithValue("addToFlagSet() unsupported type: configurature_test.Shade", func() {
+		co.Configure[SConf](&co.Options{NoRecover: true, Args: []string{}})
+	})
+
+	// Without the registry, Point is a nested config
+	type PConf struct {
+		Origin Point
+	}
+	pconf := co.Configure[PConf](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--origin_x", "5"},
+	})
+	assert.Equal(Point{5, 0}, pconf.Origin)
+}
+
+func TestTypes_Usage(t *testing.T) {
+	type Shade string
+	type SConf struct {
+		Shade Shade `help:"shade" default:"dark"`
+	}
+
+	if os.Getenv("TEST_PASSTHROUGH") == "1" {
+		types := co.NewTypes()
+		co.AddMapValueTypeTo(types, "", []string{"light", "dark"}, []Shade{"#eee", "#111"})
+		co.Configure[SConf](&co.Options{
+			Args:  []string{"-h"},
+			Types: types,
+		})
+		panic("Should have exited")
+	}
+
+	stdout, _ := runExternal(t)
+	assert.Contains(t, stdout, `--shade Shade   shade (light|dark) (default dark)`)
+}
+
+func TestPflagTypes_BytesHex(t *testing.T) {
+	type Config struct {
+		Key   []byte `default:"6869"`
+		Count int    `default:"3"`
+	}
+	c := co.Configure[Config](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+	})
+	assert.Equal(t, []byte("hi"), c.Key)
+	assert.Equal(t, 3, c.Count)
+}
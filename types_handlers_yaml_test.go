@@ -0,0 +1,101 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !configurature_core
+
+package configurature_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	co "github.com/imoore76/configurature"
+	flag "github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomType_ErrorConfigFile(t *testing.T) {
+	addImageFileTypes()
+	if os.Getenv("TEST_PASSTHROUGH") == "1" {
+		tmp, _ := os.CreateTemp("", "cfgr-test-*.yml")
+		defer os.Remove(tmp.Name())
+		fileName := tmpFile(t, "mod")
+		tmp.Write([]byte("image: " + fileName + "\n"))
+		tmp.Close()
+
+		co.Configure[MyConfig](&co.Options{
+			Args:  []string{"--conf", tmp.Name()},
+			Usage: func(_ *flag.FlagSet) {},
+		})
+		os.Exit(1)
+	}
+
+	stdout, stderr := runExternal(t)
+	assert := assert.New(t)
+	assert.Equal("", stdout)
+	assert.Equal(`error parsing configuration: unable to set value for image: `+
+		`file type ".mod" not supported`+"\n", stderr)
+
+}
+
+func TestCustomSliceType_ConfigFile(t *testing.T) {
+	addImageFileTypes()
+	files := make([]string, 3)
+	expected := make([]ImageFile, 3)
+	for idx := range 3 {
+		tmp, _ := os.CreateTemp("", "cfgr-test-config-file*.png")
+		tmp.Close()
+		defer os.Remove(tmp.Name())
+
+		files[idx] = tmp.Name()
+		expected[idx] = ImageFile(tmp.Name())
+	}
+
+	tmp, _ := os.CreateTemp("", "cfgr-test-*.yaml")
+	tmp.Write([]byte("images:\n  - " + strings.Join(files, "\n  - ") + "\n"))
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	conf := co.Configure[MyConfig](&co.Options{
+		Args:  []string{"--conf", tmp.Name()},
+		Usage: func(_ *flag.FlagSet) {},
+	})
+
+	assert.Equal(t, expected, conf.Images)
+}
+
+func TestCustomSliceType_ErrorConfigFile(t *testing.T) {
+	addImageFileTypes()
+	if os.Getenv("TEST_PASSTHROUGH") == "1" {
+		tmp, _ := os.CreateTemp("", "cfgr-test-*.yml")
+		defer os.Remove(tmp.Name())
+		fileName := tmpFile(t, "mod")
+		tmp.Write([]byte("image: " + fileName + "\n"))
+		tmp.Close()
+
+		co.Configure[MyConfig](&co.Options{
+			Args:  []string{"--conf", tmp.Name()},
+			Usage: func(_ *flag.FlagSet) {},
+		})
+		panic("Expected exit")
+	}
+
+	stdout, stderr := runExternal(t)
+	assert := assert.New(t)
+	assert.Equal("", stdout)
+	assert.Equal(`error parsing configuration: unable to set value for image: `+
+		`file type ".mod" not supported`+"\n", stderr)
+
+}
@@ -0,0 +1,138 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the reporting of unknown flags, all at once and with
+suggestions of the flags that may have been meant
+*/
+package configurature
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// unknownFlagsError returns an error listing every unknown flag in args, each
+// with the closest known flag name if there is one, or nil if all of the
+// flags are known. Only internal flags are known if DisableFlags is set.
+func unknownFlagsError(fs *pflag.FlagSet, args []string, opts *Options) error {
+	known := func(fl *pflag.Flag) bool {
+		return fl != nil && (!opts.DisableFlags || internalFlags[fl.Name] || fl.Name == profileFlag)
+	}
+
+	unknown := []string{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		// Everything after "--" is a positional argument
+		if arg == "--" {
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			continue
+		}
+
+		if strings.HasPrefix(arg, "--") {
+			name, _, hasValue := strings.Cut(arg[2:], "=")
+			fl := fs.Lookup(name)
+			if !known(fl) {
+				unknown = append(unknown, "--"+name+suggestFlag(fs, name, known))
+				continue
+			}
+			// The next arg is the flag's value
+			if !hasValue && fl.NoOptDefVal == "" {
+				i++
+			}
+			continue
+		}
+
+		// Shorthand flags may be combined. E.g. -vp 80. Like pflag, ignore
+		// the flags of go test
+		shorts := arg[1:]
+		if strings.HasPrefix(shorts, "test.") {
+			continue
+		}
+		for j := 0; j < len(shorts); j++ {
+			fl := fs.ShorthandLookup(shorts[j : j+1])
+			if !known(fl) {
+				unknown = append(unknown, "-"+shorts[j:j+1])
+				continue
+			}
+			if fl.NoOptDefVal != "" {
+				continue
+			}
+			// The rest of the arg, or else the next arg, is the flag's value
+			if j == len(shorts)-1 {
+				i++
+			}
+			break
+		}
+	}
+
+	switch len(unknown) {
+	case 0:
+		return nil
+	case 1:
+		return errors.New("unknown flag: " + unknown[0])
+	}
+	return errors.New("unknown flags: " + strings.Join(unknown, ", "))
+}
+
+// suggestFlag returns " (did you mean --<flag>?)" for the known flag in fs
+// whose name is closest to name, or "" if none are close
+func suggestFlag(fs *pflag.FlagSet, name string, known func(*pflag.Flag) bool) string {
+	best, bestDist := "", max(1, len(name)/3)+1
+	fs.VisitAll(func(fl *pflag.Flag) {
+		if fl.Hidden || !known(fl) {
+			return
+		}
+		if d := editDistance(name, fl.Name); d < bestDist {
+			best, bestDist = fl.Name, d
+		}
+	})
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean --%s?)", best)
+}
+
+// editDistance returns the number of single character insertions, deletions,
+// substitutions and transpositions of adjacent characters needed to change a
+// into b
+func editDistance(a string, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type UnknownFlagsConf struct {
+	Port     int `short:"p"`
+	Hostname string
+	Verbose  bool `short:"v"`
+}
+
+func TestUnknownFlags(t *testing.T) {
+	assert := assert.New(t)
+	configure := func(o *co.Options) { co.Configure[UnknownFlagsConf](o) }
+
+	code, _, errOut := configureExit(t, configure, co.Options{
+		Args:  []string{"--prot", "80", "--hostname", "-x", "-vq", "--verbsoe", "--zzz=1", "-p", "1", "--", "--after"},
+		Usage: func(*pflag.FlagSet) {},
+	})
+	assert.Equal(2, code)
+	assert.Equal("unknown flags: --prot (did you mean --port?), -q, --verbsoe (did you mean --verbose?), --zzz\n", errOut)
+
+	code, _, errOut = configureExit(t, configure, co.Options{
+		Args:  []string{"--hostnam", "x"},
+		Usage: func(*pflag.FlagSet) {},
+	})
+	assert.Equal(2, code)
+	assert.Equal("unknown flag: --hostnam (did you mean --hostname?)\n", errOut)
+
+	// Field flags are unknown, and not suggested, if DisableFlags is set
+	code, _, errOut = configureExit(t, configure, co.Options{
+		Args:         []string{"--port", "80", "--hlep"},
+		DisableFlags: true,
+		Usage:        func(*pflag.FlagSet) {},
+	})
+	assert.Equal(2, code)
+	assert.Equal("unknown flags: --port, --hlep (did you mean --help?)\n", errOut)
+}
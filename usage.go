@@ -0,0 +1,107 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the default usage output
+*/
+package configurature
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// printUsage prints the usage of the flags in fs to w. Flags with a group tag are
+// listed after the rest under a heading for each group. Types and default
+// values are left out if UsageHideTypes and UsageHideDefaults are set.
+func printUsage(w io.Writer, fs *pflag.FlagSet, opts *Options) {
+	ungrouped := pflag.NewFlagSet(fs.Name(), pflag.ContinueOnError)
+	groups := map[string]*pflag.FlagSet{}
+	usageFlags(fs, opts).VisitAll(func(fl *pflag.Flag) {
+		group := flagGroup(fl)
+		if group == "" || fl.Hidden {
+			ungrouped.AddFlag(usageFlag(fl, opts))
+			return
+		}
+		if groups[group] == nil {
+			groups[group] = pflag.NewFlagSet(group, pflag.ContinueOnError)
+		}
+		groups[group].AddFlag(usageFlag(fl, opts))
+	})
+
+	fmt.Fprintln(w, "Command usage:")
+	fmt.Fprintln(w, ungrouped.FlagUsages())
+	for _, group := range slices.Sorted(maps.Keys(groups)) {
+		fmt.Fprintf(w, "%s:\n", group)
+		fmt.Fprintln(w, groups[group].FlagUsages())
+	}
+}
+
+// usageFlag returns fl, or a copy of it without its type or default value if
+// opts hide them
+func usageFlag(fl *pflag.Flag, opts *Options) *pflag.Flag {
+	if !opts.UsageHideTypes && !opts.UsageHideDefaults {
+		return fl
+	}
+	uf := *fl
+	if opts.UsageHideTypes {
+		// pflag shows a back-quoted name in the usage, empty here, in place
+		// of the type
+		uf.Usage = "``" + strings.ReplaceAll(fl.Usage, "`", "")
+	}
+	if opts.UsageHideDefaults {
+		// pflag leaves out the default of Values it doesn't know if they
+		// are empty
+		uf.Value = usageValue{fl.Value}
+		uf.DefValue = ""
+	}
+	return &uf
+}
+
+// usageValue hides the type and value of a Value from pflag
+type usageValue struct {
+	pflag.Value
+}
+
+func (usageValue) String() string {
+	return ""
+}
+
+// flagGroup returns the group tag of the field of fl
+func flagGroup(fl *pflag.Flag) string {
+	if group := fl.Annotations[annotationGroup]; len(group) > 0 {
+		return group[0]
+	}
+	return ""
+}
+
+// usageFlags returns the flags of fs shown in usage. Field flags are left out
+// if DisableFlags is set.
+func usageFlags(fs *pflag.FlagSet, opts *Options) *pflag.FlagSet {
+	if !opts.DisableFlags {
+		return fs
+	}
+	uf := pflag.NewFlagSet(fs.Name(), pflag.ContinueOnError)
+	fs.VisitAll(func(fl *pflag.Flag) {
+		if internalFlags[fl.Name] || fl.Name == profileFlag {
+			uf.AddFlag(fl)
+		}
+	})
+	return uf
+}
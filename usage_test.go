@@ -0,0 +1,145 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type GroupServer struct {
+	ListenPort int    `default:"80" group:"Networking"`
+	Name       string `help:"server name"`
+}
+
+type GroupConf struct {
+	Server  GroupServer
+	Proxy   string `help:"proxy URL" group:"Networking"`
+	DBHost  string `help:"db host" group:"Database"`
+	Verbose bool
+}
+
+// usage returns the output of --help for T
+func usage[T any](t *testing.T, opts co.Options) string {
+	out := &strings.Builder{}
+	opts.Args = []string{"--help"}
+	opts.Output = out
+	opts.Exit = func(int) {}
+	assert.Panics(t, func() {
+		co.Configure[T](&opts)
+	})
+	return out.String()
+}
+
+func TestUsage_Groups(t *testing.T) {
+	assert.Equal(t, `Command usage:
+  -h, --help                 show help and exit
+      --server_name string   server name
+      --verbose              verbose
+
+Database:
+      --db_host string   db host
+
+Networking:
+      --proxy string             proxy URL
+      --server_listen_port int   server listen port (default 80)
+
+`, usage[GroupConf](t, co.Options{}))
+
+	fields := co.Fields[GroupConf](nil)
+	assert.Equal(t, "Networking", fields[0].Group)
+	assert.Equal(t, "", fields[1].Group)
+}
+
+func TestUsage_Hide(t *testing.T) {
+	type Conf struct {
+		Host    string        `default:"localhost"`
+		Timeout time.Duration `default:"5s" short:"t"`
+		Verbose bool          `default:"true"`
+		Tags    []string      "help:\"the `tag` list\""
+	}
+	assert := assert.New(t)
+
+	assert.Equal(`Command usage:
+  -h, --help               show help and exit
+      --host string        host (default "localhost")
+      --tags tag           the tag list
+  -t, --timeout duration   timeout (default 5s)
+      --verbose            verbose (default true)
+
+`, usage[Conf](t, co.Options{}))
+
+	assert.Equal(`Command usage:
+  -h, --help      show help and exit
+      --host      host (default "localhost")
+      --tags      the tag list
+  -t, --timeout   timeout (default 5s)
+      --verbose   verbose (default true)
+
+`, usage[Conf](t, co.Options{UsageHideTypes: true}))
+
+	assert.Equal(`Command usage:
+  -h, --help               show help and exit
+      --host string        host
+      --tags tag           the tag list
+  -t, --timeout duration   timeout
+      --verbose            verbose
+
+`, usage[Conf](t, co.Options{UsageHideDefaults: true}))
+
+	assert.Equal(`Command usage:
+  -h, --help      show help and exit
+      --host      host
+      --tags      the tag list
+  -t, --timeout   timeout
+      --verbose   verbose
+
+`, usage[Conf](t, co.Options{UsageHideDefaults: true, UsageHideTypes: true}))
+}
+
+func TestUsage_Output(t *testing.T) {
+	assert := assert.New(t)
+	type Conf struct {
+		Name string `help:"server name"`
+	}
+	configure := func(o *co.Options) { co.Configure[Conf](o) }
+	const usageText = "Command usage:\n  -h, --help          show help and exit\n      --name string   server name\n\n"
+
+	// Usage shown because of an error goes to ErrOutput
+	code, out, errOut := configureExit(t, configure, co.Options{Args: []string{"--nope"}})
+	assert.Equal(2, code)
+	assert.Equal("", out)
+	assert.Equal("unknown flag: --nope\n"+usageText, errOut)
+
+	code, _, _ = configureExit(t, configure, co.Options{Args: []string{"--nope"}, UsageErrorCode: 64})
+	assert.Equal(64, code)
+
+	// --help goes to UsageOutput, or else Output
+	code, out, errOut = configureExit(t, configure, co.Options{Args: []string{"--help"}})
+	assert.Equal(0, code)
+	assert.Equal(usageText, out)
+	assert.Equal("", errOut)
+
+	usageOut := &strings.Builder{}
+	code, out, _ = configureExit(t, configure, co.Options{Args: []string{"--help"}, UsageOutput: usageOut})
+	assert.Equal(0, code)
+	assert.Equal("", out)
+	assert.Equal(usageText, usageOut.String())
+}
@@ -42,21 +42,26 @@ func (c *configurer) validate(s any, fs *pflag.FlagSet) {
 			return false // false == don't stop looping over fields
 		}
 
-		// Check that required values are specified
-		_, required := tags.Lookup("required")
-		if !required && c.opts.RequireNoDefaults {
-			_, ok := tags.Lookup("default")
-			required = !ok
-		}
-
-		if required && !fs.Lookup(fName).Changed {
+		// Check that required values are specified. Values from sources other
+		// than flags don't mark the flag changed.
+		if _, ok := c.sources[fName]; c.isRequired(tags) && !ok && !fs.Lookup(fName).Changed {
 			errors = append(errors, fmt.Sprintf("%s is required", fName))
 		}
 
 		return false // false == don't stop looping over fields
 	}, []string{})
 
-	if len(errors) > 0 {
-		panic(strings.Join(errors, ", "))
+	c.checkErrors(fs, errors)
+}
+
+// isRequired returns true if the field with the given tags must be specified
+func (c *configurer) isRequired(tags *reflect.StructTag) bool {
+	if _, ok := tags.Lookup("required"); ok {
+		return true
+	}
+	if c.opts.RequireNoDefaults {
+		_, ok := tags.Lookup("default")
+		return !ok
 	}
+	return false
 }
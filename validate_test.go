@@ -15,6 +15,7 @@
 package configurature_test
 
 import (
+	"os"
 	"testing"
 
 	co "github.com/imoore76/configurature"
@@ -95,6 +96,35 @@ func TestValidation_Required(t *testing.T) {
 	assert.Equal(t, "my_string_req is required, my_string_enum must be one of a, b, c", err)
 }
 
+func TestValidation_RequiredFromEnv(t *testing.T) {
+	type T struct {
+		Name string `required:""`
+	}
+	t.Setenv("REQ_NAME", "foo")
+
+	c := co.Configure[T](&co.Options{
+		NoRecover: true,
+		Args:      []string{},
+		EnvPrefix: "REQ_",
+	})
+	assert.Equal(t, "foo", c.Name)
+}
+
+func TestValidation_RequiredFromFile(t *testing.T) {
+	type T struct {
+		Conf co.ConfigFile
+		Name string `required:""`
+	}
+	fileName := t.TempDir() + "/conf.json"
+	os.WriteFile(fileName, []byte(`{"name": "foo"}`), 0600)
+
+	c := co.Configure[T](&co.Options{
+		NoRecover: true,
+		Args:      []string{"--conf", fileName},
+	})
+	assert.Equal(t, "foo", c.Name)
+}
+
 func TestValidation_NestedFieldNames(t *testing.T) {
 	type T6 struct {
 		SubFooString string `help:"Something" required:""`
@@ -0,0 +1,90 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains the verification of config files by checksum or signature
+before they are applied
+*/
+package configurature
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ConfigVerifier verifies the integrity of a config file before it is applied
+type ConfigVerifier interface {
+	// Verify returns an error if data, read from fileName, can't be trusted
+	Verify(fileName string, data []byte) error
+}
+
+// ChecksumVerifier returns a ConfigVerifier that checks config files against
+// the SHA-256 checksum in <file>.sha256, as written by sha256sum
+func ChecksumVerifier() ConfigVerifier {
+	return checksumVerifier{}
+}
+
+type checksumVerifier struct{}
+
+// Verify implements ConfigVerifier
+func (checksumVerifier) Verify(fileName string, data []byte) error {
+	sumFile, err := os.ReadFile(fileName + ".sha256")
+	if err != nil {
+		return err
+	}
+	fields := bytes.Fields(sumFile)
+	if len(fields) == 0 {
+		return fmt.Errorf("%s.sha256 is empty", fileName)
+	}
+	want, err := hex.DecodeString(string(fields[0]))
+	if err != nil {
+		return fmt.Errorf("invalid checksum in %s.sha256: %v", fileName, err)
+	}
+	if got := sha256.Sum256(data); !bytes.Equal(got[:], want) {
+		return errors.New("checksum mismatch")
+	}
+	return nil
+}
+
+// Ed25519Verifier returns a ConfigVerifier that checks the detached Ed25519
+// signature of config files in <file>.sig, base64 encoded, against publicKey
+func Ed25519Verifier(publicKey ed25519.PublicKey) ConfigVerifier {
+	return ed25519Verifier{publicKey}
+}
+
+type ed25519Verifier struct {
+	publicKey ed25519.PublicKey
+}
+
+// Verify implements ConfigVerifier
+func (v ed25519Verifier) Verify(fileName string, data []byte) error {
+	sigFile, err := os.ReadFile(fileName + ".sig")
+	if err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(sigFile)))
+	if err != nil {
+		return fmt.Errorf("invalid signature in %s.sig: %v", fileName, err)
+	}
+	if !ed25519.Verify(v.publicKey, data, sig) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
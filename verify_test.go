@@ -0,0 +1,106 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !configurature_core
+
+package configurature_test
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+type VerifyConf struct {
+	Conf co.ConfigFile
+	Host string
+}
+
+func TestChecksumVerifier(t *testing.T) {
+	assert := assert.New(t)
+
+	confFile := filepath.Join(t.TempDir(), "conf.yaml")
+	data := []byte("host: example.com\n")
+	os.WriteFile(confFile, data, 0600)
+	sum := sha256.Sum256(data)
+	os.WriteFile(confFile+".sha256", []byte(hex.EncodeToString(sum[:])+"  conf.yaml\n"), 0600)
+
+	c := co.Configure[VerifyConf](&co.Options{
+		NoRecover:      true,
+		Args:           []string{"--conf", confFile},
+		ConfigVerifier: co.ChecksumVerifier(),
+	})
+	assert.Equal("example.com", c.Host)
+
+	os.WriteFile(confFile, []byte("host: evil.com\n"), 0600)
+	assert.PanicsWithValue("error verifying config file "+confFile+": checksum mismatch", func() {
+		co.Configure[VerifyConf](&co.Options{
+			NoRecover:      true,
+			Args:           []string{"--conf", confFile},
+			ConfigVerifier: co.ChecksumVerifier(),
+		})
+	})
+
+	os.Remove(confFile + ".sha256")
+	assert.PanicsWithValue("error verifying config file "+confFile+": open "+confFile+".sha256: no such file or directory", func() {
+		co.Configure[VerifyConf](&co.Options{
+			NoRecover:      true,
+			Args:           []string{"--conf", confFile},
+			ConfigVerifier: co.ChecksumVerifier(),
+		})
+	})
+
+	// Inline config files can't be verified
+	assert.PanicsWithValue("config in conf can't be verified because it was given inline", func() {
+		co.Configure[VerifyConf](&co.Options{
+			NoRecover:      true,
+			Args:           []string{"--conf", "base64:" + base64.StdEncoding.EncodeToString(data)},
+			ConfigVerifier: co.ChecksumVerifier(),
+		})
+	})
+}
+
+func TestEd25519Verifier(t *testing.T) {
+	assert := assert.New(t)
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	confFile := filepath.Join(t.TempDir(), "conf.yaml")
+	data := []byte("host: example.com\n")
+	os.WriteFile(confFile, data, 0600)
+	os.WriteFile(confFile+".sig", []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, data))+"\n"), 0600)
+
+	c := co.Configure[VerifyConf](&co.Options{
+		NoRecover:      true,
+		Args:           []string{"--conf", confFile},
+		ConfigVerifier: co.Ed25519Verifier(pub),
+	})
+	assert.Equal("example.com", c.Host)
+
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	assert.PanicsWithValue("error verifying config file "+confFile+": invalid signature", func() {
+		co.Configure[VerifyConf](&co.Options{
+			NoRecover:      true,
+			Args:           []string{"--conf", confFile},
+			ConfigVerifier: co.Ed25519Verifier(otherPub),
+		})
+	})
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This file contains helpers for reloading configuration when watched sources
+change
+*/
+package configurature
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// WatchingSource is a Source that can report changes to its values, such as
+// a key/value store supporting watches
+type WatchingSource interface {
+	Source
+
+	// Watch calls changed whenever the source's values change until ctx is
+	// done
+	Watch(ctx context.Context, changed func()) error
+}
+
+var (
+	// Cancels the contexts of the watchers and secret refreshers started by
+	// Configure calls, so that ResetForTest() can stop them
+	backgroundCancels []context.CancelFunc
+	backgroundMu      sync.Mutex
+)
+
+// backgroundContext returns a context for work started in the background by
+// the configurer, such as watching sources. It is done when the configurer's
+// context is done or when ResetForTest() is called.
+func (c *configurer) backgroundContext() context.Context {
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	backgroundMu.Lock()
+	backgroundCancels = append(backgroundCancels, cancel)
+	backgroundMu.Unlock()
+	return ctx
+}
+
+// stopBackground stops all work started in the background by Configure calls
+func stopBackground() {
+	backgroundMu.Lock()
+	defer backgroundMu.Unlock()
+	for _, cancel := range backgroundCancels {
+		cancel()
+	}
+	backgroundCancels = nil
+}
+
+// watchSources starts watching the Sources that implement WatchingSource
+// until the configurer's context is done or ResetForTest() is called. reload
+// is called, one call at a time, when any of them change.
+func (c *configurer) watchSources(reload func()) {
+	ctx := c.backgroundContext()
+
+	var mu sync.Mutex
+	for _, s := range c.opts.Sources {
+		ws, ok := s.(WatchingSource)
+		if !ok {
+			continue
+		}
+		go func() {
+			err := ws.Watch(ctx, func() {
+				mu.Lock()
+				defer mu.Unlock()
+				reload()
+			})
+			if err != nil && ctx.Err() == nil {
+				fmt.Fprintf(c.opts.errOutput(), "error watching source %s: %v\n", ws.Name(), err)
+			}
+		}()
+	}
+}
+
+// reloadConfig configures a new config struct with opts. Errors are returned
+// rather than exiting the program. The watchers and secret refresher started
+// by the first Configure call keep running, and nothing is prompted for in
+// the background.
+func reloadConfig[T any](ctx context.Context, opts Options) (config *T, err error) {
+	opts.NoRecover = true
+	opts.WatchSources = false
+	opts.SecretRefresh = 0
+	opts.Prompt = false
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return ConfigureContext[T](ctx, &opts), nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurature_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/imoore76/configurature"
+)
+
+// watchSource is a source whose values are changed by sending them on
+// updates
+type watchSource struct {
+	mu      sync.Mutex
+	values  map[string]any
+	updates chan map[string]any
+}
+
+func newWatchSource(values map[string]any) *watchSource {
+	return &watchSource{values: values, updates: make(chan map[string]any)}
+}
+
+func (s *watchSource) Name() string { return "watch" }
+
+func (s *watchSource) Load(ctx context.Context) (map[string]any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.values == nil {
+		return nil, errors.New("unavailable")
+	}
+	return s.values, nil
+}
+
+func (s *watchSource) Watch(ctx context.Context, changed func()) error {
+	for {
+		select {
+		case v := <-s.updates:
+			s.mu.Lock()
+			s.values = v
+			s.mu.Unlock()
+			changed()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+type WatchConfig struct {
+	Host string
+	Port int `default:"80"`
+}
+
+func TestWatchSources(t *testing.T) {
+	t.Cleanup(co.ResetForTest)
+	assert := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := newWatchSource(map[string]any{"host": "h1"})
+	changes := make(chan *WatchConfig, 1)
+	c := co.ConfigureContext[WatchConfig](ctx, &co.Options{
+		Args:         []string{"--port", "81"},
+		NoRecover:    true,
+		Sources:      []co.Source{s},
+		WatchSources: true,
+		OnChange: func(config any) {
+			changes <- config.(*WatchConfig)
+		},
+	})
+	assert.Equal("h1", c.Host)
+
+	s.updates <- map[string]any{"host": "h2"}
+	select {
+	case nc := <-changes:
+		assert.Equal("h2", nc.Host)
+		assert.Equal(81, nc.Port)
+		assert.Equal("h1", c.Host)
+		latest, err := co.Get[WatchConfig]()
+		assert.Nil(err)
+		assert.Equal(nc, latest)
+	case <-time.After(5 * time.Second):
+		t.Fatal("configuration was not reloaded")
+	}
+
+	// Failed reloads keep the current configuration
+	s.updates <- map[string]any{"unknown": "x"}
+	s.updates <- map[string]any{"host": "h3"}
+	select {
+	case nc := <-changes:
+		assert.Equal("h3", nc.Host)
+	case <-time.After(5 * time.Second):
+		t.Fatal("configuration was not reloaded")
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !configurature_core
+
+/*
+This file contains the YAML encoding and decoding used in the default build
+*/
+package configurature
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// unmarshalYAML decodes YAML data into v
+func unmarshalYAML(data []byte, v any) error {
+	return yaml.Unmarshal(data, v)
+}
+
+// encodeYAML encodes v as a YAML document
+func encodeYAML(v any) (string, error) {
+	out := strings.Builder{}
+	encoder := yaml.NewEncoder(&out)
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,37 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build configurature_core
+
+/*
+This file contains the YAML stubs used in the core build, which does not
+depend on a YAML library
+*/
+package configurature
+
+import "errors"
+
+// errNoYAML is returned by YAML functions in the core build
+var errNoYAML = errors.New("YAML support is not included in this build " +
+	"(built with the configurature_core tag)")
+
+// unmarshalYAML always fails in the core build
+func unmarshalYAML(data []byte, v any) error {
+	return errNoYAML
+}
+
+// encodeYAML always fails in the core build
+func encodeYAML(v any) (string, error) {
+	return "", errNoYAML
+}